@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"fmt"
+)
+
+// deprecatedField is a policy rule field that is scheduled for removal
+type deprecatedField struct {
+	// field is the path of the field in the rule
+	field string
+
+	// replacement is the field to use instead
+	replacement string
+
+	// isSet checks if the rule uses the field
+	isSet func(rule Rule) bool
+}
+
+// deprecatedFields lists the rule fields that are deprecated in this version
+var deprecatedFields = []deprecatedField{
+	{
+		field:       "mutate.overlay",
+		replacement: "mutate.patchStrategicMerge",
+		isSet:       func(rule Rule) bool { return rule.Mutation.Overlay != nil },
+	},
+	{
+		field:       "mutate.patches",
+		replacement: "mutate.patchesJson6902",
+		isSet:       func(rule Rule) bool { return len(rule.Mutation.Patches) > 0 },
+	},
+	{
+		field:       "preconditions operator Equal",
+		replacement: "operator Equals",
+		isSet:       func(rule Rule) bool { return hasConditionOperator(rule.Conditions, Equal) },
+	},
+	{
+		field:       "preconditions operator NotEqual",
+		replacement: "operator NotEquals",
+		isSet:       func(rule Rule) bool { return hasConditionOperator(rule.Conditions, NotEqual) },
+	},
+	{
+		field:       "validate.deny operator Equal",
+		replacement: "operator Equals",
+		isSet: func(rule Rule) bool {
+			return rule.Validation.Deny != nil && hasConditionOperator(rule.Validation.Deny.Conditions, Equal)
+		},
+	},
+	{
+		field:       "validate.deny operator NotEqual",
+		replacement: "operator NotEquals",
+		isSet: func(rule Rule) bool {
+			return rule.Validation.Deny != nil && hasConditionOperator(rule.Validation.Deny.Conditions, NotEqual)
+		},
+	},
+}
+
+// ValidateAPIVersion checks the policy apiVersion, if set, is the version of this package.
+// It returns a warning for each deprecated field used by the policy rules.
+func (p *ClusterPolicy) ValidateAPIVersion() ([]string, error) {
+	if p.APIVersion != "" && p.APIVersion != SchemeGroupVersion.String() {
+		return nil, fmt.Errorf("unsupported apiVersion %s, expected %s", p.APIVersion, SchemeGroupVersion.String())
+	}
+
+	var warnings []string
+	for i, rule := range p.Spec.Rules {
+		for _, deprecated := range deprecatedFields {
+			if deprecated.isSet(rule) {
+				warnings = append(warnings, fmt.Sprintf("spec.rules[%d]: %s is deprecated, use %s instead", i, deprecated.field, deprecated.replacement))
+			}
+		}
+	}
+
+	return warnings, nil
+}
+
+func hasConditionOperator(conditions []Condition, operator ConditionOperator) bool {
+	for _, condition := range conditions {
+		if condition.Operator == operator {
+			return true
+		}
+	}
+
+	return false
+}
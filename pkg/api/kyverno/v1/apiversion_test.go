@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_ValidateAPIVersion(t *testing.T) {
+	testcases := []struct {
+		description      string
+		policy           []byte
+		expectedWarnings []string
+		expectError      bool
+	}{
+		{
+			description: "current policy",
+			policy:      []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"add-label"},"spec":{"rules":[{"name":"add-label","match":{"resources":{"kinds":["Pod"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"app":"nginx"}}}}}]}}`),
+		},
+		{
+			description: "no apiVersion",
+			policy:      []byte(`{"kind":"ClusterPolicy","metadata":{"name":"add-label"},"spec":{"rules":[]}}`),
+		},
+		{
+			description: "unsupported apiVersion",
+			policy:      []byte(`{"apiVersion":"kyverno.io/v2","kind":"ClusterPolicy","metadata":{"name":"add-label"},"spec":{"rules":[]}}`),
+			expectError: true,
+		},
+		{
+			description:      "deprecated overlay",
+			policy:           []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"add-label"},"spec":{"rules":[{"name":"add-label","match":{"resources":{"kinds":["Pod"]}},"mutate":{"overlay":{"metadata":{"labels":{"app":"nginx"}}}}}]}}`),
+			expectedWarnings: []string{"spec.rules[0]: mutate.overlay is deprecated, use mutate.patchStrategicMerge instead"},
+		},
+		{
+			description:      "deprecated operators",
+			policy:           []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"deny-delete"},"spec":{"rules":[{"name":"deny-delete","match":{"resources":{"kinds":["Pod"]}},"preconditions":[{"key":"{{request.operation}}","operator":"NotEqual","value":"CREATE"}],"validate":{"deny":{"conditions":[{"key":"{{request.operation}}","operator":"Equal","value":"DELETE"}]}}}]}}`),
+			expectedWarnings: []string{"spec.rules[0]: preconditions operator NotEqual is deprecated, use operator NotEquals instead", "spec.rules[0]: validate.deny operator Equal is deprecated, use operator Equals instead"},
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy ClusterPolicy
+		err := json.Unmarshal(testcase.policy, &policy)
+		assert.NilError(t, err, testcase.description)
+
+		warnings, err := policy.ValidateAPIVersion()
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+		assert.DeepEqual(t, warnings, testcase.expectedWarnings)
+	}
+}
@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CompiledPolicy is a validated policy whose validate rules are prepared for repeated
+// evaluation, it is returned by ClusterPolicy.Compile
+// +k8s:deepcopy-gen=false
+type CompiledPolicy struct {
+	evaluator PolicyEvaluator
+}
+
+// PolicyEvaluator evaluates the validate rules of a compiled policy on resources
+// +k8s:deepcopy-gen=false
+type PolicyEvaluator interface {
+	// Evaluate returns the verdicts of the validate rules that match the resource
+	Evaluate(resource unstructured.Unstructured) []RuleVerdict
+}
+
+// RuleVerdict is the result of evaluating a compiled validate rule on a resource
+// +k8s:deepcopy-gen=false
+type RuleVerdict struct {
+	// Name is the rule name
+	Name string
+
+	// Success is true if the resource satisfies the rule pattern
+	Success bool
+
+	// Path is the path in the resource where the pattern failed
+	Path string
+}
+
+// PolicyCompiler validates and compiles a policy
+type PolicyCompiler func(policy *ClusterPolicy) (PolicyEvaluator, error)
+
+var policyCompiler PolicyCompiler
+
+// RegisterPolicyCompiler sets the compiler used by ClusterPolicy.Compile. The compiler
+// depends on the engine, which cannot be imported by this package, and is registered by
+// the policy package.
+func RegisterPolicyCompiler(compiler PolicyCompiler) {
+	policyCompiler = compiler
+}
+
+// Compile validates the policy and compiles its validate rules with the registered
+// compiler. The compiled policy evaluates resources as the engine does at admission.
+func (p *ClusterPolicy) Compile() (*CompiledPolicy, error) {
+	if policyCompiler == nil {
+		return nil, fmt.Errorf("no policy compiler is registered")
+	}
+
+	evaluator, err := policyCompiler(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompiledPolicy{evaluator: evaluator}, nil
+}
+
+// Evaluate returns the verdicts of the validate rules that match the resource
+func (cp *CompiledPolicy) Evaluate(resource unstructured.Unstructured) []RuleVerdict {
+	return cp.evaluator.Evaluate(resource)
+}
@@ -0,0 +1,219 @@
+package v1
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
+	policycommon "github.com/kyverno/kyverno/pkg/policy/common"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// fieldConstraint is the constraint a validation pattern places on a single field
+type fieldConstraint struct {
+	// forbidden is true if the field must not be present (negation anchor)
+	forbidden bool
+
+	// literal is true if the field must have exactly the value
+	literal bool
+
+	value interface{}
+}
+
+// ValidateContradictoryRules returns an error if two validate rules select the same
+// resources but their patterns contradict each other on the same field, in which
+// case no matching resource can ever pass both rules. It returns a warning for each
+// pair of rules whose patterns may contradict each other.
+func (p *ClusterPolicy) ValidateContradictoryRules() ([]string, error) {
+	var warnings []string
+	for i := 0; i < len(p.Spec.Rules); i++ {
+		ruleA := p.Spec.Rules[i]
+		if ruleA.Validation.Pattern == nil {
+			continue
+		}
+
+		for j := i + 1; j < len(p.Spec.Rules); j++ {
+			ruleB := p.Spec.Rules[j]
+			if ruleB.Validation.Pattern == nil || !haveSameMatch(ruleA, ruleB) {
+				continue
+			}
+
+			constraintsA := make(map[string]fieldConstraint)
+			flattenPattern(ruleA.Validation.Pattern, "", constraintsA)
+			constraintsB := make(map[string]fieldConstraint)
+			flattenPattern(ruleB.Validation.Pattern, "", constraintsB)
+
+			path, sure := findContradiction(constraintsA, constraintsB)
+			if path == "" {
+				continue
+			}
+
+			if sure {
+				return warnings, fmt.Errorf("rules %s and %s match the same resources but have contradictory patterns at %s", ruleA.Name, ruleB.Name, path)
+			}
+			warnings = append(warnings, fmt.Sprintf("rules %s and %s match the same resources and may have contradictory patterns at %s", ruleA.Name, ruleB.Name, path))
+		}
+	}
+
+	return warnings, nil
+}
+
+// haveSameMatch checks if the rules select the same resources
+func haveSameMatch(ruleA, ruleB Rule) bool {
+	return reflect.DeepEqual(ruleA.MatchResources, ruleB.MatchResources) &&
+		reflect.DeepEqual(ruleA.ExcludeResources, ruleB.ExcludeResources) &&
+		reflect.DeepEqual(ruleA.Conditions, ruleB.Conditions)
+}
+
+// flattenPattern collects the unconditional constraints of the pattern by field path.
+// Conditional, equality and existence anchors only apply in some cases and are skipped,
+// as are arrays.
+func flattenPattern(pattern interface{}, path string, constraints map[string]fieldConstraint) {
+	patternMap, ok := pattern.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for key, value := range patternMap {
+		if commonAnchors.IsNegationAnchor(key) {
+			field, _ := commonAnchors.RemoveAnchor(key)
+			constraints[path+"/"+field] = fieldConstraint{forbidden: true}
+			continue
+		}
+
+		if commonAnchors.IsConditionAnchor(key) || commonAnchors.IsEqualityAnchor(key) ||
+			commonAnchors.IsExistenceAnchor(key) || commonAnchors.IsAddingAnchor(key) {
+			continue
+		}
+
+		currentPath := path + "/" + key
+		switch typedValue := value.(type) {
+		case map[string]interface{}:
+			constraints[currentPath] = fieldConstraint{}
+			flattenPattern(typedValue, currentPath, constraints)
+		case []interface{}:
+			constraints[currentPath] = fieldConstraint{}
+		case string:
+			constraints[currentPath] = fieldConstraint{literal: isLiteralPatternValue(typedValue), value: typedValue}
+		case nil:
+			continue
+		default:
+			constraints[currentPath] = fieldConstraint{literal: true, value: typedValue}
+		}
+	}
+}
+
+// isLiteralPatternValue checks if a pattern string only matches itself, or the equivalent
+// numbers and quantities, i.e. it has no wildcards, operators or variables
+func isLiteralPatternValue(value string) bool {
+	if value == "" || regexVariables.MatchString(value) || policycommon.HasPatternOperator(value) {
+		return false
+	}
+
+	return !strings.ContainsAny(value, "*?<>!|&")
+}
+
+// literalComparison is the result of the comparison of two literal pattern values
+type literalComparison int
+
+const (
+	literalsEqual literalComparison = iota
+	literalsDiffer
+	// literalsUnsure is returned for values of different types, such as the string "true"
+	// and the boolean true, which the engine may match with the same resource value
+	literalsUnsure
+)
+
+// compareLiterals compares the literal pattern values. Numbers and quantities are compared
+// by value as done by the engine, e.g. "1Gi" equals "1024Mi" and "2" equals 2.
+func compareLiterals(a, b interface{}) literalComparison {
+	quantityA, okA := literalQuantity(a)
+	quantityB, okB := literalQuantity(b)
+	if okA && okB {
+		if quantityA.Cmp(quantityB) == 0 {
+			return literalsEqual
+		}
+		return literalsDiffer
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return literalsEqual
+	}
+
+	if reflect.TypeOf(a) == reflect.TypeOf(b) {
+		return literalsDiffer
+	}
+
+	return literalsUnsure
+}
+
+func literalQuantity(value interface{}) (resource.Quantity, bool) {
+	var str string
+	switch typed := value.(type) {
+	case string:
+		str = typed
+	case float64:
+		str = strconv.FormatFloat(typed, 'f', -1, 64)
+	case int64:
+		str = strconv.FormatInt(typed, 10)
+	case int:
+		str = strconv.Itoa(typed)
+	default:
+		return resource.Quantity{}, false
+	}
+
+	quantity, err := resource.ParseQuantity(str)
+	return quantity, err == nil
+}
+
+// findContradiction returns the first path on which the constraints cannot both be satisfied.
+// If no contradiction is sure, the first path on which the constraints may contradict each
+// other is returned and sure is false.
+func findContradiction(constraintsA, constraintsB map[string]fieldConstraint) (path string, sure bool) {
+	paths := make([]string, 0, len(constraintsA))
+	for path := range constraintsA {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var unsurePath string
+	for _, path := range paths {
+		a := constraintsA[path]
+		for otherPath, b := range constraintsB {
+			if otherPath != path && !strings.HasPrefix(otherPath, path+"/") && !strings.HasPrefix(path, otherPath+"/") {
+				continue
+			}
+
+			// one pattern forbids a field, or its parent, the other requires
+			if a.forbidden != b.forbidden {
+				forbiddenPath, requiredPath := path, otherPath
+				if b.forbidden {
+					forbiddenPath, requiredPath = otherPath, path
+				}
+
+				if requiredPath == forbiddenPath || strings.HasPrefix(requiredPath, forbiddenPath+"/") {
+					return requiredPath, true
+				}
+				continue
+			}
+
+			if otherPath != path || !a.literal || !b.literal {
+				continue
+			}
+
+			switch compareLiterals(a.value, b.value) {
+			case literalsDiffer:
+				return path, true
+			case literalsUnsure:
+				if unsurePath == "" {
+					unsurePath = path
+				}
+			}
+		}
+	}
+
+	return unsurePath, false
+}
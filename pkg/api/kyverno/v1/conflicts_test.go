@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_ValidateContradictoryRules(t *testing.T) {
+	testcases := []struct {
+		description   string
+		policy        []byte
+		expectWarning bool
+		expectError   bool
+	}{
+		{
+			description: "one rule requires a label another rule forbids",
+			policy:      []byte(`{"spec":{"rules":[{"name":"require-team","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"labels":{"team":"?*"}}}}},{"name":"forbid-team","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"labels":{"X(team)":"null"}}}}}]}}`),
+			expectError: true,
+		},
+		{
+			description: "rules require different literal values",
+			policy:      []byte(`{"spec":{"rules":[{"name":"always","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"restartPolicy":"Always"}}}},{"name":"never","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"restartPolicy":"Never"}}}}]}}`),
+			expectError: true,
+		},
+		{
+			description: "rules constrain different fields",
+			policy:      []byte(`{"spec":{"rules":[{"name":"require-team","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"labels":{"team":"?*"}}}}},{"name":"forbid-owner","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"labels":{"X(owner)":"null"}}}}}]}}`),
+			expectError: false,
+		},
+		{
+			description: "rules with compatible wildcard values",
+			policy:      []byte(`{"spec":{"rules":[{"name":"always","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"restartPolicy":"Always"}}}},{"name":"any","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"restartPolicy":"*"}}}}]}}`),
+			expectError: false,
+		},
+		{
+			description: "rules with regex values",
+			policy:      []byte(`{"spec":{"rules":[{"name":"always","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"restartPolicy":"Always"}}}},{"name":"regex","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"restartPolicy":"regex:^(Always)$"}}}}]}}`),
+			expectError: false,
+		},
+		{
+			description: "rules with equivalent quantities",
+			policy:      []byte(`{"spec":{"rules":[{"name":"gibibyte","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"overhead":{"memory":"1Gi"}}}}},{"name":"mebibytes","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"overhead":{"memory":"1024Mi"}}}}}]}}`),
+			expectError: false,
+		},
+		{
+			description: "rules with different quantities",
+			policy:      []byte(`{"spec":{"rules":[{"name":"gibibyte","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"overhead":{"memory":"1Gi"}}}}},{"name":"mebibytes","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"overhead":{"memory":"512Mi"}}}}}]}}`),
+			expectError: true,
+		},
+		{
+			description: "rules with a number and its string form",
+			policy:      []byte(`{"spec":{"rules":[{"name":"number","match":{"resources":{"kinds":["Deployment"]}},"validate":{"pattern":{"spec":{"replicas":2}}}},{"name":"string","match":{"resources":{"kinds":["Deployment"]}},"validate":{"pattern":{"spec":{"replicas":"2"}}}}]}}`),
+			expectError: false,
+		},
+		{
+			description:   "rules with a boolean and a string",
+			policy:        []byte(`{"spec":{"rules":[{"name":"boolean","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"hostNetwork":false}}}},{"name":"string","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"hostNetwork":"false"}}}}]}}`),
+			expectWarning: true,
+			expectError:   false,
+		},
+		{
+			description: "contradictory patterns on different match blocks",
+			policy:      []byte(`{"spec":{"rules":[{"name":"always","match":{"resources":{"kinds":["Pod"],"namespaces":["prod"]}},"validate":{"pattern":{"spec":{"restartPolicy":"Always"}}}},{"name":"never","match":{"resources":{"kinds":["Pod"],"namespaces":["dev"]}},"validate":{"pattern":{"spec":{"restartPolicy":"Never"}}}}]}}`),
+			expectError: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy ClusterPolicy
+		err := json.Unmarshal(testcase.policy, &policy)
+		assert.NilError(t, err)
+
+		warnings, err := policy.ValidateContradictoryRules()
+		assert.Equal(t, len(warnings) > 0, testcase.expectWarning, testcase.description)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_compareLiterals(t *testing.T) {
+	testcases := []struct {
+		a, b     interface{}
+		expected literalComparison
+	}{
+		{a: "Always", b: "Always", expected: literalsEqual},
+		{a: "Always", b: "Never", expected: literalsDiffer},
+		{a: "1Gi", b: "1024Mi", expected: literalsEqual},
+		{a: "500m", b: 0.5, expected: literalsEqual},
+		{a: "2", b: int64(3), expected: literalsDiffer},
+		{a: true, b: false, expected: literalsDiffer},
+		{a: "true", b: true, expected: literalsUnsure},
+	}
+
+	for _, testcase := range testcases {
+		assert.Equal(t, compareLiterals(testcase.a, testcase.b), testcase.expected, fmt.Sprintf("%v and %v", testcase.a, testcase.b))
+	}
+}
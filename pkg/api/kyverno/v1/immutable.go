@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// immutableFields holds, per kind, the fields that cannot be updated once the resource is created
+var immutableFields = map[string][]string{
+	"ClusterRoleBinding":    {"roleRef"},
+	"DaemonSet":             {"spec.selector"},
+	"Deployment":            {"spec.selector"},
+	"Job":                   {"spec.selector", "spec.template"},
+	"PersistentVolumeClaim": {"spec.accessModes", "spec.selector", "spec.storageClassName", "spec.volumeMode"},
+	"ReplicaSet":            {"spec.selector"},
+	"RoleBinding":           {"roleRef"},
+	"Secret":                {"type"},
+	"Service":               {"spec.clusterIP", "spec.clusterIPs"},
+	"StatefulSet":           {"spec.selector", "spec.serviceName", "spec.volumeClaimTemplates", "spec.podManagementPolicy"},
+}
+
+// ValidateGenerateDataImmutableFields returns an error if a synchronized generate block of the
+// rule sets fields in data that are immutable for the generated kind, as changes to these
+// fields are not propagated to the generated resource. The content of clone sources is not
+// known and is not checked.
+func (r *Rule) ValidateGenerateDataImmutableFields() error {
+	if !reflect.DeepEqual(r.Generation, Generation{}) {
+		if err := validateDataImmutableFields("generate", r.Generation); err != nil {
+			return err
+		}
+	}
+
+	for i, generation := range r.Generations {
+		if err := validateDataImmutableFields(fmt.Sprintf("generations[%d]", i), generation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateDataImmutableFields(path string, generation Generation) error {
+	if !generation.Synchronize {
+		return nil
+	}
+
+	data, ok := generation.Data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var set []string
+	for _, field := range immutableFields[generation.Kind] {
+		if hasField(data, strings.Split(field, ".")) {
+			set = append(set, field)
+		}
+	}
+
+	if len(set) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s.data: changes to %s are not synchronized, the fields are immutable for %s", path, strings.Join(set, ", "), generation.Kind)
+}
+
+func hasField(data map[string]interface{}, path []string) bool {
+	value, ok := data[path[0]]
+	if !ok {
+		return false
+	}
+
+	if len(path) == 1 {
+		return true
+	}
+
+	child, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	return hasField(child, path[1:])
+}
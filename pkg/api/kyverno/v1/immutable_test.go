@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_ValidateGenerateDataImmutableFields(t *testing.T) {
+	testcases := []struct {
+		description   string
+		rule          []byte
+		expectedError string
+	}{
+		{
+			description:   "synchronized Service with clusterIP",
+			rule:          []byte(`{"name":"generate-svc","generate":{"kind":"Service","name":"svc","namespace":"default","synchronize":true,"data":{"spec":{"clusterIP":"10.0.0.10","ports":[{"port":80}]}}}}`),
+			expectedError: "generate.data: changes to spec.clusterIP are not synchronized, the fields are immutable for Service",
+		},
+		{
+			description: "synchronized Service without clusterIP",
+			rule:        []byte(`{"name":"generate-svc","generate":{"kind":"Service","name":"svc","namespace":"default","synchronize":true,"data":{"spec":{"ports":[{"port":80}]}}}}`),
+		},
+		{
+			description: "Service with clusterIP not synchronized",
+			rule:        []byte(`{"name":"generate-svc","generate":{"kind":"Service","name":"svc","namespace":"default","data":{"spec":{"clusterIP":"10.0.0.10"}}}}`),
+		},
+		{
+			description: "synchronized ConfigMap",
+			rule:        []byte(`{"name":"generate-cm","generate":{"kind":"ConfigMap","name":"cm","namespace":"default","synchronize":true,"data":{"data":{"key":"value"}}}}`),
+		},
+		{
+			description: "synchronized Service clone",
+			rule:        []byte(`{"name":"generate-svc","generate":{"kind":"Service","name":"svc","namespace":"default","synchronize":true,"clone":{"namespace":"default","name":"source"}}}`),
+		},
+		{
+			description:   "synchronized StatefulSet in generations",
+			rule:          []byte(`{"name":"generate-sts","generations":[{"kind":"ConfigMap","name":"cm","namespace":"default","synchronize":true,"data":{"data":{"key":"value"}}},{"kind":"StatefulSet","name":"sts","namespace":"default","synchronize":true,"data":{"spec":{"selector":{"matchLabels":{"app":"sts"}},"serviceName":"sts"}}}]}`),
+			expectedError: "generations[1].data: changes to spec.selector, spec.serviceName are not synchronized, the fields are immutable for StatefulSet",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		err = rule.ValidateGenerateDataImmutableFields()
+		if testcase.expectedError == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.expectedError, testcase.description)
+		}
+	}
+}
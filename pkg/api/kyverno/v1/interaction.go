@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/minio/minio/pkg/wildcard"
+)
+
+// ValidateMutateGenerateInteraction returns an error if a resource generated by a rule
+// in the policy is also selected by a mutate rule of the same policy. The match of the
+// mutate rule must select the resource together with the resource description of
+// spec.match, and the resource is skipped if an exclude block certainly excludes it. The
+// generated resource may then be mutated unexpectedly, so the result should be reported
+// as a warning.
+func (p *ClusterPolicy) ValidateMutateGenerateInteraction() error {
+	for _, genRule := range p.Spec.Rules {
+		for _, generation := range genRule.GenerateTargets() {
+			for _, mutateRule := range p.Spec.Rules {
+				if !mutateRule.HasMutate() {
+					continue
+				}
+
+				if !matchSelectsGenerateTarget(mutateRule.MatchResources, generation) ||
+					(p.Spec.Match != nil && !matchesGenerateTarget(p.Spec.Match.ResourceDescription, generation)) {
+					continue
+				}
+
+				if excludesGenerateTarget(mutateRule.ExcludeResources, generation) ||
+					(p.Spec.Exclude != nil && excludesGenerateTarget(*p.Spec.Exclude, generation)) {
+					continue
+				}
+
+				return fmt.Errorf("resource %s/%s generated by rule %s is matched by mutate rule %s and may be mutated unexpectedly",
+					generation.Kind, generation.Name, genRule.Name, mutateRule.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchSelectsGenerateTarget checks if the match block may select the resource declared by
+// the generate rule, the resource description and all entries of all must select it, and
+// at least one entry of any
+func matchSelectsGenerateTarget(match MatchResources, gen Generation) bool {
+	if reflect.DeepEqual(match.ResourceDescription, ResourceDescription{}) && len(match.Any) == 0 && len(match.All) == 0 {
+		return false
+	}
+
+	if !reflect.DeepEqual(match.ResourceDescription, ResourceDescription{}) && !matchesGenerateTarget(match.ResourceDescription, gen) {
+		return false
+	}
+
+	if len(match.Any) > 0 {
+		selected := false
+		for _, rd := range match.Any {
+			if matchesGenerateTarget(rd, gen) {
+				selected = true
+				break
+			}
+		}
+
+		if !selected {
+			return false
+		}
+	}
+
+	for _, rd := range match.All {
+		if !matchesGenerateTarget(rd, gen) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// excludesGenerateTarget checks if the exclude block certainly excludes the resource declared
+// by the generate rule. Blocks restricted to users, or using selectors, annotations or
+// generations, depend on the request and the generated resource and are assumed to not
+// exclude it, as are variables in the target.
+func excludesGenerateTarget(exclude ExcludeResources, gen Generation) bool {
+	if !reflect.DeepEqual(exclude.UserInfo, UserInfo{}) {
+		return false
+	}
+
+	if !reflect.DeepEqual(exclude.ResourceDescription, ResourceDescription{}) && excludesGenerateTargetResource(exclude.ResourceDescription, gen) {
+		return true
+	}
+
+	for _, rd := range exclude.Any {
+		if excludesGenerateTargetResource(rd, gen) {
+			return true
+		}
+	}
+
+	if len(exclude.All) == 0 {
+		return false
+	}
+
+	for _, rd := range exclude.All {
+		if !excludesGenerateTargetResource(rd, gen) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func excludesGenerateTargetResource(rd ResourceDescription, gen Generation) bool {
+	if len(rd.Annotations) > 0 || rd.Selector != nil || rd.NamespaceSelector != nil || rd.MatchGeneration != "" {
+		return false
+	}
+
+	if len(rd.Kinds) > 0 && !containsString(rd.Kinds, gen.Kind) {
+		return false
+	}
+
+	if len(rd.Namespaces) > 0 && (regexVariables.MatchString(gen.Namespace) || !containsPattern(rd.Namespaces, gen.Namespace)) {
+		return false
+	}
+
+	if rd.Name != "" && (regexVariables.MatchString(gen.Name) || !wildcard.Match(rd.Name, gen.Name)) {
+		return false
+	}
+
+	return true
+}
+
+// matchesGenerateTarget checks if the resource description selects the resource
+// declared by the generate rule, a description without kinds selects all kinds.
+// Variables in the target cannot be resolved, and are assumed to match.
+func matchesGenerateTarget(rd ResourceDescription, gen Generation) bool {
+	if len(rd.Kinds) > 0 && !containsString(rd.Kinds, gen.Kind) {
+		return false
+	}
+
+	if len(rd.Namespaces) > 0 && gen.Namespace != "" && !regexVariables.MatchString(gen.Namespace) {
+		if !containsPattern(rd.Namespaces, gen.Namespace) {
+			return false
+		}
+	}
+
+	if rd.Name != "" && gen.Name != "" && !regexVariables.MatchString(gen.Name) {
+		if !wildcard.Match(rd.Name, gen.Name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsString(list []string, element string) bool {
+	for _, item := range list {
+		if item == element {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsPattern(patterns []string, element string) bool {
+	for _, pattern := range patterns {
+		if wildcard.Match(pattern, element) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_ValidateMutateGenerateInteraction(t *testing.T) {
+	testcases := []struct {
+		description string
+		policy      []byte
+		expectError bool
+	}{
+		{
+			description: "generated ConfigMap is matched by a mutate rule",
+			policy:      []byte(`{"spec":{"rules":[{"name":"generate-cm","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"default-cm","namespace":"{{request.object.metadata.name}}","data":{"data":{"key":"value"}}}},{"name":"mutate-cm","match":{"resources":{"kinds":["ConfigMap"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"mutated":"true"}}}}}]}}`),
+			expectError: true,
+		},
+		{
+			description: "generated ConfigMap is outside the mutate rule namespaces",
+			policy:      []byte(`{"spec":{"rules":[{"name":"generate-cm","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"default-cm","namespace":"default","data":{"data":{"key":"value"}}}},{"name":"mutate-cm","match":{"resources":{"kinds":["ConfigMap"],"namespaces":["prod-*"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"mutated":"true"}}}}}]}}`),
+			expectError: false,
+		},
+		{
+			description: "mutate rule matches a different kind",
+			policy:      []byte(`{"spec":{"rules":[{"name":"generate-cm","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"default-cm","namespace":"default","data":{"data":{"key":"value"}}}},{"name":"mutate-pod","match":{"resources":{"kinds":["Pod"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"mutated":"true"}}}}}]}}`),
+			expectError: false,
+		},
+		{
+			description: "generated ConfigMap is excluded by name",
+			policy:      []byte(`{"spec":{"rules":[{"name":"generate-cm","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"default-cm","namespace":"default","data":{"data":{"key":"value"}}}},{"name":"mutate-cm","match":{"resources":{"kinds":["ConfigMap"]}},"exclude":{"resources":{"name":"default-*"}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"mutated":"true"}}}}}]}}`),
+			expectError: false,
+		},
+		{
+			description: "generated ConfigMap is excluded by spec.exclude",
+			policy:      []byte(`{"spec":{"exclude":{"resources":{"namespaces":["default"]}},"rules":[{"name":"generate-cm","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"default-cm","namespace":"default","data":{"data":{"key":"value"}}}},{"name":"mutate-cm","match":{"resources":{"kinds":["ConfigMap"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"mutated":"true"}}}}}]}}`),
+			expectError: false,
+		},
+		{
+			description: "exclude depends on a selector",
+			policy:      []byte(`{"spec":{"rules":[{"name":"generate-cm","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"default-cm","namespace":"default","data":{"data":{"key":"value"}}}},{"name":"mutate-cm","match":{"resources":{"kinds":["ConfigMap"]}},"exclude":{"resources":{"name":"default-cm","selector":{"matchLabels":{"app":"web"}}}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"mutated":"true"}}}}}]}}`),
+			expectError: true,
+		},
+		{
+			description: "generated ConfigMap is matched by any",
+			policy:      []byte(`{"spec":{"rules":[{"name":"generate-cm","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"default-cm","namespace":"default","data":{"data":{"key":"value"}}}},{"name":"mutate-cm","match":{"any":[{"kinds":["Secret"]},{"kinds":["ConfigMap"],"namespaces":["default"]}]},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"mutated":"true"}}}}}]}}`),
+			expectError: true,
+		},
+		{
+			description: "any does not match the generated kind",
+			policy:      []byte(`{"spec":{"rules":[{"name":"generate-cm","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"default-cm","namespace":"default","data":{"data":{"key":"value"}}}},{"name":"mutate-pod","match":{"any":[{"kinds":["Pod"]},{"kinds":["Deployment"]}]},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"mutated":"true"}}}}}]}}`),
+			expectError: false,
+		},
+		{
+			description: "all entries must match",
+			policy:      []byte(`{"spec":{"rules":[{"name":"generate-cm","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"default-cm","namespace":"default","data":{"data":{"key":"value"}}}},{"name":"mutate-cm","match":{"all":[{"kinds":["ConfigMap"]},{"namespaces":["prod-*"]}]},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"mutated":"true"}}}}}]}}`),
+			expectError: false,
+		},
+		{
+			description: "spec.match restricts the namespaces",
+			policy:      []byte(`{"spec":{"match":{"resources":{"namespaces":["prod-*"]}},"rules":[{"name":"generate-cm","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"default-cm","namespace":"default","data":{"data":{"key":"value"}}}},{"name":"mutate-cm","match":{"resources":{"kinds":["ConfigMap"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"mutated":"true"}}}}}]}}`),
+			expectError: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy ClusterPolicy
+		err := json.Unmarshal(testcase.policy, &policy)
+		assert.NilError(t, err)
+
+		err = policy.ValidateMutateGenerateInteraction()
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
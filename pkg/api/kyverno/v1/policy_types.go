@@ -48,11 +48,70 @@ type Spec struct {
 	// +optional
 	ValidationFailureAction string `json:"validationFailureAction,omitempty" yaml:"validationFailureAction,omitempty"`
 
+	// FailureActionOverrides override the validation failure action for namespaces.
+	// The first override listing a namespace applies.
+	// +optional
+	FailureActionOverrides []FailureActionOverride `json:"failureActionOverrides,omitempty" yaml:"failureActionOverrides,omitempty"`
+
 	// Background controls if rules are applied to existing resources during a background scan.
 	// Optional. Default value is "true". The value must be set to "false" if the policy rule
 	// uses variables that are only available in the admission review request (e.g. user name).
 	// +optional
 	Background *bool `json:"background,omitempty" yaml:"background,omitempty"`
+
+	// WebhookTimeoutSeconds specifies the maximum time in seconds allowed to apply this policy.
+	// The value must be between 1 and 30 seconds. Optional. The webhook default applies
+	// if not specified.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=30
+	// +optional
+	WebhookTimeoutSeconds *int32 `json:"webhookTimeoutSeconds,omitempty" yaml:"webhookTimeoutSeconds,omitempty"`
+
+	// WebhookScope restricts the admission webhook of the policy to resources of the scope:
+	// "Cluster", "Namespaced" or "*". Optional. Defaults to "*".
+	// +kubebuilder:validation:Enum=Cluster;Namespaced;*
+	// +optional
+	WebhookScope string `json:"webhookScope,omitempty" yaml:"webhookScope,omitempty"`
+
+	// AllowSystemNamespaces confirms that namespace wildcards in rules are meant to
+	// match system namespaces such as kube-system. Optional. Defaults to "false".
+	// +optional
+	AllowSystemNamespaces bool `json:"allowSystemNamespaces,omitempty" yaml:"allowSystemNamespaces,omitempty"`
+
+	// Match defines resource filters that apply to every rule of the policy, in addition
+	// to the match declaration of each rule. Optional.
+	// +optional
+	Match *MatchResources `json:"match,omitempty" yaml:"match,omitempty"`
+
+	// Exclude defines resource filters that are excluded from every rule of the policy,
+	// in addition to the exclude declaration of each rule. Optional.
+	// +optional
+	Exclude *ExcludeResources `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+
+	// MatchConditions are CEL expressions declared on the admission webhooks of the
+	// policy. A request is sent to the webhook only if all conditions evaluate to true.
+	// Optional.
+	// +optional
+	MatchConditions []MatchCondition `json:"matchConditions,omitempty" yaml:"matchConditions,omitempty"`
+}
+
+// FailureActionOverride sets the validation failure action for namespaces.
+type FailureActionOverride struct {
+	// Action is the validation failure action, "enforce" or "audit".
+	Action string `json:"action,omitempty" yaml:"action,omitempty"`
+
+	// Namespaces are the names of the namespaces, wildcards are supported.
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+}
+
+// MatchCondition is a named CEL expression filtering the admission requests sent to the
+// webhooks of the policy.
+type MatchCondition struct {
+	// Name identifies the condition, it must be unique within the policy.
+	Name string `json:"name" yaml:"name"`
+
+	// Expression is the CEL expression evaluated against the admission request.
+	Expression string `json:"expression" yaml:"expression"`
 }
 
 // Rule defines a validation, mutation, or generation control for matching resources.
@@ -97,6 +156,23 @@ type Rule struct {
 	// Generation is used to create new resources.
 	// +optional
 	Generation Generation `json:"generate,omitempty" yaml:"generate,omitempty"`
+
+	// Generations is used to create several related resources from a single rule, each
+	// entry is validated as a generate block. Targets must be unique. Generations cannot be
+	// combined with Generation.
+	// +optional
+	Generations []Generation `json:"generations,omitempty" yaml:"generations,omitempty"`
+
+	// SkipBackgroundRequests excludes the rule from background scans, even if background
+	// processing is enabled for the policy. Optional. Defaults to "false".
+	// +optional
+	SkipBackgroundRequests bool `json:"skipBackgroundRequests,omitempty" yaml:"skipBackgroundRequests,omitempty"`
+
+	// Priority orders the rule execution: rules with a priority are applied first, lowest
+	// value first, followed by the other rules in declaration order. Priorities must be
+	// unique within a policy. Optional. Defaults to "0", i.e. no priority.
+	// +optional
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
 }
 
 // ContextEntry adds variables and data sources to a rule Context. Either a
@@ -151,7 +227,8 @@ type Condition struct {
 	Key apiextensions.JSON `json:"key,omitempty" yaml:"key,omitempty"`
 
 	// Operator is the operation to perform. Valid operators
-	// are Equals, NotEquals, In and NotIn.
+	// are Equals, NotEquals, In, NotIn, AnyIn, AllIn, GreaterThan,
+	// GreaterThanOrEquals, LessThan and LessThanOrEquals.
 	Operator ConditionOperator `json:"operator,omitempty" yaml:"operator,omitempty"`
 
 	// Value is the conditional value, or set of values. The values can be fixed set
@@ -162,7 +239,7 @@ type Condition struct {
 }
 
 // ConditionOperator is the operation performed on condition key and value.
-// +kubebuilder:validation:Enum=Equals;NotEquals;In;NotIn
+// +kubebuilder:validation:Enum=Equals;NotEquals;In;NotIn;AnyIn;AllIn;GreaterThan;GreaterThanOrEquals;LessThan;LessThanOrEquals
 type ConditionOperator string
 
 const (
@@ -180,6 +257,10 @@ const (
 	In ConditionOperator = "In"
 	// NotIn evaluates if the key is not contained in the set of values.
 	NotIn ConditionOperator = "NotIn"
+	// AnyIn evaluates if any of the keys is contained in the set of values.
+	AnyIn ConditionOperator = "AnyIn"
+	// AllIn evaluates if all of the keys are contained in the set of values.
+	AllIn ConditionOperator = "AllIn"
 	// GreaterThanOrEquals evaluates if the key (numeric) is greater than or equal to the value (numeric).
 	GreaterThanOrEquals ConditionOperator = "GreaterThanOrEquals"
 	// GreaterThan evaluates if the key (numeric) is greater than the value (numeric).
@@ -199,6 +280,22 @@ type MatchResources struct {
 
 	// ResourceDescription contains information about the resource being created or modified.
 	ResourceDescription `json:"resources,omitempty" yaml:"resources,omitempty"`
+
+	// Any is a list of resource descriptions. The rule is applicable if any of the
+	// descriptions matches the resource. Cannot be combined with a single resource description.
+	// +optional
+	Any []ResourceDescription `json:"any,omitempty" yaml:"any,omitempty"`
+
+	// All is a list of resource descriptions. The rule is applicable if all of the
+	// descriptions match the resource. Cannot be combined with a single resource description.
+	// +optional
+	All []ResourceDescription `json:"all,omitempty" yaml:"all,omitempty"`
+
+	// Operations is the list of admission operations the rule applies to, one of
+	// CREATE, UPDATE, DELETE or CONNECT. Optional. All operations handled for the rule
+	// type apply if not specified.
+	// +optional
+	Operations []string `json:"operations,omitempty" yaml:"operations,omitempty"`
 }
 
 // ExcludeResources specifies resource and admission review request data for
@@ -211,6 +308,16 @@ type ExcludeResources struct {
 	// ResourceDescription contains information about the resource being created or modified.
 	// +optional
 	ResourceDescription `json:"resources,omitempty" yaml:"resources,omitempty"`
+
+	// Any is a list of resource descriptions. The rule is not applicable if any of the
+	// descriptions matches the resource. Cannot be combined with a single resource description.
+	// +optional
+	Any []ResourceDescription `json:"any,omitempty" yaml:"any,omitempty"`
+
+	// All is a list of resource descriptions. The rule is not applicable if all of the
+	// descriptions match the resource. Cannot be combined with a single resource description.
+	// +optional
+	All []ResourceDescription `json:"all,omitempty" yaml:"all,omitempty"`
 }
 
 // UserInfo contains information about the user performing the operation.
@@ -264,6 +371,12 @@ type ResourceDescription struct {
 	// does not match an empty label set.
 	// +optional
 	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty" yaml:"namespaceSelector,omitempty"`
+
+	// MatchGeneration is a comparison of the resource generation with an integer, e.g.
+	// `metadata.generation > 1`. Supported fields are metadata.generation and
+	// status.observedGeneration, supported operators are ==, !=, >, >=, < and <=.
+	// +optional
+	MatchGeneration string `json:"matchGeneration,omitempty" yaml:"matchGeneration,omitempty"`
 }
 
 // Mutation defines how resource are modified.
@@ -313,6 +426,11 @@ type Patch struct {
 	// +kubebuilder:validation:XPreserveUnknownFields
 	// +optional
 	Value apiextensions.JSON `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// AllowMissing tolerates an absent path for remove operations, so the patch
+	// can be applied to resources which do not have the field.
+	// +optional
+	AllowMissing bool `json:"allowMissing,omitempty" yaml:"allowMissing,omitempty"`
 }
 
 // Validation defines checks to be performed on matching resources.
@@ -328,7 +446,8 @@ type Validation struct {
 	Pattern apiextensions.JSON `json:"pattern,omitempty" yaml:"pattern,omitempty"`
 
 	// AnyPattern specifies list of validation patterns. At least one of the patterns
-	// must be satisfied for the validation rule to succeed.
+	// must be satisfied for the validation rule to succeed. An entry is either a pattern,
+	// or an AnyPatternEntry with its own message.
 	// +kubebuilder:validation:XPreserveUnknownFields
 	// +optional
 	AnyPattern apiextensions.JSON `json:"anyPattern,omitempty" yaml:"anyPattern,omitempty"`
@@ -336,6 +455,107 @@ type Validation struct {
 	// Deny defines conditions to fail the validation rule.
 	// +optional
 	Deny *Deny `json:"deny,omitempty" yaml:"deny,omitempty"`
+
+	// PodSecurity applies the checks of a Pod Security Standards level instead of a pattern.
+	// The level is checked but not evaluated by the engine, so the rule must also declare
+	// deny conditions.
+	// +optional
+	PodSecurity *PodSecurity `json:"podSecurity,omitempty" yaml:"podSecurity,omitempty"`
+
+	// ForEach applies a pattern to each element of a list in the resource.
+	// Cannot be combined with foreach declarations in anyPattern entries.
+	// +optional
+	ForEach []ForEachValidation `json:"foreach,omitempty" yaml:"foreach,omitempty"`
+
+	// Manifests verifies the resource is a manifest signed by the attestors.
+	// Cannot be combined with pattern or anyPattern. The attestors are checked but the
+	// signatures are not verified by the engine, so the rule must also declare deny
+	// conditions.
+	// +optional
+	Manifests *Manifests `json:"manifests,omitempty" yaml:"manifests,omitempty"`
+
+	// CEL validates the resource with a CEL expression instead of a pattern.
+	// Cannot be combined with pattern or anyPattern. The expression is checked but not
+	// evaluated by the engine, so the rule must also declare deny conditions.
+	// +optional
+	CEL *CEL `json:"cel,omitempty" yaml:"cel,omitempty"`
+}
+
+// CEL is a validation written as a CEL expression.
+type CEL struct {
+	// Expression is the CEL expression which must evaluate to true for the resource to be valid.
+	// The resource is available as object and the admission request as request.
+	Expression string `json:"expression,omitempty" yaml:"expression,omitempty"`
+
+	// Message is displayed when the expression evaluates to false.
+	// +optional
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// Manifests verifies the signature of resource manifests.
+type Manifests struct {
+	// Attestors are the identities allowed to sign the manifests. At least one is required.
+	Attestors []Attestor `json:"attestors,omitempty" yaml:"attestors,omitempty"`
+
+	// IgnoreFields are JSON pointers to fields of the resource ignored when comparing it to
+	// the signed manifest, such as fields set by controllers.
+	// +optional
+	IgnoreFields []string `json:"ignoreFields,omitempty" yaml:"ignoreFields,omitempty"`
+}
+
+// Attestor identifies a signer, either by public key or by keyless certificate identity.
+type Attestor struct {
+	// Keys is a PEM encoded public key.
+	// +optional
+	Keys string `json:"keys,omitempty" yaml:"keys,omitempty"`
+
+	// Subject is the identity of the keyless signing certificate, e.g. an email address.
+	// +optional
+	Subject string `json:"subject,omitempty" yaml:"subject,omitempty"`
+
+	// Issuer is the OIDC issuer of the keyless signing certificate.
+	// +optional
+	Issuer string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+}
+
+// ForEachValidation applies a pattern to each element of a list.
+type ForEachValidation struct {
+	// List is a JMESPath expression selecting the list of elements to validate,
+	// e.g. "request.object.spec.containers".
+	List string `json:"list" yaml:"list"`
+
+	// Pattern specifies an overlay-style pattern used to check each element.
+	// +kubebuilder:validation:XPreserveUnknownFields
+	Pattern apiextensions.JSON `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+}
+
+// PodSecurity references a Pod Security Standards level.
+type PodSecurity struct {
+	// Level is the Pod Security Standards level, either baseline or restricted.
+	// +kubebuilder:validation:Enum=baseline;restricted
+	Level string `json:"level" yaml:"level"`
+
+	// Version is the Kubernetes version of the Pod Security Standards, e.g. v1.22 or latest.
+	// Defaults to latest.
+	// +optional
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// AnyPatternEntry is an anyPattern entry with a message specific to the pattern.
+type AnyPatternEntry struct {
+	// Pattern specifies an overlay-style pattern used to check resources.
+	// +kubebuilder:validation:XPreserveUnknownFields
+	// +optional
+	Pattern apiextensions.JSON `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+
+	// Message is displayed on failure when this pattern is the closest to matching.
+	// +optional
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+
+	// ForEach applies patterns to the elements of lists, instead of the pattern.
+	// Cannot be combined with foreach declarations at the validation level.
+	// +optional
+	ForEach []ForEachValidation `json:"foreach,omitempty" yaml:"foreach,omitempty"`
 }
 
 // Deny specifies a list of conditions. The validation rule fails, if any Condition
@@ -358,6 +578,20 @@ type Generation struct {
 	// +optional
 	Synchronize bool `json:"synchronize,omitempty" yaml:"synchronize,omitempty"`
 
+	// GenerateExisting controls if resources are also generated for triggers that exist
+	// when the policy is created, instead of only for new triggers.
+	// Optional. Defaults to "false" if not specified.
+	// +optional
+	GenerateExisting bool `json:"generateExisting,omitempty" yaml:"generateExisting,omitempty"`
+
+	// OrphanDependents controls if generated resources are kept when the trigger resource
+	// is deleted. If set to "false" generated resources are owned by the trigger, through
+	// owner references, and deleted with it. Owner references cannot cross namespaces, so
+	// resources generated in another namespace than a namespaced trigger are kept.
+	// Optional. Generated resources are kept if not specified.
+	// +optional
+	OrphanDependents *bool `json:"orphanDependents,omitempty" yaml:"orphanDependents,omitempty"`
+
 	// Data provides the resource declaration used to populate each generated resource.
 	// At most one of Data or Clone must be specified. If neither are provided, the generated
 	// resource will be created with default data only.
@@ -370,6 +604,26 @@ type Generation struct {
 	// resource will be created with default data only.
 	// +optional
 	Clone CloneFrom `json:"clone,omitempty" yaml:"clone,omitempty"`
+
+	// CloneList specifies the source resources cloned to generate a resource for each of them.
+	// CloneList cannot be combined with Data or Clone.
+	// +optional
+	CloneList CloneList `json:"cloneList,omitempty" yaml:"cloneList,omitempty"`
+}
+
+// CloneList provides the location of the source resources used to generate target resources.
+type CloneList struct {
+
+	// Namespace specifies the source resources namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// Kinds is a list of source resource kinds.
+	Kinds []string `json:"kinds,omitempty" yaml:"kinds,omitempty"`
+
+	// Selector is a label selector for the source resources.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
 }
 
 // CloneFrom provides the location of the source resource used to generate target resources.
@@ -382,6 +636,27 @@ type CloneFrom struct {
 
 	// Name specifies name of the resource.
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Exclude are JSON pointers to fields of the source resource which are not cloned,
+	// such as keys of a Secret.
+	// +optional
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+
+	// Transformations set fields of the cloned resource, such as a renamed label. They are
+	// applied in order after the excluded fields are removed.
+	// +optional
+	Transformations []CloneTransformation `json:"transformations,omitempty" yaml:"transformations,omitempty"`
+}
+
+// CloneTransformation sets a field of a cloned resource.
+type CloneTransformation struct {
+
+	// Path is the JSON pointer to the field, e.g. /metadata/labels/team.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Value is the value set for the field.
+	// +kubebuilder:validation:XPreserveUnknownFields
+	Value apiextensions.JSON `json:"value,omitempty" yaml:"value,omitempty"`
 }
 
 // PolicyStatus mostly contains runtime information related to policy execution.
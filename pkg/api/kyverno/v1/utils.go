@@ -3,6 +3,9 @@ package v1
 import (
 	"encoding/json"
 	"reflect"
+	"sort"
+
+	"github.com/minio/minio/pkg/wildcard"
 )
 
 // HasAutoGenAnnotation checks if a policy has auto-gen annotation
@@ -31,6 +34,81 @@ func (p *ClusterPolicy) BackgroundProcessingEnabled() bool {
 	return *p.Spec.Background
 }
 
+// EffectiveBackgroundRules returns the names of the rules applied in background scans
+func (p *ClusterPolicy) EffectiveBackgroundRules() []string {
+	if !p.BackgroundProcessingEnabled() {
+		return nil
+	}
+
+	var rules []string
+	for _, rule := range p.Spec.Rules {
+		if !rule.SkipBackgroundRequests {
+			rules = append(rules, rule.Name)
+		}
+	}
+
+	return rules
+}
+
+// EffectiveOperations returns the admission operations the rule applies to. Mutate rules
+// are applied on CREATE and UPDATE, other rules also on DELETE, unless operations are
+// set in the match block. nil is returned if the rule is not found.
+func (p *ClusterPolicy) EffectiveOperations(ruleName string) []string {
+	for _, rule := range p.Spec.Rules {
+		if rule.Name != ruleName {
+			continue
+		}
+
+		if len(rule.MatchResources.Operations) > 0 {
+			return rule.MatchResources.Operations
+		}
+
+		if rule.HasMutate() {
+			return []string{"CREATE", "UPDATE"}
+		}
+
+		return []string{"CREATE", "UPDATE", "DELETE"}
+	}
+
+	return nil
+}
+
+// EffectiveFailureActionForNamespace returns the validation failure action applied to
+// resources of the namespace: the action of the first override listing the namespace, or
+// the failure action of the policy, which defaults to "audit"
+func (p *ClusterPolicy) EffectiveFailureActionForNamespace(namespace string) string {
+	for _, override := range p.Spec.FailureActionOverrides {
+		for _, pattern := range override.Namespaces {
+			if wildcard.Match(pattern, namespace) {
+				return override.Action
+			}
+		}
+	}
+
+	if p.Spec.ValidationFailureAction == "" {
+		return "audit"
+	}
+
+	return p.Spec.ValidationFailureAction
+}
+
+// RulesInPriorityOrder returns the rules of the policy in execution order: rules with a
+// priority sorted by ascending priority, followed by the rules without priority in
+// declaration order
+func (p *ClusterPolicy) RulesInPriorityOrder() []Rule {
+	rules := make([]Rule, len(p.Spec.Rules))
+	copy(rules, p.Spec.Rules)
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Priority == 0 || rules[j].Priority == 0 {
+			return rules[j].Priority == 0 && rules[i].Priority != 0
+		}
+
+		return rules[i].Priority < rules[j].Priority
+	})
+
+	return rules
+}
+
 // HasMutate checks for mutate rule
 func (r Rule) HasMutate() bool {
 	return !reflect.DeepEqual(r.Mutation, Mutation{})
@@ -41,13 +119,45 @@ func (r Rule) HasValidate() bool {
 	return !reflect.DeepEqual(r.Validation, Validation{})
 }
 
-// HasGenerate checks for generate rule
+// HasGenerate checks for generate rule, declared either in the generate block or in
+// generations
 func (r Rule) HasGenerate() bool {
-	return !reflect.DeepEqual(r.Generation, Generation{})
+	return !reflect.DeepEqual(r.Generation, Generation{}) || len(r.Generations) > 0
+}
+
+// GenerateTargets returns the generate block of the rule, if any, followed by the entries
+// of generations
+func (r Rule) GenerateTargets() []Generation {
+	var targets []Generation
+	if !reflect.DeepEqual(r.Generation, Generation{}) {
+		targets = append(targets, r.Generation)
+	}
+
+	return append(targets, r.Generations...)
 }
 
 // DeserializeAnyPattern deserialize apiextensions.JSON to []interface{}
 func (in *Validation) DeserializeAnyPattern() ([]interface{}, error) {
+	entries, err := in.DeserializeAnyPatternEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	if entries == nil {
+		return nil, nil
+	}
+
+	res := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		res[i] = entry.Pattern
+	}
+
+	return res, nil
+}
+
+// DeserializeAnyPatternEntries deserialize apiextensions.JSON to []AnyPatternEntry.
+// Entries that are plain patterns have no message.
+func (in *Validation) DeserializeAnyPatternEntries() ([]AnyPatternEntry, error) {
 	if in.AnyPattern == nil {
 		return nil, nil
 	}
@@ -62,7 +172,57 @@ func (in *Validation) DeserializeAnyPattern() ([]interface{}, error) {
 		return nil, err
 	}
 
-	return res, nil
+	entries := make([]AnyPatternEntry, len(res))
+	for i, element := range res {
+		if entry, ok := toAnyPatternEntry(element); ok {
+			entries[i] = entry
+		} else {
+			entries[i] = AnyPatternEntry{Pattern: element}
+		}
+	}
+
+	return entries, nil
+}
+
+// toAnyPatternEntry converts an element with a pattern or foreach key, and otherwise
+// only the message key, to a typed entry
+func toAnyPatternEntry(element interface{}) (AnyPatternEntry, bool) {
+	elementMap, ok := element.(map[string]interface{})
+	if !ok {
+		return AnyPatternEntry{}, false
+	}
+
+	_, hasPattern := elementMap["pattern"]
+	_, hasForEach := elementMap["foreach"]
+	if !hasPattern && !hasForEach {
+		return AnyPatternEntry{}, false
+	}
+
+	var entry AnyPatternEntry
+	for key, value := range elementMap {
+		switch key {
+		case "pattern":
+			entry.Pattern = value
+		case "message":
+			message, ok := value.(string)
+			if !ok {
+				return AnyPatternEntry{}, false
+			}
+			entry.Message = message
+		case "foreach":
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return AnyPatternEntry{}, false
+			}
+			if err := json.Unmarshal(raw, &entry.ForEach); err != nil {
+				return AnyPatternEntry{}, false
+			}
+		default:
+			return AnyPatternEntry{}, false
+		}
+	}
+
+	return entry, true
 }
 
 // DeepCopyInto is declared because k8s:deepcopy-gen is
@@ -81,9 +241,57 @@ func (pp *Patch) DeepCopyInto(out *Patch) {
 	}
 }
 
+// DeepCopyInto is declared because k8s:deepcopy-gen is
+// not able to generate this method for interface{} member
+func (in *CloneTransformation) DeepCopyInto(out *CloneTransformation) {
+	if out != nil {
+		*out = *in
+	}
+}
+
 // DeepCopyInto is declared because k8s:deepcopy-gen is
 // not able to generate this method for interface{} member
 func (in *Validation) DeepCopyInto(out *Validation) {
+	if out != nil {
+		*out = *in
+		if in.Deny != nil {
+			out.Deny = in.Deny.DeepCopy()
+		}
+		if in.PodSecurity != nil {
+			out.PodSecurity = in.PodSecurity.DeepCopy()
+		}
+		if in.ForEach != nil {
+			out.ForEach = make([]ForEachValidation, len(in.ForEach))
+			for i := range in.ForEach {
+				in.ForEach[i].DeepCopyInto(&out.ForEach[i])
+			}
+		}
+		if in.Manifests != nil {
+			out.Manifests = in.Manifests.DeepCopy()
+		}
+		if in.CEL != nil {
+			out.CEL = in.CEL.DeepCopy()
+		}
+	}
+}
+
+// DeepCopyInto is declared because k8s:deepcopy-gen is
+// not able to generate this method for interface{} member
+func (in *AnyPatternEntry) DeepCopyInto(out *AnyPatternEntry) {
+	if out != nil {
+		*out = *in
+		if in.ForEach != nil {
+			out.ForEach = make([]ForEachValidation, len(in.ForEach))
+			for i := range in.ForEach {
+				in.ForEach[i].DeepCopyInto(&out.ForEach[i])
+			}
+		}
+	}
+}
+
+// DeepCopyInto is declared because k8s:deepcopy-gen is
+// not able to generate this method for interface{} member
+func (in *ForEachValidation) DeepCopyInto(out *ForEachValidation) {
 	if out != nil {
 		*out = *in
 	}
@@ -94,6 +302,12 @@ func (in *Validation) DeepCopyInto(out *Validation) {
 func (gen *Generation) DeepCopyInto(out *Generation) {
 	if out != nil {
 		*out = *gen
+		if gen.OrphanDependents != nil {
+			orphanDependents := *gen.OrphanDependents
+			out.OrphanDependents = &orphanDependents
+		}
+		gen.Clone.DeepCopyInto(&out.Clone)
+		gen.CloneList.DeepCopyInto(&out.CloneList)
 	}
 }
 
@@ -0,0 +1,200 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_DeserializeAnyPatternEntries(t *testing.T) {
+	rawValidation := []byte(`
+	{
+		"anyPattern": [
+			{
+				"pattern": {"metadata": {"labels": {"app": "?*"}}},
+				"message": "An app label is required"
+			},
+			{
+				"pattern": {"metadata": {"labels": {"name": "?*"}}}
+			},
+			{
+				"metadata": {"labels": {"team": "?*"}}
+			},
+			{
+				"pattern": {"metadata": {"labels": {"tier": "?*"}}},
+				"spec": {"replicas": ">1"}
+			}
+		]
+	}`)
+
+	var validation Validation
+	err := json.Unmarshal(rawValidation, &validation)
+	assert.NilError(t, err)
+
+	entries, err := validation.DeserializeAnyPatternEntries()
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 4)
+
+	assert.Equal(t, entries[0].Message, "An app label is required")
+	assert.DeepEqual(t, entries[0].Pattern, map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "?*"}}})
+
+	assert.Equal(t, entries[1].Message, "")
+	assert.DeepEqual(t, entries[1].Pattern, map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"name": "?*"}}})
+
+	// untyped entries are used as patterns
+	assert.Equal(t, entries[2].Message, "")
+	assert.DeepEqual(t, entries[2].Pattern, map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"team": "?*"}}})
+
+	_, isPattern := entries[3].Pattern.(map[string]interface{})["spec"]
+	assert.Assert(t, isPattern)
+
+	patterns, err := validation.DeserializeAnyPattern()
+	assert.NilError(t, err)
+	assert.Equal(t, len(patterns), 4)
+	assert.DeepEqual(t, patterns[0], entries[0].Pattern)
+}
+
+func Test_EffectiveBackgroundRules(t *testing.T) {
+	testcases := []struct {
+		description string
+		spec        []byte
+		expected    []string
+	}{
+		{
+			description: "background enabled by default",
+			spec:        []byte(`{"rules":[{"name":"a"},{"name":"b"}]}`),
+			expected:    []string{"a", "b"},
+		},
+		{
+			description: "rule skipping background requests",
+			spec:        []byte(`{"rules":[{"name":"a","skipBackgroundRequests":true},{"name":"b"}]}`),
+			expected:    []string{"b"},
+		},
+		{
+			description: "background disabled",
+			spec:        []byte(`{"background":false,"rules":[{"name":"a"},{"name":"b"}]}`),
+			expected:    nil,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy ClusterPolicy
+		err := json.Unmarshal(testcase.spec, &policy.Spec)
+		assert.NilError(t, err, testcase.description)
+		assert.DeepEqual(t, policy.EffectiveBackgroundRules(), testcase.expected)
+	}
+}
+
+func Test_EffectiveOperations(t *testing.T) {
+	rawSpec := []byte(`{"rules":[
+		{"name":"explicit","match":{"resources":{"kinds":["Pod"]},"operations":["CREATE"]},"validate":{"pattern":{"spec":{"hostNetwork":false}}}},
+		{"name":"mutate","match":{"resources":{"kinds":["Pod"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"app":"nginx"}}}}},
+		{"name":"validate","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"hostNetwork":false}}}}
+	]}`)
+
+	var policy ClusterPolicy
+	err := json.Unmarshal(rawSpec, &policy.Spec)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, policy.EffectiveOperations("explicit"), []string{"CREATE"})
+	assert.DeepEqual(t, policy.EffectiveOperations("mutate"), []string{"CREATE", "UPDATE"})
+	assert.DeepEqual(t, policy.EffectiveOperations("validate"), []string{"CREATE", "UPDATE", "DELETE"})
+	assert.Assert(t, policy.EffectiveOperations("missing") == nil)
+}
+
+func Test_RulesInPriorityOrder(t *testing.T) {
+	rawSpec := []byte(`{"rules":[{"name":"a"},{"name":"b","priority":2},{"name":"c"},{"name":"d","priority":1}]}`)
+
+	var policy ClusterPolicy
+	err := json.Unmarshal(rawSpec, &policy.Spec)
+	assert.NilError(t, err)
+
+	var names []string
+	for _, rule := range policy.RulesInPriorityOrder() {
+		names = append(names, rule.Name)
+	}
+
+	assert.DeepEqual(t, names, []string{"d", "b", "a", "c"})
+	assert.Equal(t, policy.Spec.Rules[0].Name, "a")
+}
+
+func Test_GenerateTargets(t *testing.T) {
+	testcases := []struct {
+		rule  []byte
+		kinds []string
+	}{
+		{rule: []byte(`{"name":"r","validate":{"deny":{}}}`)},
+		{rule: []byte(`{"name":"r","generate":{"kind":"ConfigMap","name":"cm"}}`), kinds: []string{"ConfigMap"}},
+		{rule: []byte(`{"name":"r","generations":[{"kind":"ConfigMap","name":"cm"},{"kind":"Secret","name":"s"}]}`), kinds: []string{"ConfigMap", "Secret"}},
+	}
+
+	for _, testcase := range testcases {
+		var rule Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err)
+
+		var kinds []string
+		for _, generation := range rule.GenerateTargets() {
+			kinds = append(kinds, generation.Kind)
+		}
+
+		assert.DeepEqual(t, kinds, testcase.kinds)
+		assert.Equal(t, rule.HasGenerate(), len(testcase.kinds) > 0)
+	}
+}
+
+func Test_DeepCopy_Rule(t *testing.T) {
+	rawRules := [][]byte{
+		[]byte(`{"name":"v","validate":{"foreach":[{"list":"request.object.spec.containers","pattern":{"name":"*"}}],"manifests":{"attestors":[{"entries":[{"keys":"key"}]}],"ignoreFields":["spec.replicas"]},"cel":{"expression":"true"},"deny":{"conditions":[{"key":"a","operator":"Equals","value":"b"}]}}}`),
+		[]byte(`{"name":"g","generate":{"kind":"Secret","name":"s","orphanDependents":true,"clone":{"namespace":"default","name":"s","exclude":["/data/a"],"transformations":[{"path":"/data/b","value":"c"}]},"cloneList":{"namespace":"default","kinds":["Secret"],"selector":{"matchLabels":{"app":"web"}}}}}`),
+	}
+
+	for _, rawRule := range rawRules {
+		var rule Rule
+		err := json.Unmarshal(rawRule, &rule)
+		assert.NilError(t, err)
+
+		copied := rule.DeepCopy()
+		assert.DeepEqual(t, *copied, rule)
+
+		if rule.HasValidate() {
+			copied.Validation.ForEach[0].List = "request.object.spec.volumes"
+			copied.Validation.Manifests.IgnoreFields[0] = "metadata"
+			copied.Validation.CEL.Expression = "false"
+			copied.Validation.Deny.Conditions[0].Operator = "NotEquals"
+			assert.Equal(t, rule.Validation.ForEach[0].List, "request.object.spec.containers")
+			assert.Equal(t, rule.Validation.Manifests.IgnoreFields[0], "spec.replicas")
+			assert.Equal(t, rule.Validation.CEL.Expression, "true")
+			assert.Equal(t, string(rule.Validation.Deny.Conditions[0].Operator), "Equals")
+		}
+
+		if rule.HasGenerate() {
+			*copied.Generation.OrphanDependents = false
+			copied.Generation.Clone.Exclude[0] = "/data/z"
+			copied.Generation.Clone.Transformations[0].Path = "/data/z"
+			copied.Generation.CloneList.Kinds[0] = "ConfigMap"
+			copied.Generation.CloneList.Selector.MatchLabels["app"] = "db"
+			assert.Equal(t, *rule.Generation.OrphanDependents, true)
+			assert.Equal(t, rule.Generation.Clone.Exclude[0], "/data/a")
+			assert.Equal(t, rule.Generation.Clone.Transformations[0].Path, "/data/b")
+			assert.Equal(t, rule.Generation.CloneList.Kinds[0], "Secret")
+			assert.Equal(t, rule.Generation.CloneList.Selector.MatchLabels["app"], "web")
+		}
+	}
+}
+
+func Test_EffectiveFailureActionForNamespace(t *testing.T) {
+	var policy ClusterPolicy
+	err := json.Unmarshal([]byte(`{"validationFailureAction":"audit","failureActionOverrides":[{"action":"enforce","namespaces":["prod-*","payments"]},{"action":"audit","namespaces":["dev-*"]}]}`), &policy.Spec)
+	assert.NilError(t, err)
+
+	assert.Equal(t, policy.EffectiveFailureActionForNamespace("prod-eu"), "enforce")
+	assert.Equal(t, policy.EffectiveFailureActionForNamespace("payments"), "enforce")
+	assert.Equal(t, policy.EffectiveFailureActionForNamespace("dev-1"), "audit")
+	assert.Equal(t, policy.EffectiveFailureActionForNamespace("staging"), "audit")
+
+	policy.Spec.ValidationFailureAction = ""
+	policy.Spec.FailureActionOverrides = nil
+	assert.Equal(t, policy.EffectiveFailureActionForNamespace("prod-eu"), "audit")
+}
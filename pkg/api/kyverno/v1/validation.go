@@ -0,0 +1,727 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/minio/minio/pkg/wildcard"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/yaml"
+)
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidateMatchDoesNotDependOnMutation returns an error if a rule matches labels or annotations
+// written by a mutate rule executed before it in the policy. Such a rule depends on the order
+// and the outcome of the mutation in a single admission pass, and does not match resources the
+// mutation was not applied to.
+func (p *ClusterPolicy) ValidateMatchDoesNotDependOnMutation() error {
+	rules := p.RulesInPriorityOrder()
+	for i, rule := range rules {
+		for _, earlier := range rules[:i] {
+			if !earlier.HasMutate() {
+				continue
+			}
+
+			for _, field := range []string{"labels", "annotations"} {
+				written := writtenMetadataKeys(earlier.Mutation, field)
+				for _, key := range matchedMetadataKeys(rule.MatchResources, field) {
+					if written[key] {
+						return fmt.Errorf("rule %s matches the %s %s written by the earlier mutate rule %s, which requires the mutation to be applied first", rule.Name, strings.TrimSuffix(field, "s"), key, earlier.Name)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// writtenMetadataKeys returns the keys of the labels or annotations written by the mutation
+func writtenMetadataKeys(mutation Mutation, field string) map[string]bool {
+	written := make(map[string]bool)
+	for _, overlay := range []interface{}{mutation.Overlay, mutation.PatchStrategicMerge} {
+		resource, _ := overlay.(map[string]interface{})
+		metadata, _ := resource["metadata"].(map[string]interface{})
+		values, _ := metadata[field].(map[string]interface{})
+		for key := range values {
+			// keys under condition anchors are not written
+			if strings.HasPrefix(key, "(") {
+				continue
+			}
+
+			if groups := regexAnchor.FindStringSubmatch(key); groups != nil {
+				key = groups[1]
+			}
+			written[key] = true
+		}
+	}
+
+	patches := mutation.Patches
+	if mutation.PatchesJSON6902 != "" {
+		var patchesJSON6902 []Patch
+		if err := yaml.Unmarshal([]byte(mutation.PatchesJSON6902), &patchesJSON6902); err == nil {
+			patches = append(append([]Patch{}, patches...), patchesJSON6902...)
+		}
+	}
+
+	prefix := "/metadata/" + field
+	for _, patch := range patches {
+		if patch.Operation != "add" && patch.Operation != "replace" {
+			continue
+		}
+
+		if patch.Path == prefix {
+			values, _ := patch.Value.(map[string]interface{})
+			for key := range values {
+				written[key] = true
+			}
+		} else if strings.HasPrefix(patch.Path, prefix+"/") {
+			key := strings.TrimPrefix(patch.Path, prefix+"/")
+			written[strings.ReplaceAll(strings.ReplaceAll(key, "~1", "/"), "~0", "~")] = true
+		}
+	}
+
+	return written
+}
+
+// matchedMetadataKeys returns the sorted keys of the labels or annotations used by the
+// selectors and annotations of the match block
+func matchedMetadataKeys(match MatchResources, field string) []string {
+	keys := make(map[string]bool)
+	descriptions := append([]ResourceDescription{match.ResourceDescription}, match.Any...)
+	for _, description := range append(descriptions, match.All...) {
+		if field == "annotations" {
+			for key := range description.Annotations {
+				keys[key] = true
+			}
+			continue
+		}
+
+		if description.Selector == nil {
+			continue
+		}
+
+		for key := range description.Selector.MatchLabels {
+			keys[key] = true
+		}
+
+		for _, expression := range description.Selector.MatchExpressions {
+			keys[expression.Key] = true
+		}
+	}
+
+	return sortedKeys(keys)
+}
+
+// ValidateNameConvention returns an error if the name of the policy does not match pattern,
+// a glob such as "security-*", or a regular expression if prefixed with "regex:"
+func (p *ClusterPolicy) ValidateNameConvention(pattern string) error {
+	if expression := strings.TrimPrefix(pattern, "regex:"); expression != pattern {
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			return fmt.Errorf("invalid naming convention %s: %v", pattern, err)
+		}
+
+		if !re.MatchString(p.Name) {
+			return fmt.Errorf("policy name %s does not match the naming convention %s", p.Name, pattern)
+		}
+
+		return nil
+	}
+
+	if !wildcard.Match(pattern, p.Name) {
+		return fmt.Errorf("policy name %s does not match the naming convention %s", p.Name, pattern)
+	}
+
+	return nil
+}
+
+// ValidateAllowedRuleTypes returns an error if a rule of the policy is of a type not listed
+// in allowed. Rule types are "mutate", "validate" and "generate".
+func (p *ClusterPolicy) ValidateAllowedRuleTypes(allowed []string) error {
+	allowedTypes := make(map[string]bool, len(allowed))
+	for _, ruleType := range allowed {
+		if ruleType != "mutate" && ruleType != "validate" && ruleType != "generate" {
+			return fmt.Errorf("unknown rule type %q, expected one of mutate, validate, generate", ruleType)
+		}
+		allowedTypes[ruleType] = true
+	}
+
+	for _, rule := range p.Spec.Rules {
+		ruleTypes := map[string]bool{
+			"mutate":   rule.HasMutate(),
+			"validate": rule.HasValidate(),
+			"generate": rule.HasGenerate(),
+		}
+
+		for _, ruleType := range []string{"mutate", "validate", "generate"} {
+			if ruleTypes[ruleType] && !allowedTypes[ruleType] {
+				return fmt.Errorf("rule %s: %s rules are not allowed", rule.Name, ruleType)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateExcludedSubjects returns an error if the policy, or one of its rules, excludes a
+// subject that must be enforced. Required subjects are user names, group names, or service
+// accounts as system:serviceaccount:<namespace>:<name>.
+func (p *ClusterPolicy) ValidateExcludedSubjects(required []string) error {
+	if p.Spec.Exclude != nil {
+		if subject, ok := excludedSubject(p.Spec.Exclude.Subjects, required); ok {
+			return fmt.Errorf("spec.exclude exempts the required subject %s", subject)
+		}
+	}
+
+	for _, rule := range p.Spec.Rules {
+		if subject, ok := excludedSubject(rule.ExcludeResources.Subjects, required); ok {
+			return fmt.Errorf("rule %s: exclude exempts the required subject %s", rule.Name, subject)
+		}
+	}
+
+	return nil
+}
+
+// excludedSubject returns the first required subject among the subjects
+func excludedSubject(subjects []rbacv1.Subject, required []string) (string, bool) {
+	for _, subject := range subjects {
+		name := subject.Name
+		if subject.Kind == rbacv1.ServiceAccountKind {
+			name = "system:serviceaccount:" + subject.Namespace + ":" + subject.Name
+		}
+
+		for _, r := range required {
+			if r == name {
+				return name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// ValidateDenyConditions checks each deny condition uses a supported operator and a value
+// of the expected arity: a list for set operators and a scalar for numeric comparisons.
+// An error is returned for each malformed condition.
+func (in *Validation) ValidateDenyConditions() []error {
+	if in.Deny == nil {
+		return nil
+	}
+
+	var errs []error
+	for i, condition := range in.Deny.Conditions {
+		if err := validateConditionOperator(condition); err != nil {
+			errs = append(errs, fmt.Errorf("conditions[%d]: %v", i, err))
+		}
+	}
+
+	return errs
+}
+
+// ValidateOperator checks the condition uses a supported operator and a value of the
+// expected arity
+func (cond Condition) ValidateOperator() error {
+	return validateConditionOperator(cond)
+}
+
+func validateConditionOperator(condition Condition) error {
+	switch condition.Operator {
+	case Equal, Equals, NotEqual, NotEquals:
+		return nil
+	case In, NotIn, AnyIn, AllIn:
+		if !isListValue(condition.Value) {
+			return fmt.Errorf("operator %s expects a list value, found %T", condition.Operator, condition.Value)
+		}
+	case GreaterThan, GreaterThanOrEquals, LessThan, LessThanOrEquals:
+		switch condition.Value.(type) {
+		case string, float64, int, int64:
+			return nil
+		default:
+			return fmt.Errorf("operator %s expects a scalar value, found %T", condition.Operator, condition.Value)
+		}
+	default:
+		return fmt.Errorf("unsupported operator %q, expected one of Equals, NotEquals, In, NotIn, AnyIn, AllIn, GreaterThan, GreaterThanOrEquals, LessThan, LessThanOrEquals", condition.Operator)
+	}
+
+	return nil
+}
+
+// isListValue checks if the value is a list, a JSON encoded list or a variable
+func isListValue(value interface{}) bool {
+	switch typed := value.(type) {
+	case []interface{}:
+		return true
+	case string:
+		if regexVariables.MatchString(typed) {
+			return true
+		}
+
+		var list []interface{}
+		return json.Unmarshal([]byte(typed), &list) == nil
+	default:
+		return false
+	}
+}
+
+// ValidateCloneSource checks the clone source of the generate rule declares a namespace and
+// a name. If exists is not nil and the kind, namespace and name of the source do not use
+// variables, exists is called to verify the source resource is present.
+func (gen *Generation) ValidateCloneSource(exists func(namespace, name, kind string) (bool, error)) error {
+	if reflect.DeepEqual(gen.Clone, CloneFrom{}) {
+		return nil
+	}
+
+	if gen.Clone.Namespace == "" {
+		return fmt.Errorf("clone.namespace cannot be empty")
+	}
+
+	if gen.Clone.Name == "" {
+		return fmt.Errorf("clone.name cannot be empty")
+	}
+
+	if exists == nil {
+		return nil
+	}
+
+	for _, value := range []string{gen.Kind, gen.Clone.Namespace, gen.Clone.Name} {
+		if regexVariables.MatchString(value) {
+			return nil
+		}
+	}
+
+	ok, err := exists(gen.Clone.Namespace, gen.Clone.Name, gen.Kind)
+	if err != nil {
+		return fmt.Errorf("failed to get clone source %s %s/%s: %v", gen.Kind, gen.Clone.Namespace, gen.Clone.Name, err)
+	}
+
+	if !ok {
+		return fmt.Errorf("clone source %s %s/%s not found", gen.Kind, gen.Clone.Namespace, gen.Clone.Name)
+	}
+
+	return nil
+}
+
+// ValidateLabelPreservation returns an error if the mutation replaces the labels of the
+// resource wholesale without setting the required label keys, which drops them from the
+// resource. Overlays and strategic merge patches merge labels unless they use the
+// "$patch: replace" directive, while JSON patches adding or replacing /metadata/labels
+// always replace them.
+func (in *Mutation) ValidateLabelPreservation(required []string) error {
+	if len(required) == 0 {
+		return nil
+	}
+
+	overlays := map[string]interface{}{"overlay": in.Overlay, "patchStrategicMerge": in.PatchStrategicMerge}
+	for _, path := range []string{"overlay", "patchStrategicMerge"} {
+		labels, ok := metadataLabels(overlays[path])
+		if !ok || labels["$patch"] != "replace" {
+			continue
+		}
+
+		if missing := missingLabels(labels, required); len(missing) > 0 {
+			return fmt.Errorf("%s.metadata.labels: labels are replaced without the required keys %s", path, strings.Join(missing, ", "))
+		}
+	}
+
+	patches := map[string][]Patch{"patches": in.Patches}
+	if in.PatchesJSON6902 != "" {
+		var patchesJSON6902 []Patch
+		if err := yaml.Unmarshal([]byte(in.PatchesJSON6902), &patchesJSON6902); err == nil {
+			patches["patchesJson6902"] = patchesJSON6902
+		}
+	}
+
+	for _, path := range []string{"patches", "patchesJson6902"} {
+		for i, patch := range patches[path] {
+			if strings.TrimSuffix(patch.Path, "/") != "/metadata/labels" || (patch.Operation != "add" && patch.Operation != "replace") {
+				continue
+			}
+
+			labels, _ := patch.Value.(map[string]interface{})
+			if missing := missingLabels(labels, required); len(missing) > 0 {
+				return fmt.Errorf("%s[%d]: labels are replaced without the required keys %s", path, i, strings.Join(missing, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+func metadataLabels(element interface{}) (map[string]interface{}, bool) {
+	resource, ok := element.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	metadata, ok := resource["metadata"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	labels, ok := metadata["labels"].(map[string]interface{})
+	return labels, ok
+}
+
+func missingLabels(labels map[string]interface{}, required []string) []string {
+	var missing []string
+	for _, key := range required {
+		if _, ok := labels[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+
+	return missing
+}
+
+// ValidateAPIVersionConsistency returns an error if the rule uses different apiVersions for
+// the same kind across its patterns, overlays and generated resources. Resources without a
+// kind are of the kind matched by the rule, if it matches a single kind. apiVersions using
+// wildcards or variables are not compared.
+func (r *Rule) ValidateAPIVersionConsistency() error {
+	var defaultKind string
+	if len(r.MatchResources.Kinds) == 1 {
+		defaultKind = r.MatchResources.Kinds[0]
+	}
+
+	versions := make(map[string]map[string]string)
+	add := func(source, kind, apiVersion string) {
+		if kind == "" || apiVersion == "" || strings.ContainsAny(apiVersion, "*?|{") || strings.ContainsAny(kind, "*?|{") {
+			return
+		}
+
+		if versions[kind] == nil {
+			versions[kind] = make(map[string]string)
+		}
+		if _, ok := versions[kind][apiVersion]; !ok {
+			versions[kind][apiVersion] = source
+		}
+	}
+
+	addResource := func(source string, element interface{}, kind string) {
+		resource, ok := element.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		if value, ok := resource["kind"].(string); ok {
+			kind = value
+		}
+		if apiVersion, ok := resource["apiVersion"].(string); ok {
+			add(source, kind, apiVersion)
+		}
+	}
+
+	addResource("validate.pattern", r.Validation.Pattern, defaultKind)
+	if entries, err := r.Validation.DeserializeAnyPatternEntries(); err == nil {
+		for i, entry := range entries {
+			addResource(fmt.Sprintf("validate.anyPattern[%d]", i), entry.Pattern, defaultKind)
+		}
+	}
+
+	addResource("mutate.overlay", r.Mutation.Overlay, defaultKind)
+	addResource("mutate.patchStrategicMerge", r.Mutation.PatchStrategicMerge, defaultKind)
+	add("generate", r.Generation.Kind, r.Generation.APIVersion)
+	addResource("generate.data", r.Generation.Data, r.Generation.Kind)
+	for i, generation := range r.Generations {
+		source := fmt.Sprintf("generations[%d]", i)
+		add(source, generation.Kind, generation.APIVersion)
+		addResource(source+".data", generation.Data, generation.Kind)
+	}
+
+	kinds := make([]string, 0, len(versions))
+	for kind := range versions {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		if len(versions[kind]) < 2 {
+			continue
+		}
+
+		var used []string
+		for apiVersion, source := range versions[kind] {
+			used = append(used, fmt.Sprintf("%s in %s", apiVersion, source))
+		}
+		sort.Strings(used)
+		return fmt.Errorf("rule %s uses different apiVersions for %s: %s", r.Name, kind, strings.Join(used, ", "))
+	}
+
+	return nil
+}
+
+// listMergeKeys are the fields identifying the entries of Kubernetes lists merged by key
+var listMergeKeys = []string{"containerPort", "devicePath", "ip", "mountPath", "name"}
+
+// ValidateIdempotentListMerge returns an error if an entry of a list of the overlay or the
+// strategic merge patch has no field identifying it, such as a name. Such entries are
+// appended to the list, and duplicated each time the resource is admitted again on UPDATE.
+// Entries holding only conditions are not written and are not checked.
+func (in *Mutation) ValidateIdempotentListMerge() error {
+	if err := findKeylessEntries(in.Overlay, "overlay"); err != nil {
+		return err
+	}
+
+	return findKeylessEntries(in.PatchStrategicMerge, "patchStrategicMerge")
+}
+
+func findKeylessEntries(element interface{}, path string) error {
+	switch typed := element.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := findKeylessEntries(typed[key], path+"."+key); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, value := range typed {
+			entryPath := fmt.Sprintf("%s[%d]", path, i)
+			if entry, ok := value.(map[string]interface{}); ok && !hasMergeKey(entry) && writesFields(entry) {
+				return fmt.Errorf("%s: entry has none of the fields %s and is appended again each time the resource is updated", entryPath, strings.Join(listMergeKeys, ", "))
+			}
+
+			if err := findKeylessEntries(value, entryPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasMergeKey(entry map[string]interface{}) bool {
+	for key := range entry {
+		if groups := regexAnchor.FindStringSubmatch(key); groups != nil {
+			key = groups[1]
+		}
+
+		for _, mergeKey := range listMergeKeys {
+			if key == mergeKey {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// writesFields returns true if the entry has fields which are not conditions
+func writesFields(entry map[string]interface{}) bool {
+	for key := range entry {
+		if !strings.HasPrefix(key, "(") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateNonRedundant returns an error if the overlay or the strategic merge patch sets a
+// field to its default value, which has no effect and causes needless updates. defaults maps
+// field paths, with map keys joined by dots and list indexes left out, to their default
+// values, e.g. "spec.containers.imagePullPolicy" to "IfNotPresent". Fields under condition
+// anchors are not checked, fields with add anchors are.
+func (in *Mutation) ValidateNonRedundant(defaults map[string]interface{}) error {
+	if len(defaults) == 0 {
+		return nil
+	}
+
+	if err := findDefaultValues(in.Overlay, "overlay", "", defaults); err != nil {
+		return err
+	}
+
+	return findDefaultValues(in.PatchStrategicMerge, "patchStrategicMerge", "", defaults)
+}
+
+func findDefaultValues(element interface{}, path, field string, defaults map[string]interface{}) error {
+	switch typed := element.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			name := key
+			if groups := regexAnchor.FindStringSubmatch(key); groups != nil {
+				if !strings.HasPrefix(key, "+(") {
+					continue
+				}
+				name = groups[1]
+			}
+
+			fieldPath := name
+			if field != "" {
+				fieldPath = field + "." + name
+			}
+
+			if value, ok := defaults[fieldPath]; ok && reflect.DeepEqual(typed[key], value) {
+				return fmt.Errorf("%s.%s: %v is the default value of %s, setting it has no effect", path, key, value, fieldPath)
+			}
+
+			if err := findDefaultValues(typed[key], path+"."+key, fieldPath, defaults); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, value := range typed {
+			if err := findDefaultValues(value, fmt.Sprintf("%s[%d]", path, i), field, defaults); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateOverlayAnchors checks the overlay and the strategic merge patch of the mutation do
+// not use existence anchors ^() or negation anchors X(), which only apply to validation
+// patterns. Conditional, equality and adding anchors are allowed.
+func (in *Mutation) ValidateOverlayAnchors() error {
+	if err := validateOverlayAnchors(in.Overlay, "overlay"); err != nil {
+		return err
+	}
+
+	return validateOverlayAnchors(in.PatchStrategicMerge, "patchStrategicMerge")
+}
+
+func validateOverlayAnchors(element interface{}, path string) error {
+	switch typed := element.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if groups := regexAnchor.FindStringSubmatch(key); groups != nil {
+				switch key[0] {
+				case '^':
+					return fmt.Errorf("%s.%s: existence anchor on %s is not supported in mutate overlays", path, key, groups[1])
+				case 'X':
+					return fmt.Errorf("%s.%s: negation anchor on %s is not supported in mutate overlays", path, key, groups[1])
+				}
+			}
+
+			if err := validateOverlayAnchors(typed[key], path+"."+key); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, value := range typed {
+			if err := validateOverlayAnchors(value, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+var regexAnchor = regexp.MustCompile(`^[+=X^]?\((.+)\)$`)
+
+// ValidateMatchExcludeSelectorEquality returns an error if the exclude block selects resources
+// with the same label selectors as the match block and has no other condition narrowing it, so
+// every matched resource is also excluded. Selectors are compared in their normalized form, for
+// instance matchLabels {app: web} equals the expression app In [web].
+func (r *Rule) ValidateMatchExcludeSelectorEquality() error {
+	match, exclude := r.MatchResources, r.ExcludeResources
+	if exclude.Selector == nil && exclude.NamespaceSelector == nil {
+		return nil
+	}
+
+	if len(exclude.Any) > 0 || len(exclude.All) > 0 {
+		return nil
+	}
+
+	for _, selectors := range [][2]*metav1.LabelSelector{
+		{match.Selector, exclude.Selector},
+		{match.NamespaceSelector, exclude.NamespaceSelector},
+	} {
+		if selectors[1] == nil {
+			continue
+		}
+
+		if selectors[0] == nil || !equalSelectors(selectors[0], selectors[1]) {
+			return nil
+		}
+	}
+
+	matchRest, excludeRest := match.ResourceDescription, exclude.ResourceDescription
+	matchRest.Selector, matchRest.NamespaceSelector = nil, nil
+	excludeRest.Selector, excludeRest.NamespaceSelector = nil, nil
+	if !reflect.DeepEqual(excludeRest, ResourceDescription{}) && !reflect.DeepEqual(excludeRest, matchRest) {
+		return nil
+	}
+
+	if !reflect.DeepEqual(exclude.UserInfo, UserInfo{}) && !reflect.DeepEqual(exclude.UserInfo, match.UserInfo) {
+		return nil
+	}
+
+	return fmt.Errorf("rule %s: exclude uses the same selectors as match, the rule never applies", r.Name)
+}
+
+// equalSelectors compares the label selectors once normalized, invalid selectors are never equal
+func equalSelectors(a, b *metav1.LabelSelector) bool {
+	normalizedA, err := normalizeSelector(a)
+	if err != nil {
+		return false
+	}
+
+	normalizedB, err := normalizeSelector(b)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(normalizedA, normalizedB)
+}
+
+// normalizeSelector returns the sorted requirements of the selector, where set based
+// requirements with a single value are written as equality based requirements
+func normalizeSelector(selector *metav1.LabelSelector) ([]string, error) {
+	parsed, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements, _ := parsed.Requirements()
+	normalized := make([]string, 0, len(requirements))
+	for _, requirement := range requirements {
+		values := requirement.Values().List()
+		switch {
+		case len(values) == 1 && (requirement.Operator() == selection.In || requirement.Operator() == selection.Equals || requirement.Operator() == selection.DoubleEquals):
+			normalized = append(normalized, requirement.Key()+"="+values[0])
+		case len(values) == 1 && (requirement.Operator() == selection.NotIn || requirement.Operator() == selection.NotEquals):
+			normalized = append(normalized, requirement.Key()+"!="+values[0])
+		default:
+			normalized = append(normalized, requirement.String())
+		}
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
@@ -0,0 +1,494 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_ValidateDenyConditions(t *testing.T) {
+	testcases := []struct {
+		description string
+		condition   Condition
+		expectError bool
+	}{
+		{description: "Equals", condition: Condition{Key: "{{request.operation}}", Operator: Equals, Value: "DELETE"}, expectError: false},
+		{description: "NotEquals", condition: Condition{Key: "{{request.operation}}", Operator: NotEquals, Value: "DELETE"}, expectError: false},
+		{description: "In with list", condition: Condition{Key: "{{request.operation}}", Operator: In, Value: []interface{}{"CREATE", "UPDATE"}}, expectError: false},
+		{description: "In with JSON list", condition: Condition{Key: "{{request.operation}}", Operator: In, Value: `["CREATE", "UPDATE"]`}, expectError: false},
+		{description: "In with variable", condition: Condition{Key: "{{request.operation}}", Operator: In, Value: "{{allowed.operations}}"}, expectError: false},
+		{description: "In with scalar", condition: Condition{Key: "{{request.operation}}", Operator: In, Value: "CREATE"}, expectError: true},
+		{description: "NotIn with list", condition: Condition{Key: "{{request.operation}}", Operator: NotIn, Value: []interface{}{"CREATE"}}, expectError: false},
+		{description: "AnyIn with list", condition: Condition{Key: "{{request.object.metadata.labels.*}}", Operator: AnyIn, Value: []interface{}{"prod"}}, expectError: false},
+		{description: "AnyIn with scalar", condition: Condition{Key: "{{request.object.metadata.labels.*}}", Operator: AnyIn, Value: 1.0}, expectError: true},
+		{description: "AllIn with list", condition: Condition{Key: "{{request.object.metadata.labels.*}}", Operator: AllIn, Value: []interface{}{"prod"}}, expectError: false},
+		{description: "AllIn with map", condition: Condition{Key: "{{request.object.metadata.labels.*}}", Operator: AllIn, Value: map[string]interface{}{"a": "b"}}, expectError: true},
+		{description: "GreaterThan with number", condition: Condition{Key: "{{request.object.spec.replicas}}", Operator: GreaterThan, Value: 3.0}, expectError: false},
+		{description: "GreaterThan with list", condition: Condition{Key: "{{request.object.spec.replicas}}", Operator: GreaterThan, Value: []interface{}{3.0}}, expectError: true},
+		{description: "GreaterThanOrEquals with string", condition: Condition{Key: "{{request.object.spec.replicas}}", Operator: GreaterThanOrEquals, Value: "3"}, expectError: false},
+		{description: "LessThan with number", condition: Condition{Key: "{{request.object.spec.replicas}}", Operator: LessThan, Value: 3.0}, expectError: false},
+		{description: "LessThanOrEquals with map", condition: Condition{Key: "{{request.object.spec.replicas}}", Operator: LessThanOrEquals, Value: map[string]interface{}{}}, expectError: true},
+		{description: "unknown operator", condition: Condition{Key: "{{request.operation}}", Operator: "Contains", Value: "DELETE"}, expectError: true},
+	}
+
+	for _, testcase := range testcases {
+		validation := Validation{Deny: &Deny{Conditions: []Condition{testcase.condition}}}
+		errs := validation.ValidateDenyConditions()
+		assert.Equal(t, len(errs) > 0, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_ValidateDenyConditions_ReportsEachCondition(t *testing.T) {
+	validation := Validation{
+		Deny: &Deny{
+			Conditions: []Condition{
+				{Key: "{{request.operation}}", Operator: "Contains", Value: "DELETE"},
+				{Key: "{{request.operation}}", Operator: Equals, Value: "DELETE"},
+				{Key: "{{request.operation}}", Operator: In, Value: 1.0},
+			},
+		},
+	}
+
+	errs := validation.ValidateDenyConditions()
+	assert.Equal(t, len(errs), 2)
+	assert.ErrorContains(t, errs[0], "conditions[0]: unsupported operator")
+	assert.ErrorContains(t, errs[1], "conditions[2]: operator In expects a list value")
+}
+
+func Test_ValidateCloneSource(t *testing.T) {
+	present := func(namespace, name, kind string) (bool, error) {
+		return namespace == "default" && name == "regcred" && kind == "Secret", nil
+	}
+	failing := func(namespace, name, kind string) (bool, error) {
+		return false, fmt.Errorf("connection refused")
+	}
+
+	testcases := []struct {
+		description string
+		generation  []byte
+		exists      func(namespace, name, kind string) (bool, error)
+		err         string
+	}{
+		{
+			description: "no clone",
+			generation:  []byte(`{"kind":"ConfigMap","name":"cm","data":{}}`),
+			exists:      present,
+		},
+		{
+			description: "missing namespace",
+			generation:  []byte(`{"kind":"Secret","name":"regcred","clone":{"name":"regcred"}}`),
+			err:         "clone.namespace cannot be empty",
+		},
+		{
+			description: "structural only",
+			generation:  []byte(`{"kind":"Secret","name":"regcred","clone":{"namespace":"default","name":"missing"}}`),
+		},
+		{
+			description: "source present",
+			generation:  []byte(`{"kind":"Secret","name":"regcred","clone":{"namespace":"default","name":"regcred"}}`),
+			exists:      present,
+		},
+		{
+			description: "source absent",
+			generation:  []byte(`{"kind":"Secret","name":"regcred","clone":{"namespace":"default","name":"missing"}}`),
+			exists:      present,
+			err:         "clone source Secret default/missing not found",
+		},
+		{
+			description: "templated source",
+			generation:  []byte(`{"kind":"Secret","name":"regcred","clone":{"namespace":"{{request.namespace}}","name":"missing"}}`),
+			exists:      present,
+		},
+		{
+			description: "lookup error",
+			generation:  []byte(`{"kind":"Secret","name":"regcred","clone":{"namespace":"default","name":"regcred"}}`),
+			exists:      failing,
+			err:         "failed to get clone source Secret default/regcred: connection refused",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var generation Generation
+		err := json.Unmarshal(testcase.generation, &generation)
+		assert.NilError(t, err, testcase.description)
+
+		err = generation.ValidateCloneSource(testcase.exists)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
+
+func Test_ValidateAllowedRuleTypes(t *testing.T) {
+	rawSpec := []byte(`{"rules":[
+		{"name":"add-label","mutate":{"patchStrategicMerge":{"metadata":{"labels":{"app":"nginx"}}}}},
+		{"name":"require-label","validate":{"pattern":{"metadata":{"labels":{"app":"?*"}}}}},
+		{"name":"default-quota","generate":{"kind":"ResourceQuota","name":"default","data":{"spec":{}}}}
+	]}`)
+
+	var policy ClusterPolicy
+	err := json.Unmarshal(rawSpec, &policy.Spec)
+	assert.NilError(t, err)
+
+	testcases := []struct {
+		allowed []string
+		err     string
+	}{
+		{allowed: []string{"mutate", "validate", "generate"}},
+		{allowed: []string{"validate", "generate"}, err: "rule add-label: mutate rules are not allowed"},
+		{allowed: []string{"mutate", "generate"}, err: "rule require-label: validate rules are not allowed"},
+		{allowed: []string{"mutate", "validate"}, err: "rule default-quota: generate rules are not allowed"},
+		{allowed: []string{"mutate", "validate", "audit"}, err: `unknown rule type "audit", expected one of mutate, validate, generate`},
+	}
+
+	for _, testcase := range testcases {
+		err := policy.ValidateAllowedRuleTypes(testcase.allowed)
+		if testcase.err == "" {
+			assert.NilError(t, err)
+		} else {
+			assert.Error(t, err, testcase.err)
+		}
+	}
+}
+
+func Test_ValidateExcludedSubjects(t *testing.T) {
+	required := []string{"system:serviceaccount:ci:deployer", "alice"}
+
+	testcases := []struct {
+		description string
+		spec        []byte
+		err         string
+	}{
+		{
+			description: "compliant exclude",
+			spec:        []byte(`{"rules":[{"name":"r","exclude":{"subjects":[{"kind":"ServiceAccount","name":"deployer","namespace":"kube-system"},{"kind":"Group","name":"system:masters"}]}}]}`),
+		},
+		{
+			description: "excluded service account",
+			spec:        []byte(`{"rules":[{"name":"ok"},{"name":"r","exclude":{"subjects":[{"kind":"ServiceAccount","name":"deployer","namespace":"ci"}]}}]}`),
+			err:         "rule r: exclude exempts the required subject system:serviceaccount:ci:deployer",
+		},
+		{
+			description: "policy level excluded user",
+			spec:        []byte(`{"exclude":{"subjects":[{"kind":"User","name":"alice"}]},"rules":[{"name":"r"}]}`),
+			err:         "spec.exclude exempts the required subject alice",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy ClusterPolicy
+		err := json.Unmarshal(testcase.spec, &policy.Spec)
+		assert.NilError(t, err, testcase.description)
+
+		err = policy.ValidateExcludedSubjects(required)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
+
+func Test_ValidateOverlayAnchors(t *testing.T) {
+	testcases := []struct {
+		description string
+		mutation    []byte
+		err         string
+	}{
+		{
+			description: "conditional anchor",
+			mutation:    []byte(`{"overlay":{"spec":{"containers":[{"(image)":"*:latest","imagePullPolicy":"Always"}]}}}`),
+		},
+		{
+			description: "equality and adding anchors",
+			mutation:    []byte(`{"patchStrategicMerge":{"metadata":{"annotations":{"+(team)":"dev"}},"spec":{"=(volumes)":[{"name":"v"}]}}}`),
+		},
+		{
+			description: "existence anchor",
+			mutation:    []byte(`{"overlay":{"spec":{"^(containers)":[{"name":"*"}]}}}`),
+			err:         "overlay.spec.^(containers): existence anchor on containers is not supported in mutate overlays",
+		},
+		{
+			description: "negation anchor",
+			mutation:    []byte(`{"patchStrategicMerge":{"spec":{"containers":[{"X(securityContext)":null}]}}}`),
+			err:         "patchStrategicMerge.spec.containers[0].X(securityContext): negation anchor on securityContext is not supported in mutate overlays",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var mutation Mutation
+		err := json.Unmarshal(testcase.mutation, &mutation)
+		assert.NilError(t, err, testcase.description)
+
+		err = mutation.ValidateOverlayAnchors()
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
+
+func Test_ValidateMatchExcludeSelectorEquality(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		expectError bool
+	}{
+		{
+			description: "identical selectors",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"app":"web"}}}},"exclude":{"resources":{"selector":{"matchExpressions":[{"key":"app","operator":"In","values":["web"]}]}}}}`),
+			expectError: true,
+		},
+		{
+			description: "overlapping selectors",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"app":"web"}}}},"exclude":{"resources":{"selector":{"matchLabels":{"app":"web","tier":"frontend"}}}}}`),
+		},
+		{
+			description: "disjoint selectors",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"app":"web"}}}},"exclude":{"resources":{"selector":{"matchLabels":{"app":"db"}}}}}`),
+		},
+		{
+			description: "identical selectors narrowed by namespace",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"app":"web"}}}},"exclude":{"resources":{"namespaces":["dev"],"selector":{"matchLabels":{"app":"web"}}}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		err = rule.ValidateMatchExcludeSelectorEquality()
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_ValidateLabelPreservation(t *testing.T) {
+	required := []string{"app.kubernetes.io/name", "team"}
+
+	testcases := []struct {
+		description string
+		mutation    []byte
+		err         string
+	}{
+		{
+			description: "additive merge",
+			mutation:    []byte(`{"patchStrategicMerge":{"metadata":{"labels":{"environment":"prod"}}}}`),
+		},
+		{
+			description: "replacement with the required labels",
+			mutation:    []byte(`{"overlay":{"metadata":{"labels":{"$patch":"replace","app.kubernetes.io/name":"web","team":"dev"}}}}`),
+		},
+		{
+			description: "strategic merge replacement missing a required label",
+			mutation:    []byte(`{"patchStrategicMerge":{"metadata":{"labels":{"$patch":"replace","team":"dev"}}}}`),
+			err:         "patchStrategicMerge.metadata.labels: labels are replaced without the required keys app.kubernetes.io/name",
+		},
+		{
+			description: "json patch replacement missing required labels",
+			mutation:    []byte(`{"patchesJson6902":"- op: add\n  path: /metadata/labels/environment\n  value: prod\n- op: replace\n  path: /metadata/labels\n  value:\n    environment: prod\n"}`),
+			err:         "patchesJson6902[1]: labels are replaced without the required keys app.kubernetes.io/name, team",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var mutation Mutation
+		err := json.Unmarshal(testcase.mutation, &mutation)
+		assert.NilError(t, err, testcase.description)
+
+		err = mutation.ValidateLabelPreservation(required)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
+
+func Test_ValidateMatchDoesNotDependOnMutation(t *testing.T) {
+	testcases := []struct {
+		description string
+		spec        []byte
+		err         string
+	}{
+		{
+			description: "rule matching a label added by an earlier rule",
+			spec:        []byte(`{"rules":[{"name":"add-tier","match":{"resources":{"kinds":["Pod"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"+(tier)":"backend"}}}}},{"name":"check-backend","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"tier":"backend"}}}},"validate":{"deny":{}}}]}`),
+			err:         "rule check-backend matches the label tier written by the earlier mutate rule add-tier, which requires the mutation to be applied first",
+		},
+		{
+			description: "rule matching an annotation added by a JSON patch",
+			spec:        []byte(`{"rules":[{"name":"annotate","match":{"resources":{"kinds":["Pod"]}},"mutate":{"patchesJson6902":"- op: add\n  path: /metadata/annotations/example.com~1owner\n  value: team-a\n"}},{"name":"check-owner","match":{"resources":{"kinds":["Pod"],"annotations":{"example.com/owner":"team-a"}}},"validate":{"deny":{}}}]}`),
+			err:         "rule check-owner matches the annotation example.com/owner written by the earlier mutate rule annotate, which requires the mutation to be applied first",
+		},
+		{
+			description: "independent rules",
+			spec:        []byte(`{"rules":[{"name":"add-tier","match":{"resources":{"kinds":["Pod"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"(app)":"web","tier":"backend"}}}}},{"name":"check-web","match":{"resources":{"kinds":["Pod"],"selector":{"matchExpressions":[{"key":"app","operator":"In","values":["web"]}]}}},"validate":{"deny":{}}}]}`),
+		},
+		{
+			description: "mutate rule executed later",
+			spec:        []byte(`{"rules":[{"name":"check-backend","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"tier":"backend"}}}},"validate":{"deny":{}}},{"name":"add-tier","match":{"resources":{"kinds":["Pod"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"tier":"backend"}}}}}]}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy ClusterPolicy
+		err := json.Unmarshal(testcase.spec, &policy.Spec)
+		assert.NilError(t, err, testcase.description)
+
+		err = policy.ValidateMatchDoesNotDependOnMutation()
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
+
+func Test_ValidateAPIVersionConsistency(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		err         string
+	}{
+		{
+			description: "consistent apiVersions",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Deployment"]}},"mutate":{"patchStrategicMerge":{"apiVersion":"apps/v1","spec":{"replicas":2}}}}`),
+		},
+		{
+			description: "different kinds",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Namespace"]}},"generate":{"apiVersion":"networking.k8s.io/v1","kind":"NetworkPolicy","name":"deny","namespace":"{{request.object.metadata.name}}","data":{"apiVersion":"networking.k8s.io/v1","spec":{}}}}`),
+		},
+		{
+			description: "inconsistent apiVersions",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Deployment"]}},"validate":{"pattern":{"apiVersion":"apps/v1","spec":{"replicas":">1"}}},"generate":{"apiVersion":"extensions/v1beta1","kind":"Deployment","name":"d","namespace":"default","data":{}}}`),
+			err:         "rule r uses different apiVersions for Deployment: apps/v1 in validate.pattern, extensions/v1beta1 in generate",
+		},
+		{
+			description: "wildcard apiVersion",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Deployment"]}},"validate":{"anyPattern":[{"apiVersion":"apps/*"},{"apiVersion":"apps/v1","spec":{"replicas":1}}]}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		err = rule.ValidateAPIVersionConsistency()
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
+
+func Test_ValidateIdempotentListMerge(t *testing.T) {
+	testcases := []struct {
+		description string
+		mutation    []byte
+		err         string
+	}{
+		{
+			description: "keyed merge",
+			mutation:    []byte(`{"patchStrategicMerge":{"spec":{"containers":[{"name":"sidecar","image":"envoy","volumeMounts":[{"mountPath":"/etc/envoy","name":"config"}]}]}}}`),
+		},
+		{
+			description: "condition only entry",
+			mutation:    []byte(`{"overlay":{"spec":{"containers":[{"(image)":"*:latest"}],"imagePullSecrets":[{"name":"regcred"}]}}}`),
+		},
+		{
+			description: "keyless append",
+			mutation:    []byte(`{"overlay":{"spec":{"containers":[{"name":"app","env":[{"value":"debug"}]}]}}}`),
+			err:         "overlay.spec.containers[0].env[0]: entry has none of the fields containerPort, devicePath, ip, mountPath, name and is appended again each time the resource is updated",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var mutation Mutation
+		err := json.Unmarshal(testcase.mutation, &mutation)
+		assert.NilError(t, err, testcase.description)
+
+		err = mutation.ValidateIdempotentListMerge()
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
+
+func Test_ValidateNonRedundant(t *testing.T) {
+	defaults := map[string]interface{}{
+		"spec.containers.imagePullPolicy": "IfNotPresent",
+		"spec.restartPolicy":              "Always",
+	}
+
+	testcases := []struct {
+		description string
+		mutation    []byte
+		err         string
+	}{
+		{
+			description: "redundant mutation",
+			mutation:    []byte(`{"patchStrategicMerge":{"spec":{"containers":[{"(image)":"*:v*","imagePullPolicy":"IfNotPresent"}]}}}`),
+			err:         "patchStrategicMerge.spec.containers[0].imagePullPolicy: IfNotPresent is the default value of spec.containers.imagePullPolicy, setting it has no effect",
+		},
+		{
+			description: "redundant add anchor",
+			mutation:    []byte(`{"overlay":{"spec":{"+(restartPolicy)":"Always"}}}`),
+			err:         "overlay.spec.+(restartPolicy): Always is the default value of spec.restartPolicy, setting it has no effect",
+		},
+		{
+			description: "meaningful mutation",
+			mutation:    []byte(`{"patchStrategicMerge":{"spec":{"containers":[{"(image)":"*:latest","imagePullPolicy":"Always"}],"restartPolicy":"OnFailure"}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var mutation Mutation
+		err := json.Unmarshal(testcase.mutation, &mutation)
+		assert.NilError(t, err, testcase.description)
+
+		err = mutation.ValidateNonRedundant(defaults)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+
+		assert.NilError(t, mutation.ValidateNonRedundant(nil), testcase.description)
+	}
+}
+
+func Test_ValidateNameConvention(t *testing.T) {
+	testcases := []struct {
+		name    string
+		pattern string
+		err     string
+	}{
+		{name: "security-require-labels", pattern: "security-*"},
+		{name: "cost-limits", pattern: "regex:^(security|cost)-[a-z-]+$"},
+		{name: "require-labels", pattern: "security-*", err: "policy name require-labels does not match the naming convention security-*"},
+		{name: "cost-", pattern: "regex:^(security|cost)-[a-z-]+$", err: "policy name cost- does not match the naming convention regex:^(security|cost)-[a-z-]+$"},
+		{name: "security-labels", pattern: "regex:(", err: "invalid naming convention regex:(: error parsing regexp: missing closing ): `(`"},
+	}
+
+	for _, testcase := range testcases {
+		var policy ClusterPolicy
+		policy.Name = testcase.name
+
+		err := policy.ValidateNameConvention(testcase.pattern)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.name)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.name)
+		}
+	}
+}
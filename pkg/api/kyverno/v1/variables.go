@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var regexVariables = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+
+// ReferencedVariables returns a sorted list of the variables referenced in the
+// policy rules: messages, patterns, overlays, patches, generate data, preconditions
+// and context entries. Variables are returned without the surrounding braces.
+func (p *ClusterPolicy) ReferencedVariables() []string {
+	found := make(map[string]bool)
+	for _, rule := range p.Spec.Rules {
+		for _, entry := range rule.Context {
+			if entry.ConfigMap != nil {
+				collectVariables(entry.ConfigMap.Name, found)
+				collectVariables(entry.ConfigMap.Namespace, found)
+			}
+			if entry.APICall != nil {
+				collectVariables(entry.APICall.URLPath, found)
+				collectVariables(entry.APICall.JMESPath, found)
+			}
+		}
+
+		for _, condition := range rule.Conditions {
+			collectVariables(condition.Key, found)
+			collectVariables(condition.Value, found)
+		}
+
+		collectVariables(rule.Mutation.Overlay, found)
+		collectVariables(rule.Mutation.PatchStrategicMerge, found)
+		collectVariables(rule.Mutation.PatchesJSON6902, found)
+		for _, patch := range rule.Mutation.Patches {
+			collectVariables(patch.Path, found)
+			collectVariables(patch.Value, found)
+		}
+
+		collectVariables(rule.Validation.Message, found)
+		collectVariables(rule.Validation.Pattern, found)
+		collectVariables(rule.Validation.AnyPattern, found)
+		if rule.Validation.Deny != nil {
+			for _, condition := range rule.Validation.Deny.Conditions {
+				collectVariables(condition.Key, found)
+				collectVariables(condition.Value, found)
+			}
+		}
+
+		for _, generation := range rule.GenerateTargets() {
+			collectVariables(generation.Kind, found)
+			collectVariables(generation.Name, found)
+			collectVariables(generation.Namespace, found)
+			collectVariables(generation.Data, found)
+			collectVariables(generation.Clone.Name, found)
+			collectVariables(generation.Clone.Namespace, found)
+		}
+	}
+
+	vars := make([]string, 0, len(found))
+	for v := range found {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// collectVariables walks the element and adds all variables found in
+// string values and map keys to the set
+func collectVariables(element interface{}, found map[string]bool) {
+	switch typed := element.(type) {
+	case map[string]interface{}:
+		for k, v := range typed {
+			collectVariables(k, found)
+			collectVariables(v, found)
+		}
+	case []interface{}:
+		for _, v := range typed {
+			collectVariables(v, found)
+		}
+	case string:
+		for _, v := range regexVariables.FindAllString(typed, -1) {
+			v = strings.TrimSpace(v[2 : len(v)-2])
+			if v != "" {
+				found[v] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_ReferencedVariables(t *testing.T) {
+	rawPolicy := []byte(`
+	{
+		"spec": {
+		   "rules": [
+			  {
+				 "name": "add-owner",
+				 "context": [
+					{
+					   "name": "dictionary",
+					   "configMap": {
+						  "name": "{{ request.object.metadata.name }}-cm",
+						  "namespace": "default"
+					   }
+					}
+				 ],
+				 "preconditions": [
+					{
+					   "key": "{{request.operation}}",
+					   "operator": "Equals",
+					   "value": "CREATE"
+					}
+				 ],
+				 "match": {
+					"resources": {
+					   "kinds": ["Pod"]
+					}
+				 },
+				 "mutate": {
+					"overlay": {
+					   "metadata": {
+						  "labels": {
+							 "owner": "{{request.userInfo.username}}"
+						  }
+					   }
+					}
+				 }
+			  },
+			  {
+				 "name": "check-owner",
+				 "match": {
+					"resources": {
+					   "kinds": ["Pod"]
+					}
+				 },
+				 "validate": {
+					"message": "owner {{request.userInfo.username}} is not allowed in {{request.namespace}}",
+					"pattern": {
+					   "metadata": {
+						  "labels": {
+							 "team": "{{dictionary.data.team}}"
+						  }
+					   }
+					}
+				 }
+			  },
+			  {
+				 "name": "generate-cm",
+				 "match": {
+					"resources": {
+					   "kinds": ["Namespace"]
+					}
+				 },
+				 "generate": {
+					"kind": "ConfigMap",
+					"name": "zk-kafka-address",
+					"namespace": "{{request.object.metadata.name}}",
+					"data": {
+					   "data": {
+						  "owner": "{{request.userInfo.username}}"
+					   }
+					}
+				 }
+			  },
+			  {
+				 "name": "generate-limits",
+				 "match": {
+					"resources": {
+					   "kinds": ["Namespace"]
+					}
+				 },
+				 "generations": [
+					{
+					   "kind": "LimitRange",
+					   "name": "{{request.object.metadata.labels.team}}-limits",
+					   "namespace": "{{request.object.metadata.name}}",
+					   "data": {}
+					}
+				 ]
+			  }
+		   ]
+		}
+	}`)
+
+	var policy ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	expected := []string{
+		"dictionary.data.team",
+		"request.namespace",
+		"request.object.metadata.labels.team",
+		"request.object.metadata.name",
+		"request.operation",
+		"request.userInfo.username",
+	}
+	assert.DeepEqual(t, policy.ReferencedVariables(), expected)
+}
+
+func Test_ReferencedVariables_NoVariables(t *testing.T) {
+	rawPolicy := []byte(`{"spec":{"rules":[{"name":"check-label","match":{"resources":{"kinds":["Pod"]}},"validate":{"message":"label app is required","pattern":{"metadata":{"labels":{"app":"?*"}}}}}]}}`)
+
+	var policy ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(policy.ReferencedVariables()), 0)
+}
@@ -95,12 +95,12 @@ func AddCloneLabel(client *dclient.Client, pInformer kyvernoinformer.ClusterPoli
 
 	for _, policy := range policies {
 		for _, rule := range policy.Spec.Rules {
-			if rule.HasGenerate() {
-				clone := rule.Generation.Clone
+			for _, generation := range rule.GenerateTargets() {
+				clone := generation.Clone
 				if clone.Name != "" {
 					namespace := clone.Namespace
 					name := clone.Name
-					kind := rule.Generation.Kind
+					kind := generation.Kind
 
 					obj, err := client.GetResource("", kind, namespace, name)
 					if err != nil {
@@ -77,3 +77,29 @@ func RemoveAnchor(key string) (string, string) {
 func AddAnchor(key, anchorPrefix string) string {
 	return anchorPrefix + key + ")"
 }
+
+// StripAnchors returns a copy of the pattern with the anchors removed from all map keys.
+// If a field is set both with and without an anchor, the value without the anchor is kept.
+func StripAnchors(pattern interface{}) interface{} {
+	switch typed := pattern.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, value := range typed {
+			field, prefix := RemoveAnchor(key)
+			if _, ok := result[field]; ok && prefix != "" {
+				continue
+			}
+
+			result[field] = StripAnchors(value)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, value := range typed {
+			result[i] = StripAnchors(value)
+		}
+		return result
+	default:
+		return typed
+	}
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"encoding/json"
 	"testing"
 
 	"gotest.tools/assert"
@@ -56,3 +57,54 @@ func TestIsExistenceAnchor_OnlyHat(t *testing.T) {
 func TestIsExistenceAnchor_ConditionAnchor(t *testing.T) {
 	assert.Assert(t, !IsExistenceAnchor("(abc)"))
 }
+
+func TestStripAnchors(t *testing.T) {
+	testcases := []struct {
+		description string
+		pattern     string
+		expected    string
+	}{
+		{
+			description: "condition anchor",
+			pattern:     `{"spec":{"containers":[{"(name)":"nginx","imagePullPolicy":"Always"}]}}`,
+			expected:    `{"spec":{"containers":[{"name":"nginx","imagePullPolicy":"Always"}]}}`,
+		},
+		{
+			description: "existence anchor",
+			pattern:     `{"spec":{"^(containers)":[{"image":"nginx"}]}}`,
+			expected:    `{"spec":{"containers":[{"image":"nginx"}]}}`,
+		},
+		{
+			description: "equality anchor",
+			pattern:     `{"spec":{"=(hostNetwork)":false}}`,
+			expected:    `{"spec":{"hostNetwork":false}}`,
+		},
+		{
+			description: "negation anchor",
+			pattern:     `{"spec":{"volumes":[{"X(hostPath)":null}]}}`,
+			expected:    `{"spec":{"volumes":[{"hostPath":null}]}}`,
+		},
+		{
+			description: "adding anchor",
+			pattern:     `{"metadata":{"labels":{"+(app)":"nginx"}}}`,
+			expected:    `{"metadata":{"labels":{"app":"nginx"}}}`,
+		},
+		{
+			description: "field with and without anchor",
+			pattern:     `{"spec":{"(replicas)":">1","replicas":3}}`,
+			expected:    `{"spec":{"replicas":3}}`,
+		},
+		{
+			description: "no anchors",
+			pattern:     `{"spec":{"replicas":3,"template":{"metadata":{"labels":{"app":"nginx"}}}}}`,
+			expected:    `{"spec":{"replicas":3,"template":{"metadata":{"labels":{"app":"nginx"}}}}}`,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var pattern, expected interface{}
+		assert.NilError(t, json.Unmarshal([]byte(testcase.pattern), &pattern), testcase.description)
+		assert.NilError(t, json.Unmarshal([]byte(testcase.expected), &expected), testcase.description)
+		assert.DeepEqual(t, StripAnchors(pattern), expected)
+	}
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var regexMatchGeneration = regexp.MustCompile(`^\s*(\S+)\s*(==|!=|>=|<=|>|<)\s*(\S+)\s*$`)
+
+// GenerationComparison is a parsed matchGeneration expression, comparing a generation
+// field of the resource with an integer
+type GenerationComparison struct {
+	// Field is the path of the compared field, e.g. metadata.generation
+	Field string
+
+	Operator string
+
+	Value int64
+}
+
+// ParseMatchGeneration parses a matchGeneration expression such as metadata.generation > 1
+func ParseMatchGeneration(expression string) (GenerationComparison, error) {
+	groups := regexMatchGeneration.FindStringSubmatch(expression)
+	if groups == nil {
+		return GenerationComparison{}, fmt.Errorf("invalid matchGeneration %q: expected a comparison such as metadata.generation > 1", expression)
+	}
+
+	value, err := strconv.ParseInt(groups[3], 10, 64)
+	if err != nil {
+		return GenerationComparison{}, fmt.Errorf("invalid matchGeneration %q: %s is not an integer", expression, groups[3])
+	}
+
+	return GenerationComparison{Field: groups[1], Operator: groups[2], Value: value}, nil
+}
+
+// Matches compares the generation field of the resource, a missing field is 0
+func (g GenerationComparison) Matches(resource unstructured.Unstructured) bool {
+	generation, _, _ := unstructured.NestedInt64(resource.Object, strings.Split(g.Field, ".")...)
+	switch g.Operator {
+	case "==":
+		return generation == g.Value
+	case "!=":
+		return generation != g.Value
+	case ">=":
+		return generation >= g.Value
+	case "<=":
+		return generation <= g.Value
+	case ">":
+		return generation > g.Value
+	case "<":
+		return generation < g.Value
+	}
+
+	return false
+}
@@ -43,6 +43,10 @@ func ProcessPatches(log logr.Logger, ruleName string, mutation kyverno.Mutation,
 	var errs []error
 	var patches [][]byte
 	for _, patch := range mutation.Patches {
+		// allowMissing is not part of the JSON patch sent to the API server
+		allowMissing := patch.AllowMissing
+		patch.AllowMissing = false
+
 		// JSON patch
 		patchRaw, err := json.Marshal(patch)
 		if err != nil {
@@ -52,6 +56,10 @@ func ProcessPatches(log logr.Logger, ruleName string, mutation kyverno.Mutation,
 		}
 		patchResource, err := applyPatch(resourceRaw, patchRaw)
 		// TODO: continue on error if one of the patches fails, will add the failure event in such case
+		if err != nil && allowMissing {
+			logger.V(4).Info("skipped remove patch of an absent path", "path", patch.Path)
+			continue
+		}
 		if err != nil && patch.Operation == "remove" {
 			log.Error(err, "failed to process JSON path or patch is a 'remove' operation")
 			continue
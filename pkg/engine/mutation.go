@@ -47,7 +47,7 @@ func Mutate(policyContext *PolicyContext) (resp *response.EngineResponse) {
 	policyContext.JSONContext.Checkpoint()
 	defer policyContext.JSONContext.Restore()
 
-	for _, rule := range policy.Spec.Rules {
+	for _, rule := range policy.RulesInPriorityOrder() {
 		var ruleResponse response.RuleResponse
 		logger := logger.WithValues("rule", rule.Name)
 		if !rule.HasMutate() {
@@ -62,7 +62,7 @@ func Mutate(policyContext *PolicyContext) (resp *response.EngineResponse) {
 			excludeResource = policyContext.ExcludeGroupRole
 		}
 
-		if err := MatchesResourceDescription(patchedResource, rule, policyContext.AdmissionInfo, excludeResource, policyContext.NamespaceLabels); err != nil {
+		if err := MatchesResourceDescription(patchedResource, rule, policyContext.AdmissionInfo, policyContext.Operation, excludeResource, policyContext.NamespaceLabels); err != nil {
 			logger.V(4).Info("rule not matched", "reason", err.Error())
 			continue
 		}
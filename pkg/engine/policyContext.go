@@ -23,6 +23,10 @@ type PolicyContext struct {
 	// AdmissionInfo contains the admission request information
 	AdmissionInfo kyverno.RequestInfo
 
+	// Operation is the admission operation of the request, e.g. CREATE, or empty
+	// when the policy is applied in background
+	Operation string
+
 	// Dynamic client - used by generate
 	Client *client.Client
 
@@ -27,7 +27,8 @@ type EngineStats struct {
 	RulesAppliedCount int
 }
 
-func checkKind(kinds []string, resourceKind string) bool {
+// CheckKind checks if the resource kind is one of the kinds
+func CheckKind(kinds []string, resourceKind string) bool {
 	for _, kind := range kinds {
 		if resourceKind == kind {
 			return true
@@ -37,11 +38,14 @@ func checkKind(kinds []string, resourceKind string) bool {
 	return false
 }
 
-func checkName(name, resourceName string) bool {
+// CheckName checks if the resource name matches the name, which may contain wildcards
+func CheckName(name, resourceName string) bool {
 	return wildcard.Match(name, resourceName)
 }
 
-func checkNameSpace(namespaces []string, resource unstructured.Unstructured) bool {
+// CheckNameSpace checks if the namespace of the resource, or its name for a Namespace,
+// matches one of the namespaces, which may contain wildcards
+func CheckNameSpace(namespaces []string, resource unstructured.Unstructured) bool {
 	resourceNameSpace := resource.GetNamespace()
 	if resource.GetKind() == "Namespace" {
 		resourceNameSpace = resource.GetName()
@@ -56,7 +60,9 @@ func checkNameSpace(namespaces []string, resource unstructured.Unstructured) boo
 	return false
 }
 
-func checkAnnotations(annotations map[string]string, resourceAnnotations map[string]string) bool {
+// CheckAnnotations checks if each of the annotations, whose keys and values may contain
+// wildcards, matches an annotation of the resource
+func CheckAnnotations(annotations map[string]string, resourceAnnotations map[string]string) bool {
 	if len(annotations) == 0 {
 		return true
 	}
@@ -100,6 +106,7 @@ func checkSelector(labelSelector *metav1.LabelSelector, resourceLabels map[strin
 // 		Name       string
 // 		Namespaces []string
 // 		Selector
+// 		MatchGeneration
 // UserInfo:
 // 		Roles        []string
 // 		ClusterRoles []string
@@ -112,25 +119,25 @@ func doesResourceMatchConditionBlock(conditionBlock kyverno.ResourceDescription,
 	var errs []error
 
 	if len(conditionBlock.Kinds) > 0 {
-		if !checkKind(conditionBlock.Kinds, resource.GetKind()) {
+		if !CheckKind(conditionBlock.Kinds, resource.GetKind()) {
 			errs = append(errs, fmt.Errorf("kind does not match %v", conditionBlock.Kinds))
 		}
 	}
 
 	if conditionBlock.Name != "" {
-		if !checkName(conditionBlock.Name, resource.GetName()) {
+		if !CheckName(conditionBlock.Name, resource.GetName()) {
 			errs = append(errs, fmt.Errorf("name does not match"))
 		}
 	}
 
 	if len(conditionBlock.Namespaces) > 0 {
-		if !checkNameSpace(conditionBlock.Namespaces, resource) {
+		if !CheckNameSpace(conditionBlock.Namespaces, resource) {
 			errs = append(errs, fmt.Errorf("namespace does not match"))
 		}
 	}
 
 	if len(conditionBlock.Annotations) > 0 {
-		if !checkAnnotations(conditionBlock.Annotations, resource.GetAnnotations()) {
+		if !CheckAnnotations(conditionBlock.Annotations, resource.GetAnnotations()) {
 			errs = append(errs, fmt.Errorf("annotations does not match"))
 		}
 	}
@@ -146,6 +153,15 @@ func doesResourceMatchConditionBlock(conditionBlock kyverno.ResourceDescription,
 		}
 	}
 
+	if conditionBlock.MatchGeneration != "" {
+		comparison, err := ParseMatchGeneration(conditionBlock.MatchGeneration)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse matchGeneration: %v", err))
+		} else if !comparison.Matches(resource) {
+			errs = append(errs, fmt.Errorf("generation does not match"))
+		}
+	}
+
 	if conditionBlock.NamespaceSelector != nil && resource.GetKind() != "Namespace" && resource.GetKind() != "" {
 		hasPassed, err := checkSelector(conditionBlock.NamespaceSelector, namespaceLabels)
 		if err != nil {
@@ -197,6 +213,32 @@ func doesResourceMatchConditionBlock(conditionBlock kyverno.ResourceDescription,
 	return append(errs, userInfoErrors...)
 }
 
+// doesResourceMatchAnyAllConditionBlocks filters the resource with the any and all condition
+// blocks of a match / exclude block: one of the any blocks, if set, and every all block must
+// match the resource. The user info of the match / exclude block applies to each block.
+func doesResourceMatchAnyAllConditionBlocks(anyBlocks, allBlocks []kyverno.ResourceDescription, userInfo kyverno.UserInfo, admissionInfo kyverno.RequestInfo, resource unstructured.Unstructured, dynamicConfig []string, namespaceLabels map[string]string) []error {
+	var errs []error
+	for i, conditionBlock := range anyBlocks {
+		blockErrs := doesResourceMatchConditionBlock(conditionBlock, userInfo, admissionInfo, resource, dynamicConfig, namespaceLabels)
+		if len(blockErrs) == 0 {
+			errs = nil
+			break
+		}
+
+		for _, err := range blockErrs {
+			errs = append(errs, fmt.Errorf("any[%d]: %v", i, err))
+		}
+	}
+
+	for i, conditionBlock := range allBlocks {
+		for _, err := range doesResourceMatchConditionBlock(conditionBlock, userInfo, admissionInfo, resource, dynamicConfig, namespaceLabels) {
+			errs = append(errs, fmt.Errorf("all[%d]: %v", i, err))
+		}
+	}
+
+	return errs
+}
+
 // matchSubjects return true if one of ruleSubjects exist in userInfo
 func matchSubjects(ruleSubjects []rbacv1.Subject, userInfo authenticationv1.UserInfo, dynamicConfig []string) bool {
 	const SaPrefix = "system:serviceaccount:"
@@ -230,8 +272,9 @@ func matchSubjects(ruleSubjects []rbacv1.Subject, userInfo authenticationv1.User
 	return false
 }
 
-//MatchesResourceDescription checks if the resource matches resource description of the rule or not
-func MatchesResourceDescription(resourceRef unstructured.Unstructured, ruleRef kyverno.Rule, admissionInfoRef kyverno.RequestInfo, dynamicConfig []string, namespaceLabels map[string]string) error {
+//MatchesResourceDescription checks if the resource matches resource description of the rule or not.
+// The operations of the match block are only checked if the admission operation is set.
+func MatchesResourceDescription(resourceRef unstructured.Unstructured, ruleRef kyverno.Rule, admissionInfoRef kyverno.RequestInfo, operation string, dynamicConfig []string, namespaceLabels map[string]string) error {
 
 	rule := *ruleRef.DeepCopy()
 	resource := *resourceRef.DeepCopy()
@@ -244,7 +287,10 @@ func MatchesResourceDescription(resourceRef unstructured.Unstructured, ruleRef k
 	}
 
 	// checking if resource matches the rule
-	if !reflect.DeepEqual(rule.MatchResources.ResourceDescription, kyverno.ResourceDescription{}) ||
+	if len(rule.MatchResources.Any) > 0 || len(rule.MatchResources.All) > 0 {
+		matchErrs := doesResourceMatchAnyAllConditionBlocks(rule.MatchResources.Any, rule.MatchResources.All, rule.MatchResources.UserInfo, admissionInfo, resource, dynamicConfig, namespaceLabels)
+		reasonsForFailure = append(reasonsForFailure, matchErrs...)
+	} else if !reflect.DeepEqual(rule.MatchResources.ResourceDescription, kyverno.ResourceDescription{}) ||
 		!reflect.DeepEqual(rule.MatchResources.UserInfo, kyverno.UserInfo{}) {
 		matchErrs := doesResourceMatchConditionBlock(rule.MatchResources.ResourceDescription, rule.MatchResources.UserInfo, admissionInfo, resource, dynamicConfig, namespaceLabels)
 		reasonsForFailure = append(reasonsForFailure, matchErrs...)
@@ -252,8 +298,17 @@ func MatchesResourceDescription(resourceRef unstructured.Unstructured, ruleRef k
 		reasonsForFailure = append(reasonsForFailure, fmt.Errorf("match cannot be empty"))
 	}
 
+	if operation != "" && len(rule.MatchResources.Operations) > 0 && !utils.ContainsString(rule.MatchResources.Operations, operation) {
+		reasonsForFailure = append(reasonsForFailure, fmt.Errorf("operation %s does not match %v", operation, rule.MatchResources.Operations))
+	}
+
 	// checking if resource has been excluded
-	if !reflect.DeepEqual(rule.ExcludeResources.ResourceDescription, kyverno.ResourceDescription{}) ||
+	if len(rule.ExcludeResources.Any) > 0 || len(rule.ExcludeResources.All) > 0 {
+		excludeErrs := doesResourceMatchAnyAllConditionBlocks(rule.ExcludeResources.Any, rule.ExcludeResources.All, rule.ExcludeResources.UserInfo, admissionInfo, resource, dynamicConfig, namespaceLabels)
+		if len(excludeErrs) == 0 {
+			reasonsForFailure = append(reasonsForFailure, fmt.Errorf("resource excluded"))
+		}
+	} else if !reflect.DeepEqual(rule.ExcludeResources.ResourceDescription, kyverno.ResourceDescription{}) ||
 		!reflect.DeepEqual(rule.ExcludeResources.UserInfo, kyverno.UserInfo{}) {
 		excludeErrs := doesResourceMatchConditionBlock(rule.ExcludeResources.ResourceDescription, rule.ExcludeResources.UserInfo, admissionInfo, resource, dynamicConfig, namespaceLabels)
 		if excludeErrs == nil {
@@ -59,6 +59,60 @@ func TestMatchesResourceDescription(t *testing.T) {
 			Policy:            []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"hello-world-policy"},"spec":{"background":false,"rules":[{"name":"hello-world-policy","match":{"resources":{"kinds":["Pod"]}},"exclude":{"resources":{"name":"hello-world"},"clusterRoles":["system:node"]},"mutate":{"overlay":{"spec":{"containers":[{"(image)":"*","imagePullPolicy":"IfNotPresent"}]}}}}]}}`),
 			areErrorsExpected: false,
 		},
+		{
+			Description:       "Should match pod since one of the any descriptions matches it",
+			Resource:          []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"hello-world","labels":{"name":"hello-world"}},"spec":{"containers":[{"name":"hello-world","image":"hello-world"}]}}`),
+			Policy:            []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"hello-world-policy"},"spec":{"background":false,"rules":[{"name":"hello-world-policy","match":{"any":[{"kinds":["Service"]},{"kinds":["Pod"]}]},"validate":{"deny":{}}}]}}`),
+			areErrorsExpected: false,
+		},
+		{
+			Description:       "Should fail since none of the any descriptions matches the pod",
+			Resource:          []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"hello-world","labels":{"name":"hello-world"}},"spec":{"containers":[{"name":"hello-world","image":"hello-world"}]}}`),
+			Policy:            []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"hello-world-policy"},"spec":{"background":false,"rules":[{"name":"hello-world-policy","match":{"any":[{"kinds":["Service"]},{"kinds":["Pod"],"name":"other"}]},"validate":{"deny":{}}}]}}`),
+			areErrorsExpected: true,
+		},
+		{
+			Description:       "Should match pod since all of the all descriptions match it",
+			Resource:          []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"hello-world","labels":{"name":"hello-world"}},"spec":{"containers":[{"name":"hello-world","image":"hello-world"}]}}`),
+			Policy:            []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"hello-world-policy"},"spec":{"background":false,"rules":[{"name":"hello-world-policy","match":{"all":[{"kinds":["Pod"]},{"name":"hello-*"}]},"validate":{"deny":{}}}]}}`),
+			areErrorsExpected: false,
+		},
+		{
+			Description:       "Should fail since one of the all descriptions does not match the pod",
+			Resource:          []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"hello-world","labels":{"name":"hello-world"}},"spec":{"containers":[{"name":"hello-world","image":"hello-world"}]}}`),
+			Policy:            []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"hello-world-policy"},"spec":{"background":false,"rules":[{"name":"hello-world-policy","match":{"all":[{"kinds":["Pod"]},{"name":"other"}]},"validate":{"deny":{}}}]}}`),
+			areErrorsExpected: true,
+		},
+		{
+			Description:       "Should fail since the any descriptions match the pod but not the all descriptions",
+			Resource:          []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"hello-world","labels":{"name":"hello-world"}},"spec":{"containers":[{"name":"hello-world","image":"hello-world"}]}}`),
+			Policy:            []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"hello-world-policy"},"spec":{"background":false,"rules":[{"name":"hello-world-policy","match":{"any":[{"kinds":["Pod"]}],"all":[{"name":"other"}]},"validate":{"deny":{}}}]}}`),
+			areErrorsExpected: true,
+		},
+		{
+			Description:       "Should exclude pod since one of the exclude any descriptions matches it",
+			Resource:          []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"hello-world","labels":{"name":"hello-world"}},"spec":{"containers":[{"name":"hello-world","image":"hello-world"}]}}`),
+			Policy:            []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"hello-world-policy"},"spec":{"background":false,"rules":[{"name":"hello-world-policy","match":{"resources":{"kinds":["Pod"]}},"exclude":{"any":[{"namespaces":["kube-system"]},{"name":"hello-*"}]},"validate":{"deny":{}}}]}}`),
+			areErrorsExpected: true,
+		},
+		{
+			Description:       "Should not exclude pod since none of the exclude any descriptions matches it",
+			Resource:          []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"hello-world","labels":{"name":"hello-world"}},"spec":{"containers":[{"name":"hello-world","image":"hello-world"}]}}`),
+			Policy:            []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"hello-world-policy"},"spec":{"background":false,"rules":[{"name":"hello-world-policy","match":{"resources":{"kinds":["Pod"]}},"exclude":{"any":[{"namespaces":["kube-system"]},{"name":"other"}]},"validate":{"deny":{}}}]}}`),
+			areErrorsExpected: false,
+		},
+		{
+			Description:       "Should exclude pod since all of the exclude all descriptions match it",
+			Resource:          []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"hello-world","labels":{"name":"hello-world"}},"spec":{"containers":[{"name":"hello-world","image":"hello-world"}]}}`),
+			Policy:            []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"hello-world-policy"},"spec":{"background":false,"rules":[{"name":"hello-world-policy","match":{"resources":{"kinds":["Pod"]}},"exclude":{"all":[{"kinds":["Pod"]},{"name":"hello-*"}]},"validate":{"deny":{}}}]}}`),
+			areErrorsExpected: true,
+		},
+		{
+			Description:       "Should not exclude pod since one of the exclude all descriptions does not match it",
+			Resource:          []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"hello-world","labels":{"name":"hello-world"}},"spec":{"containers":[{"name":"hello-world","image":"hello-world"}]}}`),
+			Policy:            []byte(`{"apiVersion":"kyverno.io/v1","kind":"ClusterPolicy","metadata":{"name":"hello-world-policy"},"spec":{"background":false,"rules":[{"name":"hello-world-policy","match":{"resources":{"kinds":["Pod"]}},"exclude":{"all":[{"kinds":["Pod"]},{"name":"other"}]},"validate":{"deny":{}}}]}}`),
+			areErrorsExpected: false,
+		},
 	}
 
 	for i, tc := range tcs {
@@ -70,7 +124,7 @@ func TestMatchesResourceDescription(t *testing.T) {
 		resource, _ := utils.ConvertToUnstructured(tc.Resource)
 
 		for _, rule := range policy.Spec.Rules {
-			err := MatchesResourceDescription(*resource, rule, tc.AdmissionInfo, []string{}, nil)
+			err := MatchesResourceDescription(*resource, rule, tc.AdmissionInfo, "", []string{}, nil)
 			if err != nil {
 				if !tc.areErrorsExpected {
 					t.Errorf("Testcase %d Unexpected error: %v", i+1, err)
@@ -84,6 +138,67 @@ func TestMatchesResourceDescription(t *testing.T) {
 	}
 }
 
+func TestMatchesResourceDescription_Operations(t *testing.T) {
+	rawRule := []byte(`{"name":"check-pods","match":{"resources":{"kinds":["Pod"]},"operations":["CREATE"]},"validate":{"deny":{}}}`)
+	rawResource := []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"hello-world"}}`)
+
+	var rule kyverno.Rule
+	if err := json.Unmarshal(rawRule, &rule); err != nil {
+		t.Fatalf("invalid rule raw: %v", err)
+	}
+
+	resource, err := utils.ConvertToUnstructured(rawResource)
+	if err != nil {
+		t.Fatalf("unable to convert raw resource to unstructured: %v", err)
+	}
+
+	tcs := []struct {
+		operation         string
+		areErrorsExpected bool
+	}{
+		{operation: "CREATE", areErrorsExpected: false},
+		{operation: "UPDATE", areErrorsExpected: true},
+		{operation: "", areErrorsExpected: false},
+	}
+
+	for _, tc := range tcs {
+		err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, tc.operation, nil, nil)
+		if (err != nil) != tc.areErrorsExpected {
+			t.Errorf("operation %q: expected errors %v, received %v", tc.operation, tc.areErrorsExpected, err)
+		}
+	}
+}
+
+func TestMatchesResourceDescription_MatchGeneration(t *testing.T) {
+	rawRule := []byte(`{"name":"check-deployments","match":{"resources":{"kinds":["Deployment"],"matchGeneration":"metadata.generation > 1"}},"validate":{"deny":{}}}`)
+
+	var rule kyverno.Rule
+	if err := json.Unmarshal(rawRule, &rule); err != nil {
+		t.Fatalf("invalid rule raw: %v", err)
+	}
+
+	tcs := []struct {
+		rawResource       []byte
+		areErrorsExpected bool
+	}{
+		{rawResource: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","generation":2}}`), areErrorsExpected: false},
+		{rawResource: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","generation":1}}`), areErrorsExpected: true},
+		{rawResource: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"}}`), areErrorsExpected: true},
+	}
+
+	for _, tc := range tcs {
+		resource, err := utils.ConvertToUnstructured(tc.rawResource)
+		if err != nil {
+			t.Fatalf("unable to convert raw resource to unstructured: %v", err)
+		}
+
+		err = MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, "", nil, nil)
+		if (err != nil) != tc.areErrorsExpected {
+			t.Errorf("resource %s: expected errors %v, received %v", tc.rawResource, tc.areErrorsExpected, err)
+		}
+	}
+}
+
 // Match multiple kinds
 func TestResourceDescriptionMatch_MultipleKind(t *testing.T) {
 	rawResource := []byte(`{
@@ -138,7 +253,7 @@ func TestResourceDescriptionMatch_MultipleKind(t *testing.T) {
 	}
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, nil); err != nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, "", []string{}, nil); err != nil {
 		t.Errorf("Testcase has failed due to the following:%v", err)
 	}
 
@@ -199,7 +314,7 @@ func TestResourceDescriptionMatch_Name(t *testing.T) {
 	}
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, nil); err != nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, "", []string{}, nil); err != nil {
 		t.Errorf("Testcase has failed due to the following:%v", err)
 	}
 }
@@ -259,7 +374,7 @@ func TestResourceDescriptionMatch_Name_Regex(t *testing.T) {
 	}
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, nil); err != nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, "", []string{}, nil); err != nil {
 		t.Errorf("Testcase has failed due to the following:%v", err)
 	}
 }
@@ -327,7 +442,7 @@ func TestResourceDescriptionMatch_Label_Expression_NotMatch(t *testing.T) {
 	}
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, nil); err != nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, "", []string{}, nil); err != nil {
 		t.Errorf("Testcase has failed due to the following:%v", err)
 	}
 }
@@ -396,7 +511,7 @@ func TestResourceDescriptionMatch_Label_Expression_Match(t *testing.T) {
 	}
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, nil); err != nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, "", []string{}, nil); err != nil {
 		t.Errorf("Testcase has failed due to the following:%v", err)
 	}
 }
@@ -476,7 +591,7 @@ func TestResourceDescriptionExclude_Label_Expression_Match(t *testing.T) {
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription},
 		ExcludeResources: kyverno.ExcludeResources{ResourceDescription: resourceDescriptionExclude}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, nil); err == nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, "", []string{}, nil); err == nil {
 		t.Errorf("Testcase has failed due to the following:\n Function has returned no error, even though it was supposed to fail")
 	}
 }
@@ -547,7 +662,7 @@ func TestWildCardAnnotation(t *testing.T) {
 }
 
 func testAnnotationMatch(t *testing.T, policy map[string]string, resource map[string]string, match bool) {
-	res := checkAnnotations(policy, resource)
+	res := CheckAnnotations(policy, resource)
 	if res != match {
 		t.Errorf("annotations %v -> labels %v: expected %v received %v", policy, resource, match, res)
 	}
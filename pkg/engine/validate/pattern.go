@@ -13,6 +13,10 @@ import (
 	apiresource "k8s.io/apimachinery/pkg/api/resource"
 )
 
+// RegexPrefix marks string pattern values that are regular expressions, e.g. "regex:^nginx:.*$".
+// The whole value after the prefix is the expression, it is not split on "|" or "&".
+const RegexPrefix = "regex:"
+
 type quantity int
 
 const (
@@ -154,6 +158,10 @@ func validateValueWithNilPattern(log logr.Logger, value interface{}) bool {
 
 // Handler for pattern values during validation process
 func validateValueWithStringPatterns(log logr.Logger, value interface{}, pattern string) bool {
+	if strings.HasPrefix(pattern, RegexPrefix) {
+		return validateValueWithRegex(log, value, strings.TrimPrefix(pattern, RegexPrefix))
+	}
+
 	conditions := strings.Split(pattern, "|")
 	for _, condition := range conditions {
 		condition = strings.Trim(condition, " ")
@@ -165,6 +173,34 @@ func validateValueWithStringPatterns(log logr.Logger, value interface{}, pattern
 	return false
 }
 
+// validateValueWithRegex matches scalar values against the regular expression
+func validateValueWithRegex(log logr.Logger, value interface{}, pattern string) bool {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		log.Info("invalid regex in pattern", "pattern", pattern, "error", err.Error())
+		return false
+	}
+
+	var strValue string
+	switch typedValue := value.(type) {
+	case string:
+		strValue = typedValue
+	case float64:
+		strValue = strconv.FormatFloat(typedValue, 'f', -1, 64)
+	case int64:
+		strValue = strconv.FormatInt(typedValue, 10)
+	case int:
+		strValue = strconv.Itoa(typedValue)
+	case bool:
+		strValue = strconv.FormatBool(typedValue)
+	default:
+		log.V(4).Info("unexpected type", "got", value, "expect", pattern)
+		return false
+	}
+
+	return re.MatchString(strValue)
+}
+
 func checkForAndConditionsAndValidate(log logr.Logger, value interface{}, pattern string) bool {
 	conditions := strings.Split(pattern, "&")
 	for _, condition := range conditions {
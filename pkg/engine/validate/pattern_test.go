@@ -212,6 +212,20 @@ func TestValidateValueWithPattern_StringsAllLogicalOperators(t *testing.T) {
 	assert.Assert(t, !ValidateValueWithPattern(log.Log, value, pattern))
 }
 
+func TestValidateValueWithPattern_Regex(t *testing.T) {
+	pattern := `regex:^registry\.corp\.com/(nginx|redis):.*$`
+	assert.Assert(t, ValidateValueWithPattern(log.Log, "registry.corp.com/nginx:1.19", pattern))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, "docker.io/nginx:1.19", pattern))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, "registry.corp.com/mysql:8", pattern))
+	assert.Assert(t, ValidateValueWithPattern(log.Log, float64(8080), "regex:^80[0-9]{2}$"))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, "anything", "regex:(unclosed"))
+}
+
+func TestValidateValueWithPattern_NonRegex(t *testing.T) {
+	assert.Assert(t, ValidateValueWithPattern(log.Log, "nginx:1.19", "nginx:*"))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, "nginx:1.19", "^nginx:.*$"))
+}
+
 func TestValidateValueWithPattern_EqualTwoFloats(t *testing.T) {
 	assert.Assert(t, ValidateValueWithPattern(log.Log, 7.0, 7.000))
 }
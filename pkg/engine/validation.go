@@ -171,13 +171,13 @@ func validateResourceWithRule(log logr.Logger, ctx *PolicyContext, rule kyverno.
 
 // matches checks if either the new or old resource satisfies the filter conditions defined in the rule
 func matches(logger logr.Logger, rule kyverno.Rule, ctx *PolicyContext) bool {
-	err := MatchesResourceDescription(ctx.NewResource, rule, ctx.AdmissionInfo, ctx.ExcludeGroupRole, ctx.NamespaceLabels)
+	err := MatchesResourceDescription(ctx.NewResource, rule, ctx.AdmissionInfo, ctx.Operation, ctx.ExcludeGroupRole, ctx.NamespaceLabels)
 	if err == nil {
 		return true
 	}
 
 	if !reflect.DeepEqual(ctx.OldResource, unstructured.Unstructured{}) {
-		err := MatchesResourceDescription(ctx.OldResource, rule, ctx.AdmissionInfo, ctx.ExcludeGroupRole, ctx.NamespaceLabels)
+		err := MatchesResourceDescription(ctx.OldResource, rule, ctx.AdmissionInfo, ctx.Operation, ctx.ExcludeGroupRole, ctx.NamespaceLabels)
 		if err == nil {
 			return true
 		}
@@ -247,14 +247,17 @@ func validatePatterns(log logr.Logger, ctx context.EvalInterface, resource unstr
 		var failedAnyPatternsErrors []error
 		var err error
 
-		anyPatterns, err := rule.Validation.DeserializeAnyPattern()
+		anyPatterns, err := rule.Validation.DeserializeAnyPatternEntries()
 		if err != nil {
 			resp.Success = false
 			resp.Message = fmt.Sprintf("failed to deserialize anyPattern, expected type array: %v", err)
 			return resp
 		}
 
-		for idx, pattern := range anyPatterns {
+		// the closest pattern is the one that failed at the deepest path
+		closestIdx, closestPath, closestDepth := -1, "", -1
+		for idx, entry := range anyPatterns {
+			pattern := entry.Pattern
 			if pattern, err = variables.SubstituteVars(logger, ctx, pattern); err != nil {
 				failedSubstitutionsErrors = append(failedSubstitutionsErrors, err)
 				continue
@@ -270,6 +273,10 @@ func validatePatterns(log logr.Logger, ctx context.EvalInterface, resource unstr
 			logger.V(4).Info("validation rule failed", "anyPattern[%d]", idx, "path", path)
 			patternErr := fmt.Errorf("Rule %s[%d] failed at path %s.", rule.Name, idx, path)
 			failedAnyPatternsErrors = append(failedAnyPatternsErrors, patternErr)
+
+			if depth := strings.Count(strings.Trim(path, "/"), "/"); depth > closestDepth {
+				closestIdx, closestPath, closestDepth = idx, path, depth
+			}
 		}
 
 		// Substitution failures
@@ -290,6 +297,9 @@ func validatePatterns(log logr.Logger, ctx context.EvalInterface, resource unstr
 
 			resp.Success = false
 			resp.Message = buildAnyPatternErrorMessage(rule, errorStr)
+			if closestIdx >= 0 && anyPatterns[closestIdx].Message != "" {
+				resp.Message = buildAnyPatternEntryErrorMessage(rule, closestIdx, anyPatterns[closestIdx].Message, closestPath)
+			}
 			return resp
 		}
 	}
@@ -321,3 +331,11 @@ func buildAnyPatternErrorMessage(rule kyverno.Rule, errors []string) string {
 
 	return fmt.Sprintf("validation error: %s. %s", rule.Validation.Message, errStr)
 }
+
+func buildAnyPatternEntryErrorMessage(rule kyverno.Rule, idx int, message, path string) string {
+	if strings.HasSuffix(message, ".") {
+		return fmt.Sprintf("validation error: %s Rule %s[%d] failed at path %s", message, rule.Name, idx, path)
+	}
+
+	return fmt.Sprintf("validation error: %s. Rule %s[%d] failed at path %s", message, rule.Name, idx, path)
+}
@@ -309,6 +309,119 @@ func TestValidate_Fail_anyPattern(t *testing.T) {
 	}
 }
 
+func TestValidate_Fail_anyPattern_EntryMessage(t *testing.T) {
+	rawPolicy := []byte(`
+	{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {
+		   "name": "validate-images"
+		},
+		"spec": {
+		   "rules": [
+			  {
+				 "name": "check-images",
+				 "match": {
+					"resources": {
+					   "kinds": [
+						  "Pod"
+					   ]
+					}
+				 },
+				 "validate": {
+					"message": "Images must be trusted",
+					"anyPattern": [
+					   {
+						  "pattern": {
+							 "metadata": {
+								"labels": {
+								   "trusted": "true"
+								}
+							 }
+						  },
+						  "message": "The pod must be labelled as trusted"
+					   },
+					   {
+						  "pattern": {
+							 "spec": {
+								"containers": [
+								   {
+									  "image": "registry.io/*"
+								   }
+								]
+							 }
+						  },
+						  "message": "Images must come from registry.io"
+					   },
+					   {
+						  "metadata": {
+							 "name": "trusted-*"
+						  }
+					   }
+					]
+				 }
+			  }
+		   ]
+		}
+	 }
+	`)
+
+	rawResource := []byte(`
+	{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {
+		   "name": "myapp-pod",
+		   "labels": {
+			  "app": "myapp"
+		   }
+		},
+		"spec": {
+		   "containers": [
+			  {
+				 "name": "nginx",
+				 "image": "nginx"
+			  }
+		   ]
+		}
+	 }
+	`)
+
+	var policy kyverno.ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	resourceUnstructured, err := utils.ConvertToUnstructured(rawResource)
+	assert.NilError(t, err)
+	er := Validate(&PolicyContext{Policy: policy, NewResource: *resourceUnstructured, JSONContext: context.NewContext()})
+	assert.Assert(t, !er.IsSuccessful())
+	assert.Equal(t, len(er.PolicyResponse.Rules), 1)
+	assert.Equal(t, er.PolicyResponse.Rules[0].Message, "validation error: Images must come from registry.io. Rule check-images[1] failed at path /spec/containers/0/image/")
+
+	rawResource = []byte(`
+	{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {
+		   "name": "trusted-pod"
+		},
+		"spec": {
+		   "containers": [
+			  {
+				 "name": "nginx",
+				 "image": "nginx"
+			  }
+		   ]
+		}
+	 }
+	`)
+
+	resourceUnstructured, err = utils.ConvertToUnstructured(rawResource)
+	assert.NilError(t, err)
+	er = Validate(&PolicyContext{Policy: policy, NewResource: *resourceUnstructured, JSONContext: context.NewContext()})
+	assert.Assert(t, er.IsSuccessful())
+}
+
 func TestValidate_host_network_port(t *testing.T) {
 	rawPolicy := []byte(`
 	{
@@ -1115,3 +1115,55 @@ func Test_Eval_NotIn_String_Set_Fail(t *testing.T) {
 		t.Error("expected to fail")
 	}
 }
+
+func Test_Eval_AnyIn_Const_Slice_Pass(t *testing.T) {
+	ctx := context.NewContext()
+	condition := kyverno.Condition{
+		Key:      []interface{}{"CREATE", "CONNECT"},
+		Operator: kyverno.AnyIn,
+		Value:    []interface{}{"CREATE", "UPDATE"},
+	}
+
+	if !Evaluate(log.Log, ctx, condition) {
+		t.Error("expected to pass")
+	}
+}
+
+func Test_Eval_AnyIn_Const_Slice_Fail(t *testing.T) {
+	ctx := context.NewContext()
+	condition := kyverno.Condition{
+		Key:      []interface{}{"DELETE", "CONNECT"},
+		Operator: kyverno.AnyIn,
+		Value:    []interface{}{"CREATE", "UPDATE"},
+	}
+
+	if Evaluate(log.Log, ctx, condition) {
+		t.Error("expected to fail")
+	}
+}
+
+func Test_Eval_AllIn_Const_Slice_Pass(t *testing.T) {
+	ctx := context.NewContext()
+	condition := kyverno.Condition{
+		Key:      []interface{}{"CREATE", "UPDATE"},
+		Operator: kyverno.AllIn,
+		Value:    []interface{}{"CREATE", "UPDATE", "DELETE"},
+	}
+
+	if !Evaluate(log.Log, ctx, condition) {
+		t.Error("expected to pass")
+	}
+}
+
+func Test_Eval_AllIn_Const_Slice_Fail(t *testing.T) {
+	ctx := context.NewContext()
+	condition := kyverno.Condition{
+		Key:      []interface{}{"CREATE", "CONNECT"},
+		Operator: kyverno.AllIn,
+		Value:    []interface{}{"CREATE", "UPDATE", "DELETE"},
+	}
+
+	if Evaluate(log.Log, ctx, condition) {
+		t.Error("expected to fail")
+	}
+}
@@ -0,0 +1,91 @@
+package operator
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/kyverno/kyverno/pkg/engine/context"
+)
+
+//NewAnyInHandler returns handler to manage AnyIn operations
+func NewAnyInHandler(log logr.Logger, ctx context.EvalInterface, subHandler VariableSubstitutionHandler) OperatorHandler {
+	return AnyInHandler{
+		ctx:        ctx,
+		subHandler: subHandler,
+		log:        log,
+	}
+}
+
+//AnyInHandler provides implementation to handle AnyIn Operator
+type AnyInHandler struct {
+	ctx        context.EvalInterface
+	subHandler VariableSubstitutionHandler
+	log        logr.Logger
+}
+
+//Evaluate evaluates expression with AnyIn Operator
+func (anyin AnyInHandler) Evaluate(key, value interface{}) bool {
+	var err error
+	// substitute the variables
+	if key, err = anyin.subHandler(anyin.log, anyin.ctx, key); err != nil {
+		anyin.log.Error(err, "Failed to resolve variable", "variable", key)
+		return false
+	}
+
+	if value, err = anyin.subHandler(anyin.log, anyin.ctx, value); err != nil {
+		anyin.log.Error(err, "Failed to resolve variable", "variable", value)
+		return false
+	}
+
+	switch typedKey := key.(type) {
+	case string:
+		return anyin.validateValueWithStringPattern(typedKey, value)
+	case []interface{}:
+		return anyin.validateValueWithSlicePattern(typedKey, value)
+	default:
+		anyin.log.Info("Unsupported type", "value", typedKey, "type", fmt.Sprintf("%T", typedKey))
+		return false
+	}
+}
+
+func (anyin AnyInHandler) validateValueWithStringPattern(key string, value interface{}) bool {
+	invalidType, keyExists := keyExistsInArray(key, value, anyin.log)
+	if invalidType {
+		anyin.log.Info("expected type []string", "value", value, "type", fmt.Sprintf("%T", value))
+		return false
+	}
+
+	return keyExists
+}
+
+func (anyin AnyInHandler) validateValueWithSlicePattern(key []interface{}, value interface{}) bool {
+	for _, k := range key {
+		v, ok := k.(string)
+		if !ok {
+			anyin.log.Info("expected type []string", "value", key, "type", fmt.Sprintf("%T", k))
+			return false
+		}
+
+		if anyin.validateValueWithStringPattern(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (anyin AnyInHandler) validateValueWithBoolPattern(_ bool, _ interface{}) bool {
+	return false
+}
+
+func (anyin AnyInHandler) validateValueWithIntPattern(_ int64, _ interface{}) bool {
+	return false
+}
+
+func (anyin AnyInHandler) validateValueWithFloatPattern(_ float64, _ interface{}) bool {
+	return false
+}
+
+func (anyin AnyInHandler) validateValueWithMapPattern(_ map[string]interface{}, _ interface{}) bool {
+	return false
+}
@@ -45,6 +45,13 @@ func CreateOperatorHandler(log logr.Logger, ctx context.EvalInterface, op kyvern
 	case strings.ToLower(string(kyverno.NotIn)):
 		return NewNotInHandler(log, ctx, subHandler)
 
+	case strings.ToLower(string(kyverno.AnyIn)):
+		return NewAnyInHandler(log, ctx, subHandler)
+
+	// In evaluates a list of keys as a subset of the values
+	case strings.ToLower(string(kyverno.AllIn)):
+		return NewInHandler(log, ctx, subHandler)
+
 	case strings.ToLower(string(kyverno.GreaterThanOrEquals)),
 		strings.ToLower(string(kyverno.GreaterThan)),
 		strings.ToLower(string(kyverno.LessThanOrEquals)),
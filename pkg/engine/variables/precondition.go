@@ -0,0 +1,106 @@
+package variables
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"github.com/kyverno/kyverno/pkg/engine/context"
+	"github.com/kyverno/kyverno/pkg/engine/variables/operator"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PreconditionEvaluator evaluates the compiled preconditions of a rule. Operators are
+// resolved and conditions without variables are detected once, at compile time.
+type PreconditionEvaluator struct {
+	log        logr.Logger
+	conditions []compiledCondition
+}
+
+type compiledCondition struct {
+	condition  kyverno.Condition
+	newHandler func(log logr.Logger, ctx context.EvalInterface, subHandler operator.VariableSubstitutionHandler) operator.OperatorHandler
+	subHandler operator.VariableSubstitutionHandler
+}
+
+// CompilePreconditions validates the operators and values of the rule preconditions and
+// compiles them into an evaluator
+func CompilePreconditions(rule kyverno.Rule) (*PreconditionEvaluator, error) {
+	evaluator := &PreconditionEvaluator{log: log.Log.WithName("Preconditions").WithValues("rule", rule.Name)}
+	for i, condition := range rule.Conditions {
+		if err := condition.ValidateOperator(); err != nil {
+			return nil, fmt.Errorf("preconditions[%d]: %v", i, err)
+		}
+
+		subHandler := SubstituteVars
+		if !containsVariables(condition.Key) && !containsVariables(condition.Value) {
+			subHandler = noSubstitution
+		}
+
+		evaluator.conditions = append(evaluator.conditions, compiledCondition{
+			condition:  condition,
+			newHandler: handlerFactory(condition.Operator),
+			subHandler: subHandler,
+		})
+	}
+
+	return evaluator, nil
+}
+
+// Matches returns true if all the preconditions are satisfied in the context
+func (e *PreconditionEvaluator) Matches(ctx context.EvalInterface) bool {
+	for _, c := range e.conditions {
+		if !c.newHandler(e.log, ctx, c.subHandler).Evaluate(c.condition.Key, c.condition.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// handlerFactory returns the constructor of the handler of a supported operator
+func handlerFactory(op kyverno.ConditionOperator) func(logr.Logger, context.EvalInterface, operator.VariableSubstitutionHandler) operator.OperatorHandler {
+	switch strings.ToLower(string(op)) {
+	case strings.ToLower(string(kyverno.Equal)), strings.ToLower(string(kyverno.Equals)):
+		return operator.NewEqualHandler
+	case strings.ToLower(string(kyverno.NotEqual)), strings.ToLower(string(kyverno.NotEquals)):
+		return operator.NewNotEqualHandler
+	case strings.ToLower(string(kyverno.In)), strings.ToLower(string(kyverno.AllIn)):
+		return operator.NewInHandler
+	case strings.ToLower(string(kyverno.NotIn)):
+		return operator.NewNotInHandler
+	case strings.ToLower(string(kyverno.AnyIn)):
+		return operator.NewAnyInHandler
+	default:
+		return func(log logr.Logger, ctx context.EvalInterface, subHandler operator.VariableSubstitutionHandler) operator.OperatorHandler {
+			return operator.NewNumericOperatorHandler(log, ctx, subHandler, op)
+		}
+	}
+}
+
+func noSubstitution(log logr.Logger, ctx context.EvalInterface, pattern interface{}) (interface{}, error) {
+	return pattern, nil
+}
+
+// containsVariables checks if a string of the element, or a key of its maps, is a variable
+func containsVariables(element interface{}) bool {
+	switch typed := element.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			if IsVariable(key) || containsVariables(value) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, value := range typed {
+			if containsVariables(value) {
+				return true
+			}
+		}
+	case string:
+		return IsVariable(typed)
+	}
+
+	return false
+}
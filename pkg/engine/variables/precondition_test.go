@@ -0,0 +1,60 @@
+package variables
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"github.com/kyverno/kyverno/pkg/engine/context"
+	"gotest.tools/assert"
+)
+
+func Test_CompilePreconditions(t *testing.T) {
+	resource := []byte(`{"metadata":{"name":"web","labels":{"tier":"frontend"}},"spec":{"replicas":3}}`)
+	ctx := context.NewContext()
+	err := ctx.AddResource(resource)
+	assert.NilError(t, err)
+
+	testcases := []struct {
+		condition string
+		expected  bool
+	}{
+		{condition: `{"key":"{{request.object.metadata.name}}","operator":"Equals","value":"web"}`, expected: true},
+		{condition: `{"key":"{{request.object.metadata.name}}","operator":"Equals","value":"api"}`, expected: false},
+		{condition: `{"key":"{{request.object.metadata.name}}","operator":"NotEquals","value":"api"}`, expected: true},
+		{condition: `{"key":"{{request.object.metadata.labels.tier}}","operator":"In","value":["frontend","backend"]}`, expected: true},
+		{condition: `{"key":"{{request.object.metadata.labels.tier}}","operator":"NotIn","value":["frontend","backend"]}`, expected: false},
+		{condition: `{"key":["frontend","cache"],"operator":"AnyIn","value":["frontend","backend"]}`, expected: true},
+		{condition: `{"key":["frontend","cache"],"operator":"AllIn","value":["frontend","backend"]}`, expected: false},
+		{condition: `{"key":3,"operator":"GreaterThan","value":2}`, expected: true},
+		{condition: `{"key":"3","operator":"GreaterThanOrEquals","value":4}`, expected: false},
+		{condition: `{"key":3,"operator":"LessThan","value":"4"}`, expected: true},
+		{condition: `{"key":3,"operator":"LessThanOrEquals","value":3}`, expected: true},
+	}
+
+	for _, testcase := range testcases {
+		var condition kyverno.Condition
+		err := json.Unmarshal([]byte(testcase.condition), &condition)
+		assert.NilError(t, err, testcase.condition)
+
+		evaluator, err := CompilePreconditions(kyverno.Rule{Name: "r", Conditions: []kyverno.Condition{condition}})
+		assert.NilError(t, err, testcase.condition)
+		assert.Equal(t, evaluator.Matches(ctx), testcase.expected, testcase.condition)
+		assert.Equal(t, evaluator.Matches(ctx), Evaluate(evaluator.log, ctx, condition), testcase.condition)
+	}
+}
+
+func Test_CompilePreconditions_Invalid(t *testing.T) {
+	_, err := CompilePreconditions(kyverno.Rule{Conditions: []kyverno.Condition{
+		{Key: "a", Operator: kyverno.Equals, Value: "a"},
+		{Key: "a", Operator: kyverno.In, Value: "a"},
+	}})
+	assert.Error(t, err, "preconditions[1]: operator In expects a list value, found string")
+
+	_, err = CompilePreconditions(kyverno.Rule{Conditions: []kyverno.Condition{{Key: "a", Operator: "Matches", Value: "a"}}})
+	assert.ErrorContains(t, err, `preconditions[0]: unsupported operator "Matches"`)
+
+	evaluator, err := CompilePreconditions(kyverno.Rule{})
+	assert.NilError(t, err)
+	assert.Assert(t, evaluator.Matches(context.NewContext()))
+}
@@ -0,0 +1,63 @@
+package generate
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	dclient "github.com/kyverno/kyverno/pkg/dclient"
+	"github.com/kyverno/kyverno/pkg/engine/context"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newSecret(namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetNamespace(namespace)
+	secret.SetName(name)
+	secret.SetLabels(labels)
+	return secret
+}
+
+func Test_expandCloneList(t *testing.T) {
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "secrets"}: "SecretList",
+	}
+
+	client, err := dclient.NewMockClient(runtime.NewScheme(), gvrToListKind,
+		newSecret("default", "regcred", map[string]string{"app": "web"}),
+		newSecret("default", "tls", map[string]string{"app": "web"}),
+		newSecret("default", "token", nil),
+		newSecret("prod", "regcred", map[string]string{"app": "web"}),
+	)
+	assert.NilError(t, err)
+	client.SetDiscovery(dclient.NewFakeDiscoveryClient(nil))
+
+	var generations []kyverno.Generation
+	err = json.Unmarshal([]byte(`[
+		{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.name}}","data":{}},
+		{"namespace":"{{request.object.metadata.name}}","synchronize":true,"cloneList":{"namespace":"default","kinds":["Secret"],"selector":{"matchLabels":{"app":"web"}}}}
+	]`), &generations)
+	assert.NilError(t, err)
+
+	expanded, err := expandCloneList(log.Log, client, generations, context.NewContext())
+	assert.NilError(t, err)
+	assert.Equal(t, len(expanded), 3)
+	assert.DeepEqual(t, expanded[0], generations[0])
+
+	var names []string
+	for _, generation := range expanded[1:] {
+		assert.Equal(t, generation.Kind, "Secret")
+		assert.Equal(t, generation.Namespace, "{{request.object.metadata.name}}")
+		assert.Equal(t, generation.Synchronize, true)
+		assert.Equal(t, generation.Clone.Namespace, "default")
+		assert.Equal(t, generation.Clone.Name, generation.Name)
+		names = append(names, generation.Name)
+	}
+	assert.DeepEqual(t, names, []string{"regcred", "tls"})
+}
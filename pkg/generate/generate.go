@@ -205,7 +205,6 @@ func (c *Controller) applyGeneratePolicy(log logr.Logger, policyContext *engine.
 
 		startTime := time.Now()
 		processExisting := false
-		var genResource kyverno.ResourceSpec
 
 		if len(rule.MatchResources.Kinds) > 0 {
 			if len(rule.MatchResources.Annotations) == 0 && rule.MatchResources.Selector == nil {
@@ -222,14 +221,22 @@ func (c *Controller) applyGeneratePolicy(log logr.Logger, policyContext *engine.
 		}
 
 		if !processExisting {
-			genResource, err = applyRule(log, c.client, rule, resource, jsonContext, policy.Name, gr)
+			generations, err := expandCloneList(log, c.client, rule.GenerateTargets(), jsonContext)
 			if err != nil {
-				log.Error(err, "failed to apply generate rule", "policy", policy.Name,
-					"rule", rule.Name, "resource", resource.GetName())
+				log.Error(err, "failed to list cloneList sources", "policy", policy.Name, "rule", rule.Name)
 				return nil, err
 			}
+
+			for _, generation := range generations {
+				genResource, err := applyRule(log, c.client, generation, resource, jsonContext, policy.Name, gr)
+				if err != nil {
+					log.Error(err, "failed to apply generate rule", "policy", policy.Name,
+						"rule", rule.Name, "resource", resource.GetName())
+					return nil, err
+				}
+				genResources = append(genResources, genResource)
+			}
 			ruleNameToProcessingTime[rule.Name] = time.Since(startTime)
-			genResources = append(genResources, genResource)
 		}
 	}
 
@@ -302,12 +309,56 @@ func getResourceInfo(object map[string]interface{}) (kind, name, namespace, apiv
 	return
 }
 
-func applyRule(log logr.Logger, client *dclient.Client, rule kyverno.Rule, resource unstructured.Unstructured, ctx context.EvalInterface, policy string, gr kyverno.GenerateRequest) (kyverno.ResourceSpec, error) {
+// expandCloneList replaces each generation declaring cloneList with a clone generation per
+// source resource of the listed kinds in the cloneList namespace. The resources are generated
+// in the namespace of the generation and named after their source.
+func expandCloneList(log logr.Logger, client *dclient.Client, generations []kyverno.Generation, ctx context.EvalInterface) ([]kyverno.Generation, error) {
+	var expanded []kyverno.Generation
+	for _, generation := range generations {
+		if len(generation.CloneList.Kinds) == 0 {
+			expanded = append(expanded, generation)
+			continue
+		}
+
+		namespace, err := variables.SubstituteVars(log, ctx, generation.CloneList.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		sourceNamespace, _ := namespace.(string)
+		for _, kind := range generation.CloneList.Kinds {
+			sources, err := client.ListResource("", kind, sourceNamespace, generation.CloneList.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list %s in namespace %s: %v", kind, sourceNamespace, err)
+			}
+
+			for _, source := range sources.Items {
+				expanded = append(expanded, kyverno.Generation{
+					ResourceSpec: kyverno.ResourceSpec{
+						APIVersion: source.GetAPIVersion(),
+						Kind:       kind,
+						Namespace:  generation.Namespace,
+						Name:       source.GetName(),
+					},
+					Synchronize: generation.Synchronize,
+					Clone: kyverno.CloneFrom{
+						Namespace: sourceNamespace,
+						Name:      source.GetName(),
+					},
+				})
+			}
+		}
+	}
+
+	return expanded, nil
+}
+
+func applyRule(log logr.Logger, client *dclient.Client, generation kyverno.Generation, resource unstructured.Unstructured, ctx context.EvalInterface, policy string, gr kyverno.GenerateRequest) (kyverno.ResourceSpec, error) {
 	var rdata map[string]interface{}
 	var err error
 	var mode ResourceMode
 	var noGenResource kyverno.ResourceSpec
-	genUnst, err := getUnstrRule(rule.Generation.DeepCopy())
+	genUnst, err := getUnstrRule(generation.DeepCopy())
 	if err != nil {
 		return noGenResource, err
 	}
@@ -379,13 +430,16 @@ func applyRule(log logr.Logger, client *dclient.Client, rule kyverno.Rule, resou
 	// - app.kubernetes.io/managed-by: kyverno
 	// - kyverno.io/generated-by: kind/namespace/name (trigger resource)
 	manageLabels(newResource, resource)
+	// manage owner references
+	// - the trigger owns the generated resource if orphanDependents is set to false
+	manageOwnerReferences(logger, newResource, resource, generation.OrphanDependents)
 	// Add Synchronize label
 	label := newResource.GetLabels()
 	label["policy.kyverno.io/policy-name"] = policy
 	label["policy.kyverno.io/gr-name"] = gr.Name
 	delete(label, "generate.kyverno.io/clone-policy-name")
 	if mode == Create {
-		if rule.Generation.Synchronize {
+		if generation.Synchronize {
 			label["policy.kyverno.io/synchronize"] = "enable"
 		} else {
 			label["policy.kyverno.io/synchronize"] = "disable"
@@ -403,13 +457,13 @@ func applyRule(log logr.Logger, client *dclient.Client, rule kyverno.Rule, resou
 		logger.V(2).Info("generated target resource")
 
 	} else if mode == Update {
-		if rule.Generation.Synchronize {
+		if generation.Synchronize {
 			label["policy.kyverno.io/synchronize"] = "enable"
 		} else {
 			label["policy.kyverno.io/synchronize"] = "disable"
 		}
 
-		if rule.Generation.Synchronize {
+		if generation.Synchronize {
 			logger.V(4).Info("updating existing resource")
 			newResource.SetLabels(label)
 			_, err := client.UpdateResource(genAPIVersion, genKind, genNamespace, newResource, false)
@@ -469,6 +523,38 @@ func manageClone(log logr.Logger, apiVersion, kind, namespace, name, policy stri
 		return nil, Skip, fmt.Errorf("source resource %s %s/%s/%s not found. %v", apiVersion, kind, rNamespace, rName, err)
 	}
 
+	// status and the server-assigned metadata are managed by the API server and are not cloned
+	unstructured.RemoveNestedField(obj.Object, "status")
+	obj.SetUID("")
+	obj.SetResourceVersion("")
+	obj.SetSelfLink("")
+
+	exclude, _, err := unstructured.NestedStringSlice(clone, "exclude")
+	if err != nil {
+		return nil, Skip, fmt.Errorf("failed to find excluded fields: %v", err)
+	}
+
+	for _, pointer := range exclude {
+		unstructured.RemoveNestedField(obj.Object, pointerFields(pointer)...)
+	}
+
+	transformations, _, err := unstructured.NestedSlice(clone, "transformations")
+	if err != nil {
+		return nil, Skip, fmt.Errorf("failed to find transformations: %v", err)
+	}
+
+	for _, t := range transformations {
+		transformation, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		pointer, _, _ := unstructured.NestedString(transformation, "path")
+		if err := unstructured.SetNestedField(obj.Object, transformation["value"], pointerFields(pointer)...); err != nil {
+			return nil, Skip, fmt.Errorf("failed to transform %s: %v", pointer, err)
+		}
+	}
+
 	// check if resource to be generated exists
 	newResource, err := client.GetResource(apiVersion, kind, namespace, name)
 	if err == nil {
@@ -477,6 +563,7 @@ func manageClone(log logr.Logger, apiVersion, kind, namespace, name, policy stri
 		obj.SetCreationTimestamp(newResource.GetCreationTimestamp())
 		obj.SetManagedFields(newResource.GetManagedFields())
 		obj.SetResourceVersion(newResource.GetResourceVersion())
+		unstructured.RemoveNestedField(newResource.Object, "status")
 		if reflect.DeepEqual(obj, newResource) {
 			return nil, Skip, nil
 		}
@@ -488,6 +575,16 @@ func manageClone(log logr.Logger, apiVersion, kind, namespace, name, policy stri
 
 }
 
+// pointerFields returns the field names of the JSON pointer
+func pointerFields(pointer string) []string {
+	fields := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, field := range fields {
+		fields[i] = strings.ReplaceAll(strings.ReplaceAll(field, "~1", "/"), "~0", "~")
+	}
+
+	return fields
+}
+
 // ResourceMode defines the mode for generated resource
 type ResourceMode string
 
@@ -0,0 +1,42 @@
+package generate
+
+import (
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// manageOwnerReferences makes the trigger resource an owner of the generated resource if
+// orphanDependents is set to false, so that the generated resource is garbage collected
+// with the trigger. Owner references cannot cross namespaces: no owner reference is added
+// if the trigger is namespaced and the generated resource is in another namespace.
+func manageOwnerReferences(log logr.Logger, unstr *unstructured.Unstructured, triggerResource unstructured.Unstructured, orphanDependents *bool) {
+	if orphanDependents == nil || *orphanDependents {
+		return
+	}
+
+	if triggerResource.GetUID() == "" {
+		log.V(2).Info("trigger resource has no uid, generated resource is not owned by the trigger")
+		return
+	}
+
+	if triggerResource.GetNamespace() != "" && triggerResource.GetNamespace() != unstr.GetNamespace() {
+		log.V(2).Info("owner references cannot cross namespaces, generated resource is not owned by the trigger",
+			"triggerNamespace", triggerResource.GetNamespace())
+		return
+	}
+
+	ownerReferences := unstr.GetOwnerReferences()
+	for _, ownerReference := range ownerReferences {
+		if ownerReference.UID == triggerResource.GetUID() {
+			return
+		}
+	}
+
+	unstr.SetOwnerReferences(append(ownerReferences, metav1.OwnerReference{
+		APIVersion: triggerResource.GetAPIVersion(),
+		Kind:       triggerResource.GetKind(),
+		Name:       triggerResource.GetName(),
+		UID:        triggerResource.GetUID(),
+	}))
+}
@@ -0,0 +1,48 @@
+package generate
+
+import (
+	"reflect"
+	"testing"
+
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_manageOwnerReferences(t *testing.T) {
+	keep, orphan := true, false
+	testcases := []struct {
+		description      string
+		trigger          string
+		generated        string
+		orphanDependents *bool
+		owners           []string
+	}{
+		{description: "not set", trigger: "default", generated: "default"},
+		{description: "orphaned", trigger: "default", generated: "default", orphanDependents: &keep},
+		{description: "same namespace", trigger: "default", generated: "default", orphanDependents: &orphan, owners: []string{"trigger"}},
+		{description: "cluster-scoped trigger", trigger: "", generated: "default", orphanDependents: &orphan, owners: []string{"trigger"}},
+		{description: "other namespace", trigger: "default", generated: "prod", orphanDependents: &orphan},
+	}
+
+	for _, testcase := range testcases {
+		trigger := unstructured.Unstructured{}
+		trigger.SetAPIVersion("v1")
+		trigger.SetKind("ConfigMap")
+		trigger.SetName("trigger")
+		trigger.SetNamespace(testcase.trigger)
+		trigger.SetUID("3f6e7a4c")
+
+		generated := &unstructured.Unstructured{}
+		generated.SetNamespace(testcase.generated)
+
+		manageOwnerReferences(log.Log, generated, trigger, testcase.orphanDependents)
+		manageOwnerReferences(log.Log, generated, trigger, testcase.orphanDependents)
+
+		var owners []string
+		for _, ownerReference := range generated.GetOwnerReferences() {
+			owners = append(owners, ownerReference.Name)
+		}
+		assert.Assert(t, reflect.DeepEqual(owners, testcase.owners), testcase.description)
+	}
+}
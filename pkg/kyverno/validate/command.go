@@ -123,6 +123,17 @@ func Command() *cobra.Command {
 				}
 			}
 
+			policyList := make([]v1.ClusterPolicy, 0, len(policies))
+			for _, policy := range policies {
+				policyList = append(policyList, *policy)
+			}
+
+			if err := policy2.ValidatePolicies(policyList); err != nil {
+				fmt.Println("----------------------------------------------------------------------")
+				fmt.Printf("Error: invalid policies.\nCause: %s\n\n", err)
+				invalidPolicyFound = true
+			}
+
 			if invalidPolicyFound == true {
 				os.Exit(1)
 			}
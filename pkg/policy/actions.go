@@ -2,6 +2,7 @@ package policy
 
 import (
 	"fmt"
+	"reflect"
 
 	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
 	dclient "github.com/kyverno/kyverno/pkg/dclient"
@@ -20,12 +21,12 @@ type Validation interface {
 // - Mutate
 // - Validation
 // - Generate
-func validateActions(idx int, rule kyverno.Rule, client *dclient.Client, mock bool) error {
+func validateActions(idx int, rule kyverno.Rule, client *dclient.Client, mock bool, opts ValidateOptions) error {
 	var checker Validation
 
 	// Mutate
 	if rule.HasMutate() {
-		checker = mutate.NewMutateFactory(rule.Mutation)
+		checker = mutate.NewMutateFactoryWithLimits(rule.Mutation, opts.maxPatchOps(), opts.maxDepth())
 		if path, err := checker.Validate(); err != nil {
 			return fmt.Errorf("path: spec.rules[%d].mutate.%s.: %v", idx, path, err)
 		}
@@ -33,29 +34,82 @@ func validateActions(idx int, rule kyverno.Rule, client *dclient.Client, mock bo
 
 	// Validate
 	if rule.HasValidate() {
-		checker = validate.NewValidateFactory(rule.Validation)
+		checker = validate.NewValidateFactoryWithLimit(rule.Validation, opts.maxDepth())
 		if path, err := checker.Validate(); err != nil {
 			return fmt.Errorf("path: spec.rules[%d].validate.%s.: %v", idx, path, err)
 		}
 	}
 
 	// Generate
-	if rule.HasGenerate() {
+	hasGenerateBlock := !reflect.DeepEqual(rule.Generation, kyverno.Generation{})
+	if hasGenerateBlock {
 		//TODO: this check is there to support offline validations
 		// generate uses selfSubjectReviews to verify actions
 		// this need to modified to use different implementation for online and offline mode
-		if mock {
-			checker = generate.NewFakeGenerate(rule.Generation)
-			if path, err := checker.Validate(); err != nil {
-				return fmt.Errorf("path: spec.rules[%d].generate.%s.: %v", idx, path, err)
-			}
-		} else {
-			checker = generate.NewGenerateFactory(client, rule.Generation, log.Log)
+		checker = newGenerateChecker(rule.Generation, client, mock, opts)
+		if path, err := checker.Validate(); err != nil {
+			return fmt.Errorf("path: spec.rules[%d].generate.%s.: %v", idx, path, err)
+		}
+	}
+
+	// Generations
+	if len(rule.Generations) > 0 {
+		if hasGenerateBlock {
+			return fmt.Errorf("path: spec.rules[%d].generations: generate and generations are mutually exclusive", idx)
+		}
+
+		if path, err := validateGenerationTargets(rule.Generations); err != nil {
+			return fmt.Errorf("path: spec.rules[%d].%s: %v", idx, path, err)
+		}
+
+		for i, generation := range rule.Generations {
+			checker = newGenerateChecker(generation, client, mock, opts)
 			if path, err := checker.Validate(); err != nil {
-				return fmt.Errorf("path: spec.rules[%d].generate.%s.: %v", idx, path, err)
+				return fmt.Errorf("path: spec.rules[%d].generations[%d].%s.: %v", idx, i, path, err)
 			}
 		}
 	}
 
 	return nil
 }
+
+// newGenerateChecker returns the checker of the generate block, which uses fake access
+// checks in mock mode
+func newGenerateChecker(generation kyverno.Generation, client *dclient.Client, mock bool, opts ValidateOptions) Validation {
+	if mock {
+		checker := generate.NewFakeGenerate(generation)
+		checker.SetCRDLookup(opts.CRDLookup)
+		return checker
+	}
+
+	checker := generate.NewGenerateFactory(client, generation, log.Log)
+	checker.SetCRDLookup(opts.CRDLookup)
+	return checker
+}
+
+// validateGenerationTargets checks each entry of generations creates a different resource
+func validateGenerationTargets(generations []kyverno.Generation) (string, error) {
+	targets := make(map[string]int, len(generations))
+	for i, generation := range generations {
+		target := fmt.Sprintf("%s %s/%s", generation.Kind, generation.Namespace, generation.Name)
+		if j, ok := targets[target]; ok {
+			return fmt.Sprintf("generations[%d]", i), fmt.Errorf("duplicate target %s, already generated by generations[%d]", target, j)
+		}
+
+		targets[target] = i
+	}
+
+	return "", nil
+}
+
+// generateTargetPath returns the path in the rule of the entry i of rule.GenerateTargets()
+func generateTargetPath(rule kyverno.Rule, i int) string {
+	if !reflect.DeepEqual(rule.Generation, kyverno.Generation{}) {
+		if i == 0 {
+			return "generate"
+		}
+		i--
+	}
+
+	return fmt.Sprintf("generations[%d]", i)
+}
@@ -0,0 +1,133 @@
+// Package analysis reports on the behaviour of policies, such as their capabilities, the
+// operations they cover or the changes between two versions, for tools and reviews
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+)
+
+// RiskProfile classifies a policy by the effects of its rules
+type RiskProfile struct {
+	// Additive is true if the policy only mutates resources, such as setting defaults
+	Additive bool
+
+	// Restrictive is true if the policy has validate rules, which can reject resources
+	Restrictive bool
+
+	// Generative is true if the policy has generate rules, which create resources
+	Generative bool
+
+	// Summary lists the classes of the policy, or is "none" if the policy has no rules
+	Summary string
+}
+
+// GetRiskProfile returns the classification of the policy according to its rule types
+func GetRiskProfile(p kyverno.ClusterPolicy) RiskProfile {
+	var profile RiskProfile
+	var mutates bool
+	for _, rule := range p.Spec.Rules {
+		mutates = mutates || rule.HasMutate()
+		profile.Restrictive = profile.Restrictive || rule.HasValidate()
+		profile.Generative = profile.Generative || rule.HasGenerate()
+	}
+
+	profile.Additive = mutates && !profile.Restrictive && !profile.Generative
+
+	var classes []string
+	if profile.Additive {
+		classes = append(classes, "additive")
+	}
+	if profile.Restrictive {
+		classes = append(classes, "restrictive")
+	}
+	if profile.Generative {
+		classes = append(classes, "generative")
+	}
+
+	profile.Summary = "none"
+	if len(classes) > 0 {
+		profile.Summary = strings.Join(classes, ", ")
+	}
+
+	return profile
+}
+
+// Capabilities summarizes what a policy can do, for tools which do not interpret rules
+type Capabilities struct {
+	// Kinds are the kinds matched by the rules and the kinds they generate
+	Kinds []string `json:"kinds"`
+
+	// Operations are the admission operations the rules apply to
+	Operations []string `json:"operations"`
+
+	// RuleTypes are the types of the rules: mutate, validate and generate
+	RuleTypes []string `json:"ruleTypes"`
+
+	// UsesVariables is true if the rules reference variables
+	UsesVariables bool `json:"usesVariables"`
+
+	// UsesExternalData is true if the rules load config maps or call the API server
+	UsesExternalData bool `json:"usesExternalData"`
+
+	// Risk is the summary of the risk profile of the policy
+	Risk string `json:"risk"`
+}
+
+// GetCapabilities returns the kinds, operations and rule types of the policy, and whether
+// it depends on variables or data loaded from the cluster. Lists are sorted.
+func GetCapabilities(p kyverno.ClusterPolicy) Capabilities {
+	kinds := make(map[string]bool)
+	operations := make(map[string]bool)
+	ruleTypes := make(map[string]bool)
+	var capabilities Capabilities
+	for _, rule := range p.Spec.Rules {
+		for _, kind := range matchedKinds(rule.MatchResources) {
+			kinds[kind] = true
+		}
+
+		for _, operation := range p.EffectiveOperations(rule.Name) {
+			operations[operation] = true
+		}
+
+		if rule.HasMutate() {
+			ruleTypes["mutate"] = true
+		}
+		if rule.HasValidate() {
+			ruleTypes["validate"] = true
+		}
+		if rule.HasGenerate() {
+			ruleTypes["generate"] = true
+			for _, generation := range rule.GenerateTargets() {
+				if generation.Kind != "" {
+					kinds[generation.Kind] = true
+				}
+			}
+		}
+
+		for _, entry := range rule.Context {
+			if entry.ConfigMap != nil || entry.APICall != nil {
+				capabilities.UsesExternalData = true
+			}
+		}
+	}
+
+	capabilities.Kinds = sortedKeys(kinds)
+	capabilities.Operations = sortedKeys(operations)
+	capabilities.RuleTypes = sortedKeys(ruleTypes)
+	capabilities.UsesVariables = len(p.ReferencedVariables()) > 0
+	capabilities.Risk = GetRiskProfile(p).Summary
+	return capabilities
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
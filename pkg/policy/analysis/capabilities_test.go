@@ -0,0 +1,83 @@
+package analysis
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_RiskProfile(t *testing.T) {
+	testcases := []struct {
+		description string
+		spec        []byte
+		profile     RiskProfile
+	}{
+		{
+			description: "mutate only",
+			spec:        []byte(`{"rules":[{"name":"m","mutate":{"patchStrategicMerge":{"metadata":{"labels":{"+(team)":"dev"}}}}}]}`),
+			profile:     RiskProfile{Additive: true, Summary: "additive"},
+		},
+		{
+			description: "mutate and validate",
+			spec:        []byte(`{"rules":[{"name":"m","mutate":{"patchStrategicMerge":{"metadata":{"labels":{"+(team)":"dev"}}}}},{"name":"v","validate":{"pattern":{"metadata":{"labels":{"team":"?*"}}}}}]}`),
+			profile:     RiskProfile{Restrictive: true, Summary: "restrictive"},
+		},
+		{
+			description: "generate only",
+			spec:        []byte(`{"rules":[{"name":"g","generate":{"kind":"ConfigMap","name":"cm","data":{}}}]}`),
+			profile:     RiskProfile{Generative: true, Summary: "generative"},
+		},
+		{
+			description: "validate and generate",
+			spec:        []byte(`{"rules":[{"name":"v","validate":{"deny":{}}},{"name":"g","generate":{"kind":"ConfigMap","name":"cm","data":{}}}]}`),
+			profile:     RiskProfile{Restrictive: true, Generative: true, Summary: "restrictive, generative"},
+		},
+		{
+			description: "generations only",
+			spec:        []byte(`{"rules":[{"name":"g","generations":[{"kind":"ConfigMap","name":"cm","data":{}}]}]}`),
+			profile:     RiskProfile{Generative: true, Summary: "generative"},
+		},
+		{
+			description: "no rules",
+			spec:        []byte(`{}`),
+			profile:     RiskProfile{Summary: "none"},
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy kyverno.ClusterPolicy
+		err := json.Unmarshal(testcase.spec, &policy.Spec)
+		assert.NilError(t, err, testcase.description)
+
+		assert.DeepEqual(t, GetRiskProfile(policy), testcase.profile)
+	}
+}
+
+func Test_Capabilities(t *testing.T) {
+	spec := []byte(`{"rules":[
+		{"name":"add-labels","match":{"resources":{"kinds":["Deployment"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"+(team)":"dev"}}}}},
+		{"name":"check-registry","match":{"any":[{"kinds":["Pod"]}],"operations":["CREATE"]},"context":[{"name":"registries","configMap":{"name":"registries","namespace":"default"}}],"validate":{"deny":{"conditions":[{"key":"{{registries.data.allowed}}","operator":"Equals","value":""}]}}},
+		{"name":"gen-quota","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ResourceQuota","name":"quota","namespace":"{{request.object.metadata.name}}","data":{}}},
+		{"name":"gen-limits","match":{"resources":{"kinds":["Namespace"]}},"generations":[{"kind":"LimitRange","name":"limits","namespace":"{{request.object.metadata.name}}","data":{}}]}
+	]}`)
+
+	var policy kyverno.ClusterPolicy
+	err := json.Unmarshal(spec, &policy.Spec)
+	assert.NilError(t, err)
+
+	capabilities := GetCapabilities(policy)
+	assert.DeepEqual(t, capabilities, Capabilities{
+		Kinds:            []string{"Deployment", "LimitRange", "Namespace", "Pod", "ResourceQuota"},
+		Operations:       []string{"CREATE", "DELETE", "UPDATE"},
+		RuleTypes:        []string{"generate", "mutate", "validate"},
+		UsesVariables:    true,
+		UsesExternalData: true,
+		Risk:             "restrictive, generative",
+	})
+
+	raw, err := json.Marshal(capabilities)
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), `{"kinds":["Deployment","LimitRange","Namespace","Pod","ResourceQuota"],"operations":["CREATE","DELETE","UPDATE"],"ruleTypes":["generate","mutate","validate"],"usesVariables":true,"usesExternalData":true,"risk":"restrictive, generative"}`)
+}
@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+)
+
+// OperationCoverage lists the admission operations applied to a kind by the rules of a policy
+type OperationCoverage struct {
+	// Covered are the operations at least one rule matching the kind applies to
+	Covered []string `json:"covered"`
+
+	// Gaps are the operations among CREATE, UPDATE and DELETE no rule matching the kind
+	// applies to, e.g. resources validated on CREATE but not on UPDATE can drift
+	Gaps []string `json:"gaps,omitempty"`
+}
+
+// coveredOperations are the operations reported by GetOperationCoverage, in order. Missing
+// CONNECT operations are not reported as gaps.
+var coveredOperations = []string{"CREATE", "UPDATE", "DELETE", "CONNECT"}
+
+// GetOperationCoverage returns, for each kind matched by the rules of the policy, the
+// operations covered by the rules and the operations left uncovered. It is an advisory
+// analysis, gaps are not errors.
+func GetOperationCoverage(p kyverno.ClusterPolicy) map[string]OperationCoverage {
+	operations := make(map[string]map[string]bool)
+	for _, rule := range p.Spec.Rules {
+		for _, kind := range matchedKinds(rule.MatchResources) {
+			if operations[kind] == nil {
+				operations[kind] = make(map[string]bool)
+			}
+
+			for _, operation := range p.EffectiveOperations(rule.Name) {
+				operations[kind][operation] = true
+			}
+		}
+	}
+
+	coverage := make(map[string]OperationCoverage, len(operations))
+	for kind, covered := range operations {
+		var kindCoverage OperationCoverage
+		for _, operation := range coveredOperations {
+			if covered[operation] {
+				kindCoverage.Covered = append(kindCoverage.Covered, operation)
+			} else if operation != "CONNECT" {
+				kindCoverage.Gaps = append(kindCoverage.Gaps, operation)
+			}
+		}
+
+		coverage[kind] = kindCoverage
+	}
+
+	return coverage
+}
+
+// matchedKinds returns the kinds of the resource description and of the any and all
+// descriptions of the match block
+func matchedKinds(match kyverno.MatchResources) []string {
+	kinds := append([]string{}, match.Kinds...)
+	for _, description := range match.Any {
+		kinds = append(kinds, description.Kinds...)
+	}
+
+	for _, description := range match.All {
+		kinds = append(kinds, description.Kinds...)
+	}
+
+	return kinds
+}
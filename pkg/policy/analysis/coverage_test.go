@@ -0,0 +1,56 @@
+package analysis
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_OperationCoverage(t *testing.T) {
+	testcases := []struct {
+		description string
+		policy      []byte
+		expected    map[string]OperationCoverage
+	}{
+		{
+			description: "CREATE only",
+			policy:      []byte(`{"spec":{"rules":[{"name":"require-labels","match":{"operations":["CREATE"],"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"labels":{"app":"?*"}}}}}]}}`),
+			expected: map[string]OperationCoverage{
+				"Pod": {Covered: []string{"CREATE"}, Gaps: []string{"UPDATE", "DELETE"}},
+			},
+		},
+		{
+			description: "CREATE and UPDATE",
+			policy: []byte(`{"spec":{"rules":[
+				{"name":"validate-create","match":{"operations":["CREATE"],"resources":{"kinds":["Pod","Service"]}},"validate":{"pattern":{"metadata":{"labels":{"app":"?*"}}}}},
+				{"name":"validate-update","match":{"any":[{"kinds":["Pod"]}],"operations":["UPDATE"]},"validate":{"pattern":{"metadata":{"labels":{"app":"?*"}}}}},
+				{"name":"add-labels","match":{"resources":{"kinds":["Service"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"team":"default"}}}}}
+			]}}`),
+			expected: map[string]OperationCoverage{
+				"Pod":     {Covered: []string{"CREATE", "UPDATE"}, Gaps: []string{"DELETE"}},
+				"Service": {Covered: []string{"CREATE", "UPDATE"}, Gaps: []string{"DELETE"}},
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy kyverno.ClusterPolicy
+		err := json.Unmarshal(testcase.policy, &policy)
+		assert.NilError(t, err, testcase.description)
+
+		assert.DeepEqual(t, GetOperationCoverage(policy), testcase.expected)
+	}
+}
+
+func Test_matchedKinds_DoesNotWriteIntoAny(t *testing.T) {
+	match := kyverno.MatchResources{
+		Any: make([]kyverno.ResourceDescription, 1, 2),
+		All: []kyverno.ResourceDescription{{Kinds: []string{"Service"}}},
+	}
+	match.Any[0].Kinds = []string{"Pod"}
+
+	assert.DeepEqual(t, matchedKinds(match), []string{"Pod", "Service"})
+	assert.Assert(t, match.Any[:2][1].Kinds == nil)
+}
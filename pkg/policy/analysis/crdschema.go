@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+)
+
+// regexAnchor matches the anchored keys of overlays, the first group is the field name
+var regexAnchor = regexp.MustCompile(`^[+=X^]?\((.+)\)$`)
+
+// ValidateAgainstCRDSchema checks the overlay and the strategic merge patch of the mutation
+// only set fields declared in the structural schema of the mutated custom resource, as the
+// API server rejects unknown fields. Fields under x-kubernetes-preserve-unknown-fields are
+// not checked.
+func ValidateAgainstCRDSchema(in kyverno.Mutation, schema *apiextensions.JSONSchemaProps) error {
+	if schema == nil {
+		return nil
+	}
+
+	if in.Overlay != nil {
+		if err := validateSchemaFields(in.Overlay, schema, "overlay", true); err != nil {
+			return err
+		}
+	}
+
+	if in.PatchStrategicMerge != nil {
+		if err := validateSchemaFields(in.PatchStrategicMerge, schema, "patchStrategicMerge", true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateSchemaFields walks the element and returns an error for the first field not
+// declared in the schema. Resource and embedded resource roots implicitly declare
+// apiVersion, kind and metadata.
+func validateSchemaFields(element interface{}, schema *apiextensions.JSONSchemaProps, path string, resource bool) error {
+	if schema == nil || (schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields) {
+		return nil
+	}
+
+	switch typed := element.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			value := typed[key]
+			field := key
+			if groups := regexAnchor.FindStringSubmatch(key); groups != nil {
+				field = groups[1]
+			}
+
+			if strings.HasPrefix(field, "$") {
+				continue
+			}
+
+			if (resource || schema.XEmbeddedResource) && (field == "apiVersion" || field == "kind" || field == "metadata") {
+				continue
+			}
+
+			if property, ok := schema.Properties[field]; ok {
+				if err := validateSchemaFields(value, &property, path+"."+key, false); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if schema.AdditionalProperties != nil && (schema.AdditionalProperties.Allows || schema.AdditionalProperties.Schema != nil) {
+				if err := validateSchemaFields(value, schema.AdditionalProperties.Schema, path+"."+key, false); err != nil {
+					return err
+				}
+				continue
+			}
+
+			return fmt.Errorf("%s.%s: field %s is not declared in the CRD schema", path, key, field)
+		}
+	case []interface{}:
+		if schema.Items == nil {
+			return nil
+		}
+
+		for i, value := range typed {
+			if err := validateSchemaFields(value, schema.Items.Schema, fmt.Sprintf("%s[%d]", path, i), false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
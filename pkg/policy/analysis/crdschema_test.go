@@ -0,0 +1,76 @@
+package analysis
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+)
+
+func Test_ValidateAgainstCRDSchema(t *testing.T) {
+	preserve := true
+	schema := &apiextensions.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensions.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				Properties: map[string]apiextensions.JSONSchemaProps{
+					"replicas": {Type: "integer"},
+					"tags": {
+						Type:                 "object",
+						AdditionalProperties: &apiextensions.JSONSchemaPropsOrBool{Schema: &apiextensions.JSONSchemaProps{Type: "string"}},
+					},
+					"backends": {
+						Type: "array",
+						Items: &apiextensions.JSONSchemaPropsOrArray{Schema: &apiextensions.JSONSchemaProps{
+							Type:       "object",
+							Properties: map[string]apiextensions.JSONSchemaProps{"host": {Type: "string"}},
+						}},
+					},
+					"config": {Type: "object", XPreserveUnknownFields: &preserve},
+				},
+			},
+		},
+	}
+
+	testcases := []struct {
+		description string
+		mutation    []byte
+		err         string
+	}{
+		{
+			description: "declared fields",
+			mutation:    []byte(`{"overlay":{"metadata":{"labels":{"app":"web"}},"spec":{"replicas":2,"tags":{"team":"web"},"backends":[{"(host)":"*","+(host)":"web"}],"config":{"any":{"field":1}}}}}`),
+		},
+		{
+			description: "unknown field",
+			mutation:    []byte(`{"overlay":{"spec":{"replica":2}}}`),
+			err:         "overlay.spec.replica: field replica is not declared in the CRD schema",
+		},
+		{
+			description: "unknown field in list item",
+			mutation:    []byte(`{"patchStrategicMerge":{"spec":{"backends":[{"host":"web","port":80}]}}}`),
+			err:         "patchStrategicMerge.spec.backends[0].port: field port is not declared in the CRD schema",
+		},
+		{
+			description: "unknown anchored field",
+			mutation:    []byte(`{"overlay":{"spec":{"+(paused)":true}}}`),
+			err:         "overlay.spec.+(paused): field paused is not declared in the CRD schema",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var mutation kyverno.Mutation
+		err := json.Unmarshal(testcase.mutation, &mutation)
+		assert.NilError(t, err, testcase.description)
+
+		err = ValidateAgainstCRDSchema(mutation, schema)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package analysis
+
+import (
+	"encoding/json"
+	"reflect"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+)
+
+// PolicyDiff lists the rules changed between two versions of a policy
+type PolicyDiff struct {
+	// AddedRules are the names of the rules only in the new policy
+	AddedRules []string `json:"addedRules,omitempty"`
+
+	// RemovedRules are the names of the rules only in the old policy
+	RemovedRules []string `json:"removedRules,omitempty"`
+
+	// ModifiedRules are the rules in both policies whose blocks differ
+	ModifiedRules []RuleDiff `json:"modifiedRules,omitempty"`
+}
+
+// RuleDiff lists the blocks changed in a rule
+type RuleDiff struct {
+	// Name is the name of the rule
+	Name string `json:"name"`
+
+	// Blocks are the changed blocks: context, match, exclude, preconditions, mutate, validate and generate
+	Blocks []string `json:"blocks"`
+}
+
+// Empty returns true if the policies have the same rules
+func (d PolicyDiff) Empty() bool {
+	return len(d.AddedRules) == 0 && len(d.RemovedRules) == 0 && len(d.ModifiedRules) == 0
+}
+
+// DiffPolicies compares the rules of two versions of a policy by name. Blocks are
+// compared after a JSON round trip, so that empty and missing fields or the order of
+// map keys are not reported as changes. Rules are listed in the order of the policies.
+func DiffPolicies(old, new kyverno.ClusterPolicy) PolicyDiff {
+	var diff PolicyDiff
+	oldRules := make(map[string]kyverno.Rule, len(old.Spec.Rules))
+	for _, rule := range old.Spec.Rules {
+		oldRules[rule.Name] = rule
+	}
+
+	newRules := make(map[string]bool, len(new.Spec.Rules))
+	for _, rule := range new.Spec.Rules {
+		newRules[rule.Name] = true
+		oldRule, ok := oldRules[rule.Name]
+		if !ok {
+			diff.AddedRules = append(diff.AddedRules, rule.Name)
+			continue
+		}
+
+		if blocks := changedBlocks(oldRule, rule); len(blocks) > 0 {
+			diff.ModifiedRules = append(diff.ModifiedRules, RuleDiff{Name: rule.Name, Blocks: blocks})
+		}
+	}
+
+	for _, rule := range old.Spec.Rules {
+		if !newRules[rule.Name] {
+			diff.RemovedRules = append(diff.RemovedRules, rule.Name)
+		}
+	}
+
+	return diff
+}
+
+func changedBlocks(old, new kyverno.Rule) []string {
+	blocks := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"context", old.Context, new.Context},
+		{"match", old.MatchResources, new.MatchResources},
+		{"exclude", old.ExcludeResources, new.ExcludeResources},
+		{"preconditions", old.Conditions, new.Conditions},
+		{"mutate", old.Mutation, new.Mutation},
+		{"validate", old.Validation, new.Validation},
+		{"generate", old.GenerateTargets(), new.GenerateTargets()},
+	}
+
+	var changed []string
+	for _, block := range blocks {
+		if !reflect.DeepEqual(normalizeBlock(block.old), normalizeBlock(block.new)) {
+			changed = append(changed, block.name)
+		}
+	}
+
+	return changed
+}
+
+func normalizeBlock(block interface{}) interface{} {
+	data, err := json.Marshal(block)
+	if err != nil {
+		return block
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return block
+	}
+
+	if list, ok := normalized.([]interface{}); ok && len(list) == 0 {
+		return nil
+	}
+
+	return normalized
+}
@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_DiffPolicies(t *testing.T) {
+	old := []byte(`{"spec":{"rules":[
+		{"name":"require-labels","match":{"resources":{"kinds":["Pod"]}},"validate":{"message":"labels are required","pattern":{"metadata":{"labels":{"app":"?*"}}}}},
+		{"name":"add-default","match":{"resources":{"kinds":["Pod"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"team":"default"}}}}},
+		{"name":"generate-quota","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ResourceQuota","name":"quota","namespace":"{{request.object.metadata.name}}","data":{"spec":{"hard":{"pods":"10"}}}}},
+		{"name":"generate-config","match":{"resources":{"kinds":["Namespace"]}},"generations":[{"kind":"ConfigMap","name":"config","namespace":"{{request.object.metadata.name}}","data":{"data":{"team":"dev"}}}]}
+	]}}`)
+
+	testcases := []struct {
+		description string
+		new         []byte
+		expected    PolicyDiff
+	}{
+		{
+			description: "cosmetic changes",
+			new: []byte(`{"spec":{"rules":[
+				{"name":"require-labels","match":{"resources":{"kinds":["Pod"]}},"preconditions":[],"validate":{"pattern":{"metadata":{"labels":{"app":"?*"}}},"message":"labels are required"}},
+				{"name":"add-default","match":{"resources":{"kinds":["Pod"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"team":"default"}}}}},
+				{"name":"generate-quota","match":{"resources":{"kinds":["Namespace"]}},"generate":{"namespace":"{{request.object.metadata.name}}","name":"quota","kind":"ResourceQuota","data":{"spec":{"hard":{"pods":"10"}}}}},
+				{"name":"generate-config","match":{"resources":{"kinds":["Namespace"]}},"generations":[{"kind":"ConfigMap","name":"config","namespace":"{{request.object.metadata.name}}","data":{"data":{"team":"dev"}}}]}
+			]}}`),
+		},
+		{
+			description: "added and removed rules",
+			new: []byte(`{"spec":{"rules":[
+				{"name":"require-labels","match":{"resources":{"kinds":["Pod"]}},"validate":{"message":"labels are required","pattern":{"metadata":{"labels":{"app":"?*"}}}}},
+				{"name":"add-default","match":{"resources":{"kinds":["Pod"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"team":"default"}}}}},
+				{"name":"generate-limits","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"LimitRange","name":"limits","namespace":"{{request.object.metadata.name}}","data":{"spec":{}}}},
+				{"name":"generate-config","match":{"resources":{"kinds":["Namespace"]}},"generations":[{"kind":"ConfigMap","name":"config","namespace":"{{request.object.metadata.name}}","data":{"data":{"team":"dev"}}}]}
+			]}}`),
+			expected: PolicyDiff{AddedRules: []string{"generate-limits"}, RemovedRules: []string{"generate-quota"}},
+		},
+		{
+			description: "modified blocks",
+			new: []byte(`{"spec":{"rules":[
+				{"name":"require-labels","match":{"resources":{"kinds":["Pod","Deployment"]}},"validate":{"message":"labels are required","pattern":{"metadata":{"labels":{"app":"?*","team":"?*"}}}}},
+				{"name":"add-default","match":{"resources":{"kinds":["Pod"]}},"exclude":{"resources":{"namespaces":["kube-system"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"team":"default"}}}}},
+				{"name":"generate-quota","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ResourceQuota","name":"quota","namespace":"{{request.object.metadata.name}}","data":{"spec":{"hard":{"pods":"20"}}}}},
+				{"name":"generate-config","match":{"resources":{"kinds":["Namespace"]}},"generations":[{"kind":"ConfigMap","name":"config","namespace":"{{request.object.metadata.name}}","data":{"data":{"team":"ops"}}}]}
+			]}}`),
+			expected: PolicyDiff{ModifiedRules: []RuleDiff{
+				{Name: "require-labels", Blocks: []string{"match", "validate"}},
+				{Name: "add-default", Blocks: []string{"exclude"}},
+				{Name: "generate-quota", Blocks: []string{"generate"}},
+				{Name: "generate-config", Blocks: []string{"generate"}},
+			}},
+		},
+	}
+
+	var oldPolicy kyverno.ClusterPolicy
+	err := json.Unmarshal(old, &oldPolicy)
+	assert.NilError(t, err)
+
+	for _, testcase := range testcases {
+		var newPolicy kyverno.ClusterPolicy
+		err := json.Unmarshal(testcase.new, &newPolicy)
+		assert.NilError(t, err, testcase.description)
+
+		diff := DiffPolicies(oldPolicy, newPolicy)
+		assert.DeepEqual(t, diff, testcase.expected)
+		assert.Equal(t, diff.Empty(), testcase.description == "cosmetic changes", testcase.description)
+	}
+}
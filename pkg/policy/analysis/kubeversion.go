@@ -0,0 +1,41 @@
+package analysis
+
+import (
+	"fmt"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// kubernetesFeatures are the policy features depending on the Kubernetes version, with the
+// first version supporting them
+var kubernetesFeatures = []struct {
+	name       string
+	minVersion string
+	used       func(p kyverno.ClusterPolicy) bool
+}{
+	{"spec.webhookScope", "1.14", func(p kyverno.ClusterPolicy) bool { return p.Spec.WebhookScope != "" }},
+	{"spec.webhookTimeoutSeconds", "1.14", func(p kyverno.ClusterPolicy) bool { return p.Spec.WebhookTimeoutSeconds != nil }},
+	{"spec.matchConditions", "1.28", func(p kyverno.ClusterPolicy) bool { return len(p.Spec.MatchConditions) > 0 }},
+}
+
+// ValidateForKubernetesVersion returns an error if the policy uses a feature which is not
+// available in the Kubernetes version, such as "1.27" or "v1.27.3"
+func ValidateForKubernetesVersion(p kyverno.ClusterPolicy, kubernetesVersion string) error {
+	target, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return fmt.Errorf("invalid Kubernetes version %s: %v", kubernetesVersion, err)
+	}
+
+	for _, feature := range kubernetesFeatures {
+		if !feature.used(p) {
+			continue
+		}
+
+		if target.LessThan(version.MustParseGeneric(feature.minVersion)) {
+			return fmt.Errorf("%s requires Kubernetes %s or later, the target version is %s", feature.name, feature.minVersion, kubernetesVersion)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package analysis
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_ValidateForKubernetesVersion(t *testing.T) {
+	testcases := []struct {
+		description string
+		spec        []byte
+		version     string
+		err         string
+	}{
+		{
+			description: "match conditions on an old version",
+			spec:        []byte(`{"matchConditions":[{"name":"exclude-leases","expression":"request.resource.resource != 'leases'"}],"rules":[{"name":"r"}]}`),
+			version:     "v1.27.3",
+			err:         "spec.matchConditions requires Kubernetes 1.28 or later, the target version is v1.27.3",
+		},
+		{
+			description: "match conditions on a recent version",
+			spec:        []byte(`{"matchConditions":[{"name":"exclude-leases","expression":"request.resource.resource != 'leases'"}],"rules":[{"name":"r"}]}`),
+			version:     "1.28",
+		},
+		{
+			description: "no versioned feature",
+			spec:        []byte(`{"rules":[{"name":"r"}]}`),
+			version:     "1.10",
+		},
+		{
+			description: "invalid version",
+			spec:        []byte(`{"rules":[{"name":"r"}]}`),
+			version:     "latest",
+			err:         "invalid Kubernetes version latest: could not parse \"latest\" as version",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy kyverno.ClusterPolicy
+		err := json.Unmarshal(testcase.spec, &policy.Spec)
+		assert.NilError(t, err, testcase.description)
+
+		err = ValidateForKubernetesVersion(policy, testcase.version)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+)
+
+// ValidateNameGlobCoherence returns an error if the exclude name is not a narrowing of the
+// match name, that is some names matching the exclude glob do not match the match glob.
+// Exclusions such as app-prod-* from app-* are expected, while *-prod from app-* is
+// ambiguous as it is unclear which names are meant. Names using variables are not checked.
+func ValidateNameGlobCoherence(r kyverno.Rule) error {
+	match, exclude := r.MatchResources.Name, r.ExcludeResources.Name
+	if match == "" || exclude == "" || strings.Contains(match, "{{") || strings.Contains(exclude, "{{") {
+		return nil
+	}
+
+	if !globContains(match, exclude) {
+		return fmt.Errorf("exclude name %s is not a narrowing of the match name %s, it also selects names the rule does not match", exclude, match)
+	}
+
+	return nil
+}
+
+// globContains returns true if every name matching the inner glob matches the outer glob.
+// Globs use * for any sequence of characters and ? for a single character. In the inner glob,
+// * can only be matched by * and ? by ? or * of the outer glob.
+func globContains(outer, inner string) bool {
+	// contained[i][j] is true if inner[j:] is contained in outer[i:]
+	contained := make([][]bool, len(outer)+1)
+	for i := range contained {
+		contained[i] = make([]bool, len(inner)+1)
+	}
+
+	contained[len(outer)][len(inner)] = true
+	for i := len(outer) - 1; i >= 0; i-- {
+		if outer[i] == '*' {
+			contained[i][len(inner)] = contained[i+1][len(inner)]
+		}
+	}
+
+	for i := len(outer) - 1; i >= 0; i-- {
+		for j := len(inner) - 1; j >= 0; j-- {
+			switch {
+			case outer[i] == '*':
+				contained[i][j] = contained[i+1][j] || contained[i][j+1]
+			case inner[j] == '*':
+				contained[i][j] = false
+			case outer[i] == '?' || outer[i] == inner[j]:
+				contained[i][j] = contained[i+1][j+1]
+			}
+		}
+	}
+
+	return contained[0][0]
+}
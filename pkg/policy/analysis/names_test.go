@@ -0,0 +1,38 @@
+package analysis
+
+import (
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_ValidateNameGlobCoherence(t *testing.T) {
+	testcases := []struct {
+		description string
+		match       string
+		exclude     string
+		expectError bool
+	}{
+		{description: "narrowing", match: "app-*", exclude: "app-prod-*"},
+		{description: "single name", match: "app-*", exclude: "app-prod"},
+		{description: "single character", match: "app-?", exclude: "app-1"},
+		{description: "same glob", match: "*-prod-*", exclude: "*-prod-*"},
+		{description: "no match name", exclude: "*-prod"},
+		{description: "variable", match: "app-*", exclude: "{{request.object.metadata.name}}"},
+		{description: "overlapping suffix", match: "app-*", exclude: "*-prod", expectError: true},
+		{description: "disjoint", match: "app-*", exclude: "db-*", expectError: true},
+		{description: "wider wildcard", match: "app-?", exclude: "app-*", expectError: true},
+	}
+
+	for _, testcase := range testcases {
+		rule := kyverno.Rule{
+			Name:             "r",
+			MatchResources:   kyverno.MatchResources{ResourceDescription: kyverno.ResourceDescription{Kinds: []string{"Pod"}, Name: testcase.match}},
+			ExcludeResources: kyverno.ExcludeResources{ResourceDescription: kyverno.ResourceDescription{Name: testcase.exclude}},
+		}
+
+		err := ValidateNameGlobCoherence(rule)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
+)
+
+// validateAnyPatternShadowing returns an error if an anyPattern entry is redundant because
+// another entry is implied by it, so the other entry matches every resource the redundant
+// entry matches. Patterns are compared with their anchors removed, entries using foreach
+// are not compared.
+func validateAnyPatternShadowing(rule kyverno.Rule) (string, error) {
+	if rule.Validation.AnyPattern == nil {
+		return "", nil
+	}
+
+	entries, err := rule.Validation.DeserializeAnyPatternEntries()
+	if err != nil {
+		return "", nil
+	}
+
+	for j, entry := range entries {
+		if len(entry.ForEach) > 0 || entry.Pattern == nil {
+			continue
+		}
+
+		for i, other := range entries {
+			if i == j || len(other.ForEach) > 0 || other.Pattern == nil {
+				continue
+			}
+
+			if !patternImplies(entry.Pattern, other.Pattern) {
+				continue
+			}
+
+			// equal entries imply each other, only the later one is redundant
+			if i > j && patternImplies(other.Pattern, entry.Pattern) {
+				continue
+			}
+
+			return fmt.Sprintf("validate.anyPattern[%d]", j), fmt.Errorf("entry is redundant, anyPattern[%d] matches every resource it matches", i)
+		}
+	}
+
+	return "", nil
+}
+
+// patternImplies returns true if every resource matching the strict pattern also matches
+// the loose pattern, that is every constraint of the loose pattern is found in the strict one
+func patternImplies(strict, loose interface{}) bool {
+	switch looseTyped := loose.(type) {
+	case map[string]interface{}:
+		strictTyped, ok := strict.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		strictFields := make(map[string]interface{}, len(strictTyped))
+		for key, value := range strictTyped {
+			field, _ := commonAnchors.RemoveAnchor(key)
+			strictFields[field] = value
+		}
+
+		for key, value := range looseTyped {
+			field, _ := commonAnchors.RemoveAnchor(key)
+			strictValue, ok := strictFields[field]
+			if !ok || !patternImplies(strictValue, value) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		strictTyped, ok := strict.([]interface{})
+		if !ok || len(strictTyped) != len(looseTyped) {
+			return false
+		}
+
+		for i := range looseTyped {
+			if !patternImplies(strictTyped[i], looseTyped[i]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return reflect.DeepEqual(strict, loose)
+	}
+}
@@ -0,0 +1,46 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateAnyPatternShadowing(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		path        string
+	}{
+		{
+			description: "subset pair",
+			rule:        []byte(`{"name":"r","validate":{"anyPattern":[{"spec":{"containers":[{"securityContext":{"runAsNonRoot":true,"readOnlyRootFilesystem":true}}]}},{"spec":{"containers":[{"securityContext":{"runAsNonRoot":true}}]}}]}}`),
+			path:        "validate.anyPattern[0]",
+		},
+		{
+			description: "duplicate entries",
+			rule:        []byte(`{"name":"r","validate":{"anyPattern":[{"metadata":{"labels":{"app":"?*"}}},{"metadata":{"labels":{"=(app)":"?*"}}}]}}`),
+			path:        "validate.anyPattern[1]",
+		},
+		{
+			description: "independent entries",
+			rule:        []byte(`{"name":"r","validate":{"anyPattern":[{"spec":{"securityContext":{"runAsNonRoot":true}}},{"spec":{"containers":[{"securityContext":{"runAsNonRoot":true}}]}}]}}`),
+		},
+		{
+			description: "different values",
+			rule:        []byte(`{"name":"r","validate":{"anyPattern":[{"metadata":{"labels":{"app":"web","tier":"frontend"}}},{"metadata":{"labels":{"app":"db"}}}]}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateAnyPatternShadowing(rule)
+		assert.Equal(t, err != nil, testcase.path != "", testcase.description)
+		assert.Equal(t, path, testcase.path, testcase.description)
+	}
+}
@@ -35,6 +35,8 @@ func applyPolicy(policy kyverno.ClusterPolicy, resource unstructured.Unstructure
 		logger.V(3).Info("applyPolicy", "resource", name, "processingTime", time.Since(startTime).String())
 	}()
 
+	policy.Spec.Rules = backgroundRules(policy)
+
 	var engineResponses []*response.EngineResponse
 	var engineResponseMutation, engineResponseValidation *response.EngineResponse
 	var err error
@@ -71,6 +73,19 @@ func applyPolicy(policy kyverno.ClusterPolicy, resource unstructured.Unstructure
 	return engineResponses
 }
 
+// backgroundRules returns the rules of the policy that are applied in background scans
+func backgroundRules(policy kyverno.ClusterPolicy) []kyverno.Rule {
+	names := policy.EffectiveBackgroundRules()
+	rules := make([]kyverno.Rule, 0, len(names))
+	for _, rule := range policy.Spec.Rules {
+		if utils.ContainsString(names, rule.Name) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
 func mutation(policy kyverno.ClusterPolicy, resource unstructured.Unstructured, log logr.Logger, resCache resourcecache.ResourceCache, jsonContext *context.Context, namespaceLabels map[string]string) (*response.EngineResponse, error) {
 
 	policyContext := &engine.PolicyContext{
@@ -89,6 +89,17 @@ func ContainsVariablesOtherThanObject(policy kyverno.ClusterPolicy) error {
 			}
 		}
 
+		anyPatternEntries, err := rule.Validation.DeserializeAnyPatternEntries()
+		if err != nil {
+			return fmt.Errorf("failed to deserialize anyPattern, expect array: %s", err.Error())
+		}
+
+		for idx2, entry := range anyPatternEntries {
+			if _, err = variables.SubstituteVars(log.Log, ctx, entry.Message); !checkNotFoundErr(err) {
+				return fmt.Errorf("invalid variable used at spec/rules[%d]/validate/anyPattern[%d]/message: %s", idx, idx2, err.Error())
+			}
+		}
+
 		if _, err = variables.SubstituteVars(log.Log, ctx, rule.Validation.Message); !checkNotFoundErr(err) {
 			return fmt.Errorf("invalid variable used at spec/rules[%d]/validate/message: %s", idx, err.Error())
 		}
@@ -106,30 +117,68 @@ func ContainsVariablesOtherThanObject(policy kyverno.ClusterPolicy) error {
 			}
 		}
 
-		if _, err = variables.SubstituteVars(log.Log, ctx, rule.Generation.Name); !checkNotFoundErr(err) {
-			return fmt.Errorf("invalid variable used at spec/rules[%d]/generate/name: %v", idx, err)
-		}
+		for i, generation := range rule.GenerateTargets() {
+			path := generateTargetPath(rule, i)
+			if _, err = variables.SubstituteVars(log.Log, ctx, generation.Name); !checkNotFoundErr(err) {
+				return fmt.Errorf("invalid variable used at spec/rules[%d]/%s/name: %v", idx, path, err)
+			}
 
-		if _, err = variables.SubstituteVars(log.Log, ctx, rule.Generation.Namespace); !checkNotFoundErr(err) {
-			return fmt.Errorf("invalid variable used at spec/rules[%d]/generate/name: %v", idx, err)
-		}
+			if _, err = variables.SubstituteVars(log.Log, ctx, generation.Namespace); !checkNotFoundErr(err) {
+				return fmt.Errorf("invalid variable used at spec/rules[%d]/%s/name: %v", idx, path, err)
+			}
 
-		if _, err = variables.SubstituteVars(log.Log, ctx, rule.Generation.Data); !checkNotFoundErr(err) {
-			return fmt.Errorf("invalid variable used at spec/rules[%d]/generate/data: %v", idx, err)
-		}
+			if _, err = variables.SubstituteVars(log.Log, ctx, generation.Data); !checkNotFoundErr(err) {
+				return fmt.Errorf("invalid variable used at spec/rules[%d]/%s/data: %v", idx, path, err)
+			}
 
-		if _, err = variables.SubstituteVars(log.Log, ctx, rule.Generation.Clone.Name); !checkNotFoundErr(err) {
-			return fmt.Errorf("invalid variable used at spec/rules[%d]/generate/clone/name: %v", idx, err)
-		}
+			if _, err = variables.SubstituteVars(log.Log, ctx, generation.Clone.Name); !checkNotFoundErr(err) {
+				return fmt.Errorf("invalid variable used at spec/rules[%d]/%s/clone/name: %v", idx, path, err)
+			}
 
-		if _, err = variables.SubstituteVars(log.Log, ctx, rule.Generation.Clone.Namespace); !checkNotFoundErr(err) {
-			return fmt.Errorf("invalid variable used at spec/rules[%d]/generate/clone/namespace: %v", idx, err)
+			if _, err = variables.SubstituteVars(log.Log, ctx, generation.Clone.Namespace); !checkNotFoundErr(err) {
+				return fmt.Errorf("invalid variable used at spec/rules[%d]/%s/clone/namespace: %v", idx, path, err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// validateBackgroundGenerateVariables returns an error if the generate blocks of the rule use
+// variables only set on admission, such as request.userInfo, which are empty when the rule
+// is applied in background scans. Only request.object, request.namespace and the context
+// entries of the rule can be used, as for the other background rules.
+func validateBackgroundGenerateVariables(rule kyverno.Rule) (string, error) {
+	ctx := context.NewContext("request.object", "request.namespace")
+	for _, entry := range rule.Context {
+		ctx.AddBuiltInVars(entry.Name)
+	}
+
+	for i, generation := range rule.GenerateTargets() {
+		path := generateTargetPath(rule, i)
+		fields := []struct {
+			path  string
+			value interface{}
+		}{
+			{"name", generation.Name},
+			{"namespace", generation.Namespace},
+			{"data", generation.Data},
+			{"clone.name", generation.Clone.Name},
+			{"clone.namespace", generation.Clone.Namespace},
+			{"cloneList.namespace", generation.CloneList.Namespace},
+		}
+
+		for _, field := range fields {
+			if _, err := variables.SubstituteVars(log.Log, ctx, field.value); !checkNotFoundErr(err) {
+				return fmt.Sprintf("%s.%s", path, field.path), fmt.Errorf("variable is only set on admission and the rule is applied in background scans, "+
+					"set spec.background=false or skipBackgroundRequests=true: %v", err)
+			}
+		}
+	}
+
+	return "", nil
+}
+
 func checkNotFoundErr(err error) bool {
 	if err != nil {
 		switch err.(type) {
@@ -0,0 +1,42 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateBackgroundGenerateVariables(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		path        string
+	}{
+		{
+			description: "admission-only variable in data",
+			rule:        []byte(`{"name":"r","generate":{"kind":"ConfigMap","name":"owner","namespace":"{{request.object.metadata.name}}","data":{"data":{"creator":"{{request.userInfo.username}}"}}}}`),
+			path:        "generate.data",
+		},
+		{
+			description: "admission-only variable in generations",
+			rule:        []byte(`{"name":"r","generations":[{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.name}}","data":{}},{"kind":"Secret","name":"regcred","namespace":"{{request.object.metadata.name}}","clone":{"namespace":"{{serviceAccountNamespace}}","name":"regcred"}}]}`),
+			path:        "generations[1].clone.namespace",
+		},
+		{
+			description: "request object and context variables",
+			rule:        []byte(`{"name":"r","context":[{"name":"settings","configMap":{"name":"settings","namespace":"kyverno"}}],"generate":{"kind":"ConfigMap","name":"cm","namespace":"{{request.namespace}}","data":{"data":{"app":"{{request.object.metadata.labels.app}}","team":"{{settings.data.team}}"}}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateBackgroundGenerateVariables(rule)
+		assert.Equal(t, err != nil, testcase.path != "", testcase.description)
+		assert.Equal(t, path, testcase.path, testcase.description)
+	}
+}
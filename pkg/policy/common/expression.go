@@ -0,0 +1,54 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PrecheckExpressionSyntax returns an error if the CEL expression is empty, has unterminated
+// string literals, unbalanced brackets or ends with an operator. It is a syntax pre-check, the
+// expression is not compiled, so other syntax errors, type errors and unknown identifiers are
+// only reported by the API server.
+func PrecheckExpressionSyntax(expression string) error {
+	trimmed := strings.TrimSpace(expression)
+	if trimmed == "" {
+		return fmt.Errorf("expression is empty")
+	}
+
+	closing := map[byte]byte{')': '(', ']': '[', '}': '{'}
+	var brackets []byte
+	for i := 0; i < len(expression); i++ {
+		c := expression[i]
+		switch c {
+		case '"', '\'':
+			end := i + 1
+			for ; end < len(expression) && expression[end] != c; end++ {
+				if expression[end] == '\\' {
+					end++
+				}
+			}
+
+			if end >= len(expression) {
+				return fmt.Errorf("unterminated string literal at offset %d", i)
+			}
+			i = end
+		case '(', '[', '{':
+			brackets = append(brackets, c)
+		case ')', ']', '}':
+			if len(brackets) == 0 || brackets[len(brackets)-1] != closing[c] {
+				return fmt.Errorf("unexpected '%c' at offset %d", c, i)
+			}
+			brackets = brackets[:len(brackets)-1]
+		}
+	}
+
+	if len(brackets) > 0 {
+		return fmt.Errorf("missing closing bracket for '%c'", brackets[len(brackets)-1])
+	}
+
+	if strings.ContainsAny(trimmed[len(trimmed)-1:], "&|=!<>+-*/%.,?:") {
+		return fmt.Errorf("expression ends with operator '%s'", trimmed[len(trimmed)-1:])
+	}
+
+	return nil
+}
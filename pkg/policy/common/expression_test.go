@@ -0,0 +1,32 @@
+package common
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_PrecheckExpressionSyntax(t *testing.T) {
+	testcases := []struct {
+		expression string
+		err        string
+	}{
+		{expression: "object.spec.replicas <= 5 && object.metadata.name.startsWith('web')"},
+		{expression: "object.metadata.labels['app'] == \"nginx\""},
+		{expression: "", err: "expression is empty"},
+		{expression: "  \t", err: "expression is empty"},
+		{expression: "has(object.spec", err: "missing closing bracket for '('"},
+		{expression: "object.spec)", err: "unexpected ')' at offset 11"},
+		{expression: "object.metadata.name == 'web", err: "unterminated string literal at offset 24"},
+		{expression: "object.spec.replicas <= ", err: "expression ends with operator '='"},
+	}
+
+	for _, testcase := range testcases {
+		err := PrecheckExpressionSyntax(testcase.expression)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.expression)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.expression)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/kyverno/kyverno/pkg/engine/operator"
+	"github.com/kyverno/kyverno/pkg/engine/validate"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// PatternOperatorValidator validates the value following the prefix of a pattern operator
+type PatternOperatorValidator func(value string) error
+
+// operatorPosition is where an operator is written in a string pattern value
+type operatorPosition int
+
+const (
+	// valuePrefix operators prefix the whole value, such as regex:
+	valuePrefix operatorPosition = iota
+	// conditionPrefix operators prefix each condition of the values joined with '|' and '&', such as >=
+	conditionPrefix
+	// conditionInfix operators are written anywhere in a condition, such as the wildcard *
+	conditionInfix
+)
+
+// families of the condition operators, the family of the operators prefixing the whole value
+// is their prefix without the trailing colon, such as regex
+const (
+	comparisonFamily = "comparison"
+	wildcardFamily   = "wildcard"
+)
+
+// patternOperator is a registered operator of string pattern values
+type patternOperator struct {
+	position operatorPosition
+	family   string
+	// validator validates the operand following the operator, if any
+	validator PatternOperatorValidator
+}
+
+var (
+	patternOperatorsMu sync.RWMutex
+	patternOperators   = map[string]patternOperator{}
+)
+
+func init() {
+	RegisterPatternOperator(validate.RegexPrefix, func(value string) error {
+		_, err := regexp.Compile(value)
+		return err
+	})
+
+	// comparisons are joined with '&' to check a value is in a range
+	for _, op := range []operator.Operator{operator.MoreEqual, operator.LessEqual, operator.More, operator.Less} {
+		registerPatternOperator(string(op), patternOperator{position: conditionPrefix, family: comparisonFamily, validator: validateQuantityOperand})
+	}
+
+	for _, wildcard := range []string{"*", "?"} {
+		registerPatternOperator(wildcard, patternOperator{position: conditionInfix, family: wildcardFamily})
+	}
+}
+
+// RegisterPatternOperator registers the validator of the string pattern values starting with
+// prefix, such as "regex:". Values of validate rule patterns using the operator are validated
+// when policies are loaded. Registering a prefix again replaces its validator.
+func RegisterPatternOperator(prefix string, validator PatternOperatorValidator) {
+	registerPatternOperator(prefix, patternOperator{position: valuePrefix, family: strings.TrimSuffix(prefix, ":"), validator: validator})
+}
+
+func registerPatternOperator(token string, op patternOperator) {
+	patternOperatorsMu.Lock()
+	defer patternOperatorsMu.Unlock()
+
+	patternOperators[token] = op
+}
+
+// lookupPrefixOperator returns the longest operator registered at the position that s starts with
+func lookupPrefixOperator(s string, position operatorPosition) (string, patternOperator, bool) {
+	patternOperatorsMu.RLock()
+	defer patternOperatorsMu.RUnlock()
+
+	var token string
+	for registered, op := range patternOperators {
+		if op.position == position && strings.HasPrefix(s, registered) && len(registered) > len(token) {
+			token = registered
+		}
+	}
+
+	op, ok := patternOperators[token]
+	return token, op, ok && token != ""
+}
+
+// containsInfixOperator returns true if the condition contains an infix operator of the family
+func containsInfixOperator(condition, family string) bool {
+	patternOperatorsMu.RLock()
+	defer patternOperatorsMu.RUnlock()
+
+	for registered, op := range patternOperators {
+		if op.position == conditionInfix && op.family == family && strings.Contains(condition, registered) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasPatternOperator checks if the string pattern value starts with a registered operator
+// applying to the whole value, such as "regex:"
+func HasPatternOperator(value string) bool {
+	_, _, ok := lookupPrefixOperator(value, valuePrefix)
+	return ok
+}
+
+// validatePatternOperator validates the string pattern value with the operator registered for
+// the longest prefix it starts with, if any
+func validatePatternOperator(value string) error {
+	prefix, op, ok := lookupPrefixOperator(value, valuePrefix)
+	if !ok || op.validator == nil {
+		return nil
+	}
+
+	if err := op.validator(strings.TrimPrefix(value, prefix)); err != nil {
+		return fmt.Errorf("Invalid %s operator value %s: %v", op.family, value, err)
+	}
+
+	return nil
+}
+
+// validateQuantityOperand returns an error if the operand of a comparison is neither a number
+// nor a quantity
+func validateQuantityOperand(operand string) error {
+	if _, err := resource.ParseQuantity(operand); err != nil {
+		return fmt.Errorf("expects a number or a quantity such as 500m or 2Gi")
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
+	"gotest.tools/assert"
+)
+
+func Test_RegisterPatternOperator(t *testing.T) {
+	RegisterPatternOperator("cidr:", func(value string) error {
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return fmt.Errorf("not a CIDR")
+		}
+		return nil
+	})
+
+	testcases := []struct {
+		value string
+		err   string
+	}{
+		{value: "cidr:10.0.0.0/8"},
+		{value: "cidr:10.0.0.0", err: "Invalid cidr operator value cidr:10.0.0.0: not a CIDR"},
+		{value: "10.0.0.0"},
+		{value: "regex:^10\\."},
+		{value: "regex:(", err: "Invalid regex operator value regex:(: error parsing regexp: missing closing ): `(`"},
+	}
+
+	for _, testcase := range testcases {
+		pattern := map[string]interface{}{"spec": map[string]interface{}{"clusterIP": testcase.value}}
+		path, err := ValidateValidationPattern(pattern, "/", []commonAnchors.IsAnchor{}, DefaultMaxDepth)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.value)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.value)
+			assert.Equal(t, path, "//spec/clusterIP", testcase.value)
+		}
+	}
+}
+
+func Test_lookupPrefixOperator(t *testing.T) {
+	testcases := []struct {
+		value    string
+		position operatorPosition
+		token    string
+		family   string
+	}{
+		{value: "regex:^a", position: valuePrefix, token: "regex:", family: "regex"},
+		{value: ">=2Gi", position: conditionPrefix, token: ">=", family: "comparison"},
+		{value: "<5", position: conditionPrefix, token: "<", family: "comparison"},
+		{value: "nginx:*", position: conditionPrefix},
+		{value: ">=2Gi", position: valuePrefix},
+	}
+
+	for _, testcase := range testcases {
+		token, op, ok := lookupPrefixOperator(testcase.value, testcase.position)
+		assert.Equal(t, ok, testcase.token != "", testcase.value)
+		assert.Equal(t, token, testcase.token, testcase.value)
+		assert.Equal(t, op.family, testcase.family, testcase.value)
+	}
+
+	assert.Assert(t, containsInfixOperator("nginx:*", wildcardFamily))
+	assert.Assert(t, !containsInfixOperator("nginx:1.19", wildcardFamily))
+}
@@ -0,0 +1,23 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateJSONPointer checks the value is a JSON pointer (RFC 6901) to a field
+func ValidateJSONPointer(pointer string) error {
+	if !strings.HasPrefix(pointer, "/") {
+		return fmt.Errorf("invalid JSON pointer %q: must start with '/'", pointer)
+	}
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		for i := 0; i < len(token); i++ {
+			if token[i] == '~' && (i+1 == len(token) || (token[i+1] != '0' && token[i+1] != '1')) {
+				return fmt.Errorf("invalid JSON pointer %q: '~' must be escaped as '~0'", pointer)
+			}
+		}
+	}
+
+	return nil
+}
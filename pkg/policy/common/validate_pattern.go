@@ -4,27 +4,89 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// DefaultMaxDepth is the default limit of nested maps and arrays in a pattern
+const DefaultMaxDepth = 256
+
 //ValidatePattern validates the pattern
 func ValidatePattern(patternElement interface{}, path string, supportedAnchors []commonAnchors.IsAnchor) (string, error) {
+	return ValidatePatternWithDepth(patternElement, path, supportedAnchors, DefaultMaxDepth)
+}
+
+// ValidatePatternWithDepth validates the pattern like ValidatePattern, and returns an error
+// if maps and arrays are nested more than maxDepth levels
+func ValidatePatternWithDepth(patternElement interface{}, path string, supportedAnchors []commonAnchors.IsAnchor, maxDepth int) (string, error) {
+	return validatePattern(patternElement, path, supportedAnchors, 0, maxDepth, false)
+}
+
+// ValidateValidationPattern validates the pattern of a validate rule like ValidatePatternWithDepth,
+// and also checks the syntax of its anchors and the operators of its string values. Validate
+// rules support all anchors and operators, the keys and values of overlays and generated
+// resources which look like them can be literals.
+func ValidateValidationPattern(patternElement interface{}, path string, supportedAnchors []commonAnchors.IsAnchor, maxDepth int) (string, error) {
+	return validatePattern(patternElement, path, supportedAnchors, 0, maxDepth, true)
+}
+
+func validatePattern(patternElement interface{}, path string, supportedAnchors []commonAnchors.IsAnchor, depth, maxDepth int, validation bool) (string, error) {
 	switch typedPatternElement := patternElement.(type) {
 	case map[string]interface{}:
-		return validateMap(typedPatternElement, path, supportedAnchors)
+		if depth >= maxDepth {
+			return path, fmt.Errorf("pattern exceeds the maximum depth of %d", maxDepth)
+		}
+		return validateMap(typedPatternElement, path, supportedAnchors, depth+1, maxDepth, validation)
 	case []interface{}:
-		return validateArray(typedPatternElement, path, supportedAnchors)
-	case string, float64, int, int64, bool, nil:
+		if depth >= maxDepth {
+			return path, fmt.Errorf("pattern exceeds the maximum depth of %d", maxDepth)
+		}
+		return validateArray(typedPatternElement, path, supportedAnchors, depth+1, maxDepth, validation)
+	case string:
+		if !validation {
+			return "", nil
+		}
+
+		if err := validatePatternOperator(typedPatternElement); err != nil {
+			return path, err
+		}
+
+		if err := validateOperatorFamilies(typedPatternElement); err != nil {
+			return path, err
+		}
+
+		if err := validateWildcardComparisons(typedPatternElement); err != nil {
+			return path, err
+		}
+
+		if err := validateConditionOperands(typedPatternElement); err != nil {
+			return path, err
+		}
+		return "", nil
+	case float64, int, int64, bool, nil:
 		//TODO? check operator
 		return "", nil
 	default:
 		return path, fmt.Errorf("Validation rule failed at '%s', pattern contains unknown type", path)
 	}
 }
-func validateMap(patternMap map[string]interface{}, path string, supportedAnchors []commonAnchors.IsAnchor) (string, error) {
+func validateMap(patternMap map[string]interface{}, path string, supportedAnchors []commonAnchors.IsAnchor, depth, maxDepth int, validation bool) (string, error) {
 	// check if anchors are defined
 	for key, value := range patternMap {
+		if validation {
+			if err := validateAnchorSyntax(key); err != nil {
+				return path + "/" + key, err
+			}
+		}
+
+		if err := validateIndexKey(key, value); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: %s/%s: %v", path, key, err))
+		}
+
 		// if key is anchor
 		// check regex () -> this is anchor
 		// ()
@@ -57,24 +119,258 @@ func validateMap(patternMap map[string]interface{}, path string, supportedAnchor
 			}
 		}
 		// lets validate the values now :)
-		if errPath, err := ValidatePattern(value, path+"/"+key, supportedAnchors); err != nil {
+		if errPath, err := validatePattern(value, path+"/"+key, supportedAnchors, depth, maxDepth, validation); err != nil {
 			return errPath, err
 		}
 	}
 	return "", nil
 }
 
-func validateArray(patternArray []interface{}, path string, supportedAnchors []commonAnchors.IsAnchor) (string, error) {
+func validateArray(patternArray []interface{}, path string, supportedAnchors []commonAnchors.IsAnchor, depth, maxDepth int, validation bool) (string, error) {
+	if err := validateElementKinds(patternArray); err != nil {
+		log.Log.V(1).Info(fmt.Sprintf("warning: path: %s: %v", path, err))
+	}
+
 	for i, patternElement := range patternArray {
 		currentPath := path + strconv.Itoa(i) + "/"
 		// lets validate the values now :)
-		if errPath, err := ValidatePattern(patternElement, currentPath, supportedAnchors); err != nil {
+		if errPath, err := validatePattern(patternElement, currentPath, supportedAnchors, depth, maxDepth, validation); err != nil {
 			return errPath, err
 		}
 	}
 	return "", nil
 }
 
+var regexAnchorKey = regexp.MustCompile(`^.?\((.+)\)$`)
+
+// validateIndexKey returns an error if the map key is a number with a map value, which looks
+// like an attempt to match an element of an array by its index. Keys of pattern maps match
+// the fields of resource maps, elements of resource arrays are matched with pattern arrays.
+func validateIndexKey(key string, value interface{}) error {
+	if _, ok := value.(map[string]interface{}); !ok {
+		return nil
+	}
+
+	if groups := regexAnchorKey.FindStringSubmatch(key); groups != nil {
+		key = groups[1]
+	}
+
+	if _, err := strconv.Atoi(key); err != nil {
+		return nil
+	}
+
+	return fmt.Errorf("key %s is a field name, array elements cannot be selected by index, use an array pattern instead", key)
+}
+
+// validateElementKinds returns an error if the array mixes maps, arrays and scalar values.
+// Each element of a pattern array is matched against the elements of the resource array,
+// which have the same kind, so the elements of a different kind never match.
+func validateElementKinds(patternArray []interface{}) error {
+	kinds := make(map[string]bool)
+	var found []string
+	for _, element := range patternArray {
+		var kind string
+		switch element.(type) {
+		case map[string]interface{}:
+			kind = "map"
+		case []interface{}:
+			kind = "array"
+		case nil:
+			continue
+		default:
+			kind = "scalar"
+		}
+
+		if !kinds[kind] {
+			kinds[kind] = true
+			found = append(found, kind)
+		}
+	}
+
+	if len(found) > 1 {
+		return fmt.Errorf("array elements have different kinds %s, the elements of a resource array have a single kind", strings.Join(found, ", "))
+	}
+
+	return nil
+}
+
+// validateConditionOperands validates the operands of the registered operators prefixing the
+// conditions of the pattern value, such as the numbers or quantities compared with >= or <.
+// The conditions of the value are split on '|' and '&' as done by the engine. Operands using
+// variables or references are resolved at runtime and are not checked.
+func validateConditionOperands(value string) error {
+	if _, _, ok := lookupPrefixOperator(value, valuePrefix); ok {
+		return nil
+	}
+
+	for _, or := range strings.Split(value, "|") {
+		for _, condition := range strings.Split(or, "&") {
+			condition = strings.Trim(condition, " ")
+			token, op, ok := lookupPrefixOperator(condition, conditionPrefix)
+			// the engine reads single characters as literals
+			if !ok || op.validator == nil || len(condition) < 2 {
+				continue
+			}
+
+			operand := strings.TrimSpace(condition[len(token):])
+			if strings.Contains(operand, "{{") || strings.Contains(operand, "$(") {
+				continue
+			}
+
+			if err := op.validator(operand); err != nil {
+				return fmt.Errorf("Invalid operand %q in %s: operator %s %v", operand, value, token, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+var regexPatternVariable = regexp.MustCompile(`{{[^{}]*}}|\$\([^()]*\)`)
+
+// validateWildcardComparisons returns an error if a condition of the pattern value combines
+// the wildcards * or ? with one of the >, >=, < and <= operators, such as *-prod > 5.
+// Comparisons only apply to numbers and quantities, while wildcards only match strings.
+// Wildcards joined with '|' or '&' remain valid, variables and references are not checked.
+func validateWildcardComparisons(value string) error {
+	if _, _, ok := lookupPrefixOperator(value, valuePrefix); ok {
+		return nil
+	}
+
+	for _, or := range strings.Split(regexPatternVariable.ReplaceAllString(value, ""), "|") {
+		for _, condition := range strings.Split(or, "&") {
+			condition = strings.TrimSpace(condition)
+			if !containsInfixOperator(condition, wildcardFamily) {
+				continue
+			}
+
+			if token := comparisonOperator(condition); token != "" {
+				return fmt.Errorf("Invalid pattern %s: wildcard value %s cannot be combined with the comparison operator %s", value, condition, token)
+			}
+		}
+	}
+
+	return nil
+}
+
+// comparisonOperator returns the first comparison operator starting a word of the condition
+// and followed by an operand, if any
+func comparisonOperator(condition string) string {
+	words := strings.Fields(condition)
+	for i, word := range words {
+		token, op, ok := lookupPrefixOperator(word, conditionPrefix)
+		if !ok || op.family != comparisonFamily {
+			continue
+		}
+
+		if len(word) > len(token) || i < len(words)-1 {
+			return token
+		}
+	}
+
+	return ""
+}
+
+// operatorFamilyOrder is the order in which the families of a pattern value are reported
+var operatorFamilyOrder = []string{"alternation", "range", "single-comparison", "duration", "quantity"}
+
+// incompatibleOperatorFamilies are the pairs of families which cannot be used in the same
+// pattern value: comparisons only compare a range when joined with '&', and durations and
+// quantities cannot be compared with each other
+var incompatibleOperatorFamilies = [][2]string{
+	{"alternation", "range"},
+	{"alternation", "single-comparison"},
+	{"duration", "quantity"},
+}
+
+// validateOperatorFamilies returns an error if the pattern value combines operator families
+// which are incompatible, such as >5 | <3
+func validateOperatorFamilies(value string) error {
+	families := operatorFamilies(value)
+	found := make(map[string]bool, len(families))
+	for _, family := range families {
+		found[family] = true
+	}
+
+	for _, pair := range incompatibleOperatorFamilies {
+		if found[pair[0]] && found[pair[1]] {
+			return fmt.Errorf("Invalid pattern %s: operator families %s cannot be combined", value, strings.Join(families, ", "))
+		}
+	}
+
+	return nil
+}
+
+// operatorFamilies returns the families of the operators of the pattern value: the family of
+// the registered operator prefixing the whole value, such as regex, or alternation for values
+// joined with '|', single-comparison or range for one or several comparisons joined with '&',
+// and duration or quantity for the operands of the comparisons
+func operatorFamilies(value string) []string {
+	if _, op, ok := lookupPrefixOperator(value, valuePrefix); ok {
+		return []string{op.family}
+	}
+
+	found := make(map[string]bool)
+	ors := strings.Split(value, "|")
+	if len(ors) > 1 {
+		found["alternation"] = true
+	}
+
+	for _, or := range ors {
+		comparisons := 0
+		for _, condition := range strings.Split(or, "&") {
+			condition = strings.Trim(condition, " ")
+			token, op, ok := lookupPrefixOperator(condition, conditionPrefix)
+			if !ok || op.family != comparisonFamily || len(condition) < 2 {
+				continue
+			}
+
+			comparisons++
+			if family := operandFamily(strings.TrimSpace(condition[len(token):])); family != "" {
+				found[family] = true
+			}
+		}
+
+		if comparisons == 1 {
+			found["single-comparison"] = true
+		} else if comparisons > 1 {
+			found["range"] = true
+		}
+	}
+
+	var families []string
+	for _, family := range operatorFamilyOrder {
+		if found[family] {
+			families = append(families, family)
+		}
+	}
+
+	return families
+}
+
+// operandFamily returns duration or quantity if the operand is only valid as one of them.
+// Numbers, ambiguous operands such as 5m and operands using variables have no family.
+func operandFamily(operand string) string {
+	if strings.Contains(operand, "{{") || strings.Contains(operand, "$(") {
+		return ""
+	}
+
+	if _, err := strconv.ParseFloat(operand, 64); err == nil {
+		return ""
+	}
+
+	_, quantityErr := resource.ParseQuantity(operand)
+	_, durationErr := time.ParseDuration(operand)
+	switch {
+	case durationErr == nil && quantityErr != nil:
+		return "duration"
+	case quantityErr == nil && durationErr != nil:
+		return "quantity"
+	}
+
+	return ""
+}
+
 func checkAnchors(key string, supportedAnchors []commonAnchors.IsAnchor) bool {
 	for _, f := range supportedAnchors {
 		if f(key) {
@@ -83,3 +379,48 @@ func checkAnchors(key string, supportedAnchors []commonAnchors.IsAnchor) bool {
 	}
 	return false
 }
+
+// anchorMarkers are the prefixes allowed before the opening parenthesis of an anchor
+var anchorMarkers = map[string]bool{
+	"":  true, // condition anchor
+	"^": true, // existence anchor
+	"=": true, // equality anchor
+	"X": true, // negation anchor
+	"+": true, // adding anchor
+}
+
+// validateAnchorSyntax returns an error if a key that looks like an anchor is malformed,
+// i.e. the parentheses are not balanced, no field is wrapped or the anchor marker is unknown
+func validateAnchorSyntax(key string) error {
+	open := strings.Index(key, "(")
+	if open != 0 && open != 1 && !strings.HasSuffix(key, ")") {
+		return nil
+	}
+
+	if open == -1 {
+		return fmt.Errorf("Malformed anchor %s: missing opening parenthesis", key)
+	}
+
+	if open > 1 {
+		return fmt.Errorf("Malformed anchor %s: anchor marker must be a single character", key)
+	}
+
+	if marker := key[:open]; !anchorMarkers[marker] {
+		return fmt.Errorf("Malformed anchor %s: unknown anchor marker '%s'", key, marker)
+	}
+
+	if !strings.HasSuffix(key, ")") {
+		return fmt.Errorf("Malformed anchor %s: missing closing parenthesis", key)
+	}
+
+	inner := key[open+1 : len(key)-1]
+	if inner == "" {
+		return fmt.Errorf("Malformed anchor %s: no field specified", key)
+	}
+
+	if strings.ContainsAny(inner, "()") {
+		return fmt.Errorf("Malformed anchor %s: unbalanced parentheses", key)
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
+	"gotest.tools/assert"
+)
+
+func Test_validateAnchorSyntax(t *testing.T) {
+	testcases := []struct {
+		key         string
+		expectError bool
+	}{
+		{key: "name", expectError: false},
+		{key: "(name)", expectError: false},
+		{key: "^(containers)", expectError: false},
+		{key: "=(image)", expectError: false},
+		{key: "X(hostPath)", expectError: false},
+		{key: "+(memory)", expectError: false},
+		{key: "^(name", expectError: true},
+		{key: "=(name))", expectError: true},
+		{key: "((name)", expectError: true},
+		{key: "name)", expectError: true},
+		{key: "()", expectError: true},
+		{key: "?(name)", expectError: true},
+		{key: "ab(name)", expectError: true},
+	}
+
+	for _, testcase := range testcases {
+		err := validateAnchorSyntax(testcase.key)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.key)
+	}
+}
+
+func Test_ValidatePattern_MalformedAnchor(t *testing.T) {
+	pattern := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"=(image": "*:latest",
+				},
+			},
+		},
+	}
+
+	path, err := ValidateValidationPattern(pattern, "/", []commonAnchors.IsAnchor{commonAnchors.IsEqualityAnchor}, DefaultMaxDepth)
+	assert.Assert(t, err != nil)
+	assert.Equal(t, path, "//spec/containers0//=(image")
+}
+
+func Test_ValidatePattern_Regex(t *testing.T) {
+	testcases := []struct {
+		description  string
+		value        string
+		expectedPath string
+	}{
+		{description: "valid regex", value: `regex:^registry\.corp\.com/.*$`},
+		{description: "invalid regex", value: `regex:^registry\.corp\.com/(.*$`, expectedPath: "//spec/containers0//image"},
+		{description: "non-regex value", value: `^registry\.corp\.com/(.*$`},
+	}
+
+	for _, testcase := range testcases {
+		pattern := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"image": testcase.value},
+				},
+			},
+		}
+
+		path, err := ValidateValidationPattern(pattern, "/", []commonAnchors.IsAnchor{}, DefaultMaxDepth)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+	}
+}
+
+func Test_ValidatePatternWithDepth(t *testing.T) {
+	var pattern interface{} = "value"
+	for i := 0; i < 10; i++ {
+		pattern = map[string]interface{}{"a": []interface{}{pattern}}
+	}
+
+	_, err := ValidatePatternWithDepth(pattern, "/", []commonAnchors.IsAnchor{}, 20)
+	assert.NilError(t, err)
+
+	path, err := ValidatePatternWithDepth(pattern, "/", []commonAnchors.IsAnchor{}, 19)
+	assert.Error(t, err, "pattern exceeds the maximum depth of 19")
+	assert.Equal(t, path, "//a0//a0//a0//a0//a0//a0//a0//a0//a0//a")
+
+	for i := 0; i < DefaultMaxDepth; i++ {
+		pattern = []interface{}{pattern}
+	}
+
+	_, err = ValidatePattern(pattern, "/", []commonAnchors.IsAnchor{})
+	assert.Error(t, err, "pattern exceeds the maximum depth of 256")
+}
+
+func Test_validateConditionOperands(t *testing.T) {
+	testcases := []struct {
+		value       string
+		expectError bool
+	}{
+		{value: "<=2Gi"},
+		{value: ">500m"},
+		{value: ">=1 & <=4"},
+		{value: "<1.5 | >10", expectError: true},
+		{value: "<={{request.object.spec.limit}}"},
+		{value: "!*:latest"},
+		{value: "2Gx"},
+		{value: "<=2Gx", expectError: true},
+		{value: ">=1 & <=four", expectError: true},
+	}
+
+	for _, testcase := range testcases {
+		pattern := map[string]interface{}{"resources": map[string]interface{}{"limits": map[string]interface{}{"memory": testcase.value}}}
+		path, err := ValidateValidationPattern(pattern, "/", []commonAnchors.IsAnchor{}, DefaultMaxDepth)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.value)
+		if testcase.expectError {
+			assert.Equal(t, path, "//resources/limits/memory", testcase.value)
+		}
+	}
+}
+
+func Test_validateElementKinds(t *testing.T) {
+	testcases := []struct {
+		description string
+		array       string
+		expectError bool
+	}{
+		{description: "maps", array: `[{"name":"nginx"},{"name":"sidecar"}]`},
+		{description: "scalars", array: `["NET_ADMIN", 1, true]`},
+		{description: "map and null", array: `[{"name":"nginx"}, null]`},
+		{description: "map and scalar", array: `[{"name":"nginx"}, "sidecar"]`, expectError: true},
+		{description: "array and map", array: `[["a"], {"name":"nginx"}]`, expectError: true},
+	}
+
+	for _, testcase := range testcases {
+		var array []interface{}
+		err := json.Unmarshal([]byte(testcase.array), &array)
+		assert.NilError(t, err, testcase.description)
+
+		err = validateElementKinds(array)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_operatorFamilies(t *testing.T) {
+	testcases := []struct {
+		value    string
+		families []string
+		err      string
+	}{
+		{value: "nginx"},
+		{value: "nginx | apache", families: []string{"alternation"}},
+		{value: ">1 & <5", families: []string{"range"}},
+		{value: ">=3", families: []string{"single-comparison"}},
+		{value: "regex:^(a|b)$", families: []string{"regex"}},
+		{value: ">30s", families: []string{"single-comparison", "duration"}},
+		{value: ">100Mi & <=2Gi", families: []string{"range", "quantity"}},
+		{
+			value:    ">5 | <3",
+			families: []string{"alternation", "single-comparison"},
+			err:      "Invalid pattern >5 | <3: operator families alternation, single-comparison cannot be combined",
+		},
+		{
+			value:    ">1h & <2Gi",
+			families: []string{"range", "duration", "quantity"},
+			err:      "Invalid pattern >1h & <2Gi: operator families range, duration, quantity cannot be combined",
+		},
+	}
+
+	for _, testcase := range testcases {
+		assert.DeepEqual(t, operatorFamilies(testcase.value), testcase.families)
+
+		err := validateOperatorFamilies(testcase.value)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.value)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.value)
+		}
+	}
+}
+
+func Test_validateIndexKey(t *testing.T) {
+	testcases := []struct {
+		description string
+		pattern     string
+		expectError bool
+	}{
+		{description: "numeric key in a map", pattern: `{"0":{"image":"nginx:*"}}`, expectError: true},
+		{description: "anchored numeric key", pattern: `{"=(1)":{"image":"nginx:*"}}`, expectError: true},
+		{description: "numeric key with a scalar value", pattern: `{"8080":"http"}`},
+		{description: "field name", pattern: `{"containers":[{"image":"nginx:*"}]}`},
+	}
+
+	for _, testcase := range testcases {
+		var pattern map[string]interface{}
+		err := json.Unmarshal([]byte(testcase.pattern), &pattern)
+		assert.NilError(t, err, testcase.description)
+
+		for key, value := range pattern {
+			err = validateIndexKey(key, value)
+			assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+		}
+
+		_, err = ValidatePattern(map[string]interface{}{"spec": pattern}, "/", []commonAnchors.IsAnchor{commonAnchors.IsEqualityAnchor})
+		assert.NilError(t, err, testcase.description)
+	}
+}
+
+func Test_validateWildcardComparisons(t *testing.T) {
+	testcases := []struct {
+		value string
+		err   string
+	}{
+		{value: "*-prod | staging-*"},
+		{value: "!*-test & ?*"},
+		{value: ">5 & <10"},
+		{value: "{{ length(request.object.spec.containers) > `1` }}-*"},
+		{value: "regex:^.*>5$"},
+		{value: "*-prod > 5", err: "Invalid pattern *-prod > 5: wildcard value *-prod > 5 cannot be combined with the comparison operator >"},
+		{value: ">=app-* & !*-test", err: "Invalid pattern >=app-* & !*-test: wildcard value >=app-* cannot be combined with the comparison operator >="},
+	}
+
+	for _, testcase := range testcases {
+		pattern := map[string]interface{}{"metadata": map[string]interface{}{"name": testcase.value}}
+		_, err := ValidateValidationPattern(pattern, "/", []commonAnchors.IsAnchor{}, DefaultMaxDepth)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.value)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.value)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"github.com/kyverno/kyverno/pkg/engine"
+	"github.com/kyverno/kyverno/pkg/engine/context"
+	"github.com/kyverno/kyverno/pkg/engine/validate"
+	"github.com/kyverno/kyverno/pkg/engine/variables"
+	"github.com/kyverno/kyverno/pkg/openapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func init() {
+	kyverno.RegisterPolicyCompiler(func(policy *kyverno.ClusterPolicy) (kyverno.PolicyEvaluator, error) {
+		openAPIController, err := compileOpenAPIController()
+		if err != nil {
+			return nil, err
+		}
+
+		return compile(policy, openAPIController)
+	})
+}
+
+var (
+	compileOpenAPIOnce sync.Once
+	compileOpenAPI     *openapi.Controller
+	compileOpenAPIErr  error
+)
+
+// compileOpenAPIController returns the OpenAPI controller validating the policies compiled
+// with ClusterPolicy.Compile, it is created on first use
+func compileOpenAPIController() (*openapi.Controller, error) {
+	compileOpenAPIOnce.Do(func() {
+		compileOpenAPI, compileOpenAPIErr = openapi.NewOpenAPIController()
+	})
+
+	return compileOpenAPI, compileOpenAPIErr
+}
+
+// compiledPolicy is a validated policy whose validate rules are prepared for repeated
+// evaluation: selectors are parsed and patterns are deserialized and compiled once at
+// compile time. Rules which depend on the request are evaluated by the engine.
+type compiledPolicy struct {
+	policy kyverno.ClusterPolicy
+	rules  []compiledRule
+	// enginePolicy holds the rules evaluated by the engine
+	enginePolicy kyverno.ClusterPolicy
+}
+
+type compiledRule struct {
+	name string
+	// engine is set for rules which are not compiled and are evaluated by the engine
+	engine   bool
+	match    compiledFilter
+	exclude  *compiledFilter
+	patterns []compiledPattern
+}
+
+// compiledPattern is a validate pattern, the tree is nil if the pattern is evaluated by the engine
+type compiledPattern struct {
+	pattern interface{}
+	tree    *patternNode
+}
+
+// compiledFilter is a compiled match or exclude block. As in the engine, the resource must
+// satisfy at least one of the any descriptions and all the all descriptions if any or all
+// are set, and the resource description otherwise. A filter without descriptions does not
+// match any resource.
+type compiledFilter struct {
+	resources *compiledResourceDescription
+	any       []compiledResourceDescription
+	all       []compiledResourceDescription
+}
+
+type compiledResourceDescription struct {
+	kinds       []string
+	name        string
+	namespaces  []string
+	annotations map[string]string
+	selector    labels.Selector
+	generation  *engine.GenerationComparison
+}
+
+// compile validates the policy and compiles its validate rules. Only rules with static
+// match criteria are compiled, rules using user information, operations, namespace
+// selectors, wildcards in selectors, context, preconditions, deny conditions or variables
+// depend on the request and are evaluated by the engine. Resources are matched with the
+// matching functions of the engine, so verdicts are the ones of admission.
+func compile(policy *kyverno.ClusterPolicy, openAPIController *openapi.Controller) (*compiledPolicy, error) {
+	if err := Validate(policy, nil, true, openAPIController); err != nil {
+		return nil, err
+	}
+
+	cp := &compiledPolicy{policy: *policy.DeepCopy(), enginePolicy: *policy.DeepCopy()}
+	cp.enginePolicy.Spec.Rules = nil
+	for i, rule := range policy.Spec.Rules {
+		if !rule.HasValidate() {
+			continue
+		}
+
+		compiled, err := compileRule(rule)
+		if err != nil {
+			log.Log.V(4).Info("rule is evaluated by the engine", "policy", policy.Name, "rule", rule.Name, "reason", err.Error())
+			compiled = compiledRule{name: rule.Name, engine: true}
+			cp.enginePolicy.Spec.Rules = append(cp.enginePolicy.Spec.Rules, policy.Spec.Rules[i])
+		}
+
+		cp.rules = append(cp.rules, compiled)
+	}
+
+	return cp, nil
+}
+
+// Evaluate returns the verdicts of the validate rules that match the resource. The rules
+// evaluated by the engine only have access to the resource, use EvaluateRequest for rules
+// depending on the admission request.
+func (cp *compiledPolicy) Evaluate(resource unstructured.Unstructured) []kyverno.RuleVerdict {
+	return cp.EvaluateRequest(&engine.PolicyContext{NewResource: resource})
+}
+
+// EvaluateRequest returns the verdicts of the validate rules that match the resource of the
+// policy context. Compiled rules are evaluated on the resource, the other rules are processed
+// by the engine with the policy context. The path is not set in the verdicts of the engine.
+func (cp *compiledPolicy) EvaluateRequest(policyContext *engine.PolicyContext) []kyverno.RuleVerdict {
+	resource := policyContext.NewResource
+	if engine.ManagedPodResource(cp.policy, resource) {
+		return nil
+	}
+
+	var engineVerdicts map[string]kyverno.RuleVerdict
+	if len(cp.enginePolicy.Spec.Rules) > 0 {
+		engineVerdicts = cp.evaluateWithEngine(*policyContext)
+	}
+
+	var verdicts []kyverno.RuleVerdict
+	for _, rule := range cp.rules {
+		if rule.engine {
+			if verdict, ok := engineVerdicts[rule.name]; ok {
+				verdicts = append(verdicts, verdict)
+			}
+			continue
+		}
+
+		if rule.applies(resource) {
+			verdicts = append(verdicts, rule.evaluate(resource))
+		}
+	}
+
+	return verdicts
+}
+
+// evaluateWithEngine returns the verdicts of the engine for the rules which are not compiled
+func (cp *compiledPolicy) evaluateWithEngine(policyContext engine.PolicyContext) map[string]kyverno.RuleVerdict {
+	policyContext.Policy = *cp.enginePolicy.DeepCopy()
+	if policyContext.JSONContext == nil {
+		policyContext.JSONContext = context.NewContext()
+		if raw, err := policyContext.NewResource.MarshalJSON(); err == nil {
+			if err := policyContext.JSONContext.AddResource(raw); err != nil {
+				log.Log.Error(err, "failed to add the resource to the context", "policy", cp.policy.Name)
+			}
+		}
+	}
+
+	er := engine.Validate(&policyContext)
+	verdicts := make(map[string]kyverno.RuleVerdict, len(er.PolicyResponse.Rules))
+	for _, rule := range er.PolicyResponse.Rules {
+		verdicts[rule.Name] = kyverno.RuleVerdict{Name: rule.Name, Success: rule.Success}
+	}
+
+	return verdicts
+}
+
+// applies checks the resource satisfies the match block and not the exclude block
+func (r compiledRule) applies(resource unstructured.Unstructured) bool {
+	if !r.match.matches(resource) {
+		return false
+	}
+
+	return r.exclude == nil || !r.exclude.matches(resource)
+}
+
+func (r compiledRule) evaluate(resource unstructured.Unstructured) kyverno.RuleVerdict {
+	verdict := kyverno.RuleVerdict{Name: r.name}
+	for _, pattern := range r.patterns {
+		path, err := pattern.validate(resource)
+		if err == nil {
+			verdict.Success = true
+			verdict.Path = ""
+			return verdict
+		}
+
+		if verdict.Path == "" {
+			verdict.Path = path
+		}
+	}
+
+	return verdict
+}
+
+func compileRule(rule kyverno.Rule) (compiledRule, error) {
+	if len(rule.Context) > 0 || len(rule.Conditions) > 0 {
+		return compiledRule{}, fmt.Errorf("context and preconditions are evaluated per request")
+	}
+
+	if rule.Validation.Deny != nil {
+		return compiledRule{}, fmt.Errorf("deny conditions are evaluated per request")
+	}
+
+	if !reflect.DeepEqual(rule.MatchResources.UserInfo, kyverno.UserInfo{}) ||
+		!reflect.DeepEqual(rule.ExcludeResources.UserInfo, kyverno.UserInfo{}) {
+		return compiledRule{}, fmt.Errorf("user information is evaluated per request")
+	}
+
+	if len(rule.MatchResources.Operations) > 0 {
+		return compiledRule{}, fmt.Errorf("operations are evaluated per request")
+	}
+
+	compiled := compiledRule{name: rule.Name}
+	match, err := compileFilter(rule.MatchResources.ResourceDescription, rule.MatchResources.Any, rule.MatchResources.All)
+	if err != nil {
+		return compiledRule{}, fmt.Errorf("match: %v", err)
+	}
+	compiled.match = match
+
+	exclude := rule.ExcludeResources
+	if !reflect.DeepEqual(exclude.ResourceDescription, kyverno.ResourceDescription{}) || len(exclude.Any) > 0 || len(exclude.All) > 0 {
+		compiledExclude, err := compileFilter(exclude.ResourceDescription, exclude.Any, exclude.All)
+		if err != nil {
+			return compiledRule{}, fmt.Errorf("exclude: %v", err)
+		}
+		compiled.exclude = &compiledExclude
+	}
+
+	patterns := []interface{}{rule.Validation.Pattern}
+	if rule.Validation.Pattern == nil {
+		anyPatterns, err := rule.Validation.DeserializeAnyPattern()
+		if err != nil {
+			return compiledRule{}, fmt.Errorf("failed to deserialize anyPattern, expect array: %v", err)
+		}
+		patterns = anyPatterns
+	}
+
+	for _, pattern := range patterns {
+		if hasVariables(pattern) {
+			return compiledRule{}, fmt.Errorf("variables are substituted per request")
+		}
+
+		tree, err := compilePattern(pattern)
+		if err != nil {
+			log.Log.V(4).Info("pattern is evaluated by the engine", "rule", rule.Name, "reason", err.Error())
+		}
+		compiled.patterns = append(compiled.patterns, compiledPattern{pattern: pattern, tree: tree})
+	}
+
+	return compiled, nil
+}
+
+// validate evaluates the pattern on the resource. Patterns which are not compiled are
+// copied, as the engine expands the wildcards of the pattern in place.
+func (p compiledPattern) validate(resource unstructured.Unstructured) (string, error) {
+	if p.tree != nil {
+		return p.tree.validateResource(resource.Object)
+	}
+
+	return validate.ValidateResourceWithPattern(log.Log, resource.Object, runtime.DeepCopyJSONValue(p.pattern))
+}
+
+func compileFilter(rd kyverno.ResourceDescription, anyDescriptions, allDescriptions []kyverno.ResourceDescription) (compiledFilter, error) {
+	var filter compiledFilter
+	if len(anyDescriptions) == 0 && len(allDescriptions) == 0 && !reflect.DeepEqual(rd, kyverno.ResourceDescription{}) {
+		compiled, err := compileResourceDescription(rd)
+		if err != nil {
+			return compiledFilter{}, err
+		}
+		filter.resources = &compiled
+	}
+
+	for i, desc := range anyDescriptions {
+		compiled, err := compileResourceDescription(desc)
+		if err != nil {
+			return compiledFilter{}, fmt.Errorf("any[%d]: %v", i, err)
+		}
+		filter.any = append(filter.any, compiled)
+	}
+
+	for i, desc := range allDescriptions {
+		compiled, err := compileResourceDescription(desc)
+		if err != nil {
+			return compiledFilter{}, fmt.Errorf("all[%d]: %v", i, err)
+		}
+		filter.all = append(filter.all, compiled)
+	}
+
+	return filter, nil
+}
+
+// matches checks the resource against the resource description or the any and all descriptions
+func (f compiledFilter) matches(resource unstructured.Unstructured) bool {
+	if f.resources != nil {
+		return f.resources.matches(resource)
+	}
+
+	if len(f.any) == 0 && len(f.all) == 0 {
+		return false
+	}
+
+	if len(f.any) > 0 {
+		matched := false
+		for _, rd := range f.any {
+			if rd.matches(resource) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	for _, rd := range f.all {
+		if !rd.matches(resource) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func compileResourceDescription(rd kyverno.ResourceDescription) (compiledResourceDescription, error) {
+	if rd.NamespaceSelector != nil {
+		return compiledResourceDescription{}, fmt.Errorf("namespace selector is evaluated per request")
+	}
+
+	compiled := compiledResourceDescription{
+		kinds:       rd.Kinds,
+		name:        rd.Name,
+		namespaces:  rd.Namespaces,
+		annotations: rd.Annotations,
+	}
+
+	if rd.Selector != nil {
+		for k, v := range rd.Selector.MatchLabels {
+			if HasWildcard(k) || HasWildcard(v) {
+				return compiledResourceDescription{}, fmt.Errorf("wildcards in selector are expanded per request")
+			}
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(rd.Selector)
+		if err != nil {
+			return compiledResourceDescription{}, err
+		}
+		compiled.selector = selector
+	}
+
+	if rd.MatchGeneration != "" {
+		comparison, err := engine.ParseMatchGeneration(rd.MatchGeneration)
+		if err != nil {
+			return compiledResourceDescription{}, err
+		}
+		compiled.generation = &comparison
+	}
+
+	return compiled, nil
+}
+
+// matches checks the resource against all the conditions of the description, with the
+// functions used by the engine to match resources
+func (rd compiledResourceDescription) matches(resource unstructured.Unstructured) bool {
+	if len(rd.kinds) > 0 && !engine.CheckKind(rd.kinds, resource.GetKind()) {
+		return false
+	}
+
+	if rd.name != "" && !engine.CheckName(rd.name, resource.GetName()) {
+		return false
+	}
+
+	if len(rd.namespaces) > 0 && !engine.CheckNameSpace(rd.namespaces, resource) {
+		return false
+	}
+
+	if len(rd.annotations) > 0 && !engine.CheckAnnotations(rd.annotations, resource.GetAnnotations()) {
+		return false
+	}
+
+	if rd.selector != nil && !rd.selector.Matches(labels.Set(resource.GetLabels())) {
+		return false
+	}
+
+	if rd.generation != nil && !rd.generation.Matches(resource) {
+		return false
+	}
+
+	return true
+}
+
+// hasVariables checks if any string value or key in the element contains a variable
+func hasVariables(element interface{}) bool {
+	switch typed := element.(type) {
+	case map[string]interface{}:
+		for k, v := range typed {
+			if variables.IsVariable(k) || hasVariables(v) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, v := range typed {
+			if hasVariables(v) {
+				return true
+			}
+		}
+	case string:
+		return variables.IsVariable(typed)
+	}
+
+	return false
+}
@@ -0,0 +1,384 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"github.com/kyverno/kyverno/pkg/engine"
+	"github.com/kyverno/kyverno/pkg/engine/context"
+	"github.com/kyverno/kyverno/pkg/engine/utils"
+	"github.com/kyverno/kyverno/pkg/openapi"
+	"gotest.tools/assert"
+)
+
+var compileTestPolicy = []byte(`
+{
+	"apiVersion": "kyverno.io/v1",
+	"kind": "ClusterPolicy",
+	"metadata": {
+	   "name": "compile-test"
+	},
+	"spec": {
+	   "rules": [
+		  {
+			 "name": "validate-tag",
+			 "match": {
+				"resources": {
+				   "kinds": ["Pod"],
+				   "selector": {
+					  "matchLabels": {
+						 "app": "myapp"
+					  }
+				   }
+				}
+			 },
+			 "exclude": {
+				"resources": {
+				   "namespaces": ["kube-system"]
+				}
+			 },
+			 "validate": {
+				"message": "An image tag is required",
+				"pattern": {
+				   "spec": {
+					  "containers": [
+						 {
+							"image": "*:*"
+						 }
+					  ]
+				   }
+				}
+			 }
+		  },
+		  {
+			 "name": "validate-latest",
+			 "match": {
+				"resources": {
+				   "kinds": ["Pod"]
+				}
+			 },
+			 "validate": {
+				"message": "imagePullPolicy 'Always' required with tag 'latest'",
+				"anyPattern": [
+				   {
+					  "spec": {
+						 "containers": [
+							{
+							   "(image)": "*latest",
+							   "imagePullPolicy": "Always"
+							}
+						 ]
+					  }
+				   },
+				   {
+					  "spec": {
+						 "containers": [
+							{
+							   "imagePullPolicy": "IfNotPresent"
+							}
+						 ]
+					  }
+				   }
+				]
+			 }
+		  }
+	   ]
+	}
+}`)
+
+var compileTestResources = [][]byte{
+	[]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-1","namespace":"default","labels":{"app":"myapp"}},"spec":{"containers":[{"name":"nginx","image":"nginx:latest","imagePullPolicy":"Always"}]}}`),
+	[]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-2","namespace":"default","labels":{"app":"myapp"}},"spec":{"containers":[{"name":"nginx","image":"nginx","imagePullPolicy":"Never"}]}}`),
+	[]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-3","namespace":"kube-system","labels":{"app":"myapp"}},"spec":{"containers":[{"name":"nginx","image":"nginx","imagePullPolicy":"IfNotPresent"}]}}`),
+	[]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-4","namespace":"default"},"spec":{"containers":[{"name":"nginx","image":"nginx:1.19","imagePullPolicy":"IfNotPresent"}]}}`),
+	[]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"default"}}`),
+}
+
+func Test_Compile_EquivalentVerdicts(t *testing.T) {
+	var policy kyverno.ClusterPolicy
+	err := json.Unmarshal(compileTestPolicy, &policy)
+	assert.NilError(t, err)
+
+	compiled, err := policy.Compile()
+	assert.NilError(t, err)
+
+	for _, rawResource := range compileTestResources {
+		resource, err := utils.ConvertToUnstructured(rawResource)
+		assert.NilError(t, err)
+
+		er := engine.Validate(&engine.PolicyContext{Policy: policy, NewResource: *resource, JSONContext: context.NewContext()})
+		verdicts := compiled.Evaluate(*resource)
+
+		assert.Equal(t, len(verdicts), len(er.PolicyResponse.Rules), resource.GetName())
+		for i, verdict := range verdicts {
+			assert.Equal(t, verdict.Name, er.PolicyResponse.Rules[i].Name, resource.GetName())
+			assert.Equal(t, verdict.Success, er.PolicyResponse.Rules[i].Success, resource.GetName())
+		}
+	}
+}
+
+func Test_Compile_AnchoredPatterns(t *testing.T) {
+	rawPolicy := []byte(`
+	{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "compile-anchors"},
+		"spec": {
+			"rules": [
+				{
+					"name": "latest-always-pulled",
+					"match": {"resources": {"kinds": ["Pod"]}},
+					"validate": {"message": "imagePullPolicy must be Always", "pattern": {"spec": {"containers": [{"(image)": "*:latest", "imagePullPolicy": "Always"}]}}}
+				},
+				{
+					"name": "no-host-network",
+					"match": {"resources": {"kinds": ["Pod"]}},
+					"validate": {"message": "hostNetwork is not allowed", "pattern": {"spec": {"=(hostNetwork)": false, "=(volumes)": [{"X(hostPath)": "null"}]}}}
+				},
+				{
+					"name": "some-nginx",
+					"match": {"resources": {"kinds": ["Pod"]}},
+					"validate": {"message": "an nginx container is required", "pattern": {"spec": {"^(containers)": [{"image": "nginx*"}]}}}
+				}
+			]
+		}
+	}`)
+
+	resources := [][]byte{
+		[]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-1","namespace":"default"},"spec":{"containers":[{"name":"nginx","image":"nginx:latest","imagePullPolicy":"Always"}]}}`),
+		[]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-2","namespace":"default"},"spec":{"containers":[{"name":"nginx","image":"nginx:latest","imagePullPolicy":"IfNotPresent"}]}}`),
+		[]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-3","namespace":"default"},"spec":{"hostNetwork":true,"containers":[{"name":"busybox","image":"busybox:1.33"}]}}`),
+		[]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-4","namespace":"default"},"spec":{"containers":[{"name":"nginx","image":"nginx:1.19"}],"volumes":[{"name":"host","hostPath":{"path":"/var"}}]}}`),
+		[]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-5","namespace":"default"},"spec":{"hostNetwork":false,"containers":[{"name":"nginx","image":"nginx:1.19"}],"volumes":[{"name":"data","emptyDir":{}}]}}`),
+	}
+
+	var policy kyverno.ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	openAPIController, _ := openapi.NewOpenAPIController()
+	compiled, err := compile(&policy, openAPIController)
+	assert.NilError(t, err)
+
+	for _, rule := range compiled.rules {
+		assert.Assert(t, !rule.engine, rule.name)
+		for _, pattern := range rule.patterns {
+			assert.Assert(t, pattern.tree != nil, rule.name)
+		}
+	}
+
+	for _, rawResource := range resources {
+		resource, err := utils.ConvertToUnstructured(rawResource)
+		assert.NilError(t, err)
+
+		er := engine.Validate(&engine.PolicyContext{Policy: policy, NewResource: *resource, JSONContext: context.NewContext()})
+		verdicts := compiled.Evaluate(*resource)
+
+		assert.Equal(t, len(verdicts), len(er.PolicyResponse.Rules), resource.GetName())
+		for i, verdict := range verdicts {
+			assert.Equal(t, verdict.Name, er.PolicyResponse.Rules[i].Name, resource.GetName())
+			assert.Equal(t, verdict.Success, er.PolicyResponse.Rules[i].Success, resource.GetName()+"/"+verdict.Name)
+		}
+	}
+}
+
+func Test_Compile_EngineFallback(t *testing.T) {
+	rawPolicy := []byte(`
+	{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "compile-fallback"},
+		"spec": {
+			"rules": [
+				{
+					"name": "team-label",
+					"match": {"resources": {"kinds": ["ConfigMap"]}},
+					"validate": {"message": "team label is required", "pattern": {"metadata": {"labels": {"team": "?*"}}}}
+				},
+				{
+					"name": "owner-matches-name",
+					"match": {"resources": {"kinds": ["ConfigMap"]}},
+					"validate": {"message": "owner must be the name", "pattern": {"metadata": {"labels": {"owner": "{{request.object.metadata.name}}"}}}}
+				},
+				{
+					"name": "deny-default",
+					"match": {"resources": {"kinds": ["ConfigMap"]}},
+					"validate": {"message": "default namespace is not allowed", "deny": {"conditions": [{"key": "{{request.object.metadata.namespace}}", "operator": "Equals", "value": "default"}]}}
+				}
+			]
+		}
+	}`)
+
+	var policy kyverno.ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	openAPIController, _ := openapi.NewOpenAPIController()
+	compiled, err := compile(&policy, openAPIController)
+	assert.NilError(t, err)
+	assert.Equal(t, len(compiled.enginePolicy.Spec.Rules), 2)
+
+	testcases := []struct {
+		resource []byte
+		verdicts []kyverno.RuleVerdict
+	}{
+		{
+			resource: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"team-a","labels":{"team":"a","owner":"cm"}}}`),
+			verdicts: []kyverno.RuleVerdict{{Name: "team-label", Success: true}, {Name: "owner-matches-name", Success: true}, {Name: "deny-default", Success: true}},
+		},
+		{
+			resource: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"default","labels":{"owner":"other"}}}`),
+			verdicts: []kyverno.RuleVerdict{{Name: "team-label", Path: "/metadata/labels/team/"}, {Name: "owner-matches-name"}, {Name: "deny-default"}},
+		},
+	}
+
+	for _, testcase := range testcases {
+		resource, err := utils.ConvertToUnstructured(testcase.resource)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, compiled.Evaluate(*resource), testcase.verdicts)
+	}
+}
+
+func Test_Compile_RejectsDynamicRules(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+	}{
+		{
+			description: "rule with variables",
+			rule:        []byte(`{"name":"check-owner","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"labels":{"owner":"{{request.object.metadata.name}}"}}}}}`),
+		},
+		{
+			description: "rule with preconditions",
+			rule:        []byte(`{"name":"check-owner","match":{"resources":{"kinds":["Pod"]}},"preconditions":[{"key":"{{request.operation}}","operator":"Equals","value":"CREATE"}],"validate":{"pattern":{"metadata":{"labels":{"owner":"?*"}}}}}`),
+		},
+		{
+			description: "rule with operations",
+			rule:        []byte(`{"name":"check-owner","match":{"resources":{"kinds":["Pod"]},"operations":["CREATE"]},"validate":{"pattern":{"metadata":{"labels":{"owner":"?*"}}}}}`),
+		},
+		{
+			description: "rule with wildcards in selector",
+			rule:        []byte(`{"name":"check-owner","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"app":"*"}}}},"validate":{"pattern":{"metadata":{"labels":{"owner":"?*"}}}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err)
+
+		_, err = compileRule(rule)
+		assert.Assert(t, err != nil, testcase.description)
+	}
+}
+
+func Test_Compile_MatchFilters(t *testing.T) {
+	rawPolicy := []byte(`
+	{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "compile-filters"},
+		"spec": {
+			"rules": [
+				{
+					"name": "any-pod-or-deployment",
+					"match": {"any": [{"kinds": ["Pod"]}, {"kinds": ["Deployment"]}]},
+					"exclude": {"resources": {"namespaces": ["prod-system"]}},
+					"validate": {"message": "team label is required", "pattern": {"metadata": {"labels": {"team": "?*"}}}}
+				},
+				{
+					"name": "all-updated-deployments",
+					"match": {"all": [{"kinds": ["Deployment"]}, {"kinds": ["Deployment"], "matchGeneration": "metadata.generation > 1"}]},
+					"exclude": {"all": [{"kinds": ["Deployment"], "name": "legacy-*"}]},
+					"validate": {"message": "owner label is required", "pattern": {"metadata": {"labels": {"owner": "?*"}}}}
+				}
+			]
+		}
+	}`)
+
+	testcases := []struct {
+		resource []byte
+		verdicts map[string]bool
+	}{
+		{
+			resource: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"prod-a"}}`),
+			verdicts: map[string]bool{},
+		},
+		{
+			resource: []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"prod-a","labels":{"team":"a"}}}`),
+			verdicts: map[string]bool{"any-pod-or-deployment": true},
+		},
+		{
+			resource: []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"dev"}}`),
+			verdicts: map[string]bool{"any-pod-or-deployment": false},
+		},
+		{
+			resource: []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"prod-system"}}`),
+			verdicts: map[string]bool{},
+		},
+		{
+			resource: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","namespace":"prod-a","generation":1}}`),
+			verdicts: map[string]bool{"any-pod-or-deployment": false},
+		},
+		{
+			resource: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","namespace":"prod-a","generation":2,"labels":{"team":"a"}}}`),
+			verdicts: map[string]bool{"any-pod-or-deployment": true, "all-updated-deployments": false},
+		},
+		{
+			resource: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"legacy-web","namespace":"prod-a","generation":2}}`),
+			verdicts: map[string]bool{"any-pod-or-deployment": false},
+		},
+	}
+
+	var policy kyverno.ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	compiled, err := policy.Compile()
+	assert.NilError(t, err)
+
+	for _, testcase := range testcases {
+		resource, err := utils.ConvertToUnstructured(testcase.resource)
+		assert.NilError(t, err)
+
+		verdicts := map[string]bool{}
+		for _, verdict := range compiled.Evaluate(*resource) {
+			verdicts[verdict.Name] = verdict.Success
+		}
+		assert.DeepEqual(t, verdicts, testcase.verdicts)
+
+		engineVerdicts := map[string]bool{}
+		er := engine.Validate(&engine.PolicyContext{Policy: policy, NewResource: *resource, JSONContext: context.NewContext()})
+		for _, rule := range er.PolicyResponse.Rules {
+			engineVerdicts[rule.Name] = rule.Success
+		}
+		assert.DeepEqual(t, verdicts, engineVerdicts)
+	}
+}
+
+func Benchmark_Compiled_Evaluate(b *testing.B) {
+	var policy kyverno.ClusterPolicy
+	_ = json.Unmarshal(compileTestPolicy, &policy)
+	compiled, err := policy.Compile()
+	if err != nil {
+		b.Fatal(err)
+	}
+	resource, _ := utils.ConvertToUnstructured(compileTestResources[0])
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compiled.Evaluate(*resource)
+	}
+}
+
+func Benchmark_Engine_Validate(b *testing.B) {
+	var policy kyverno.ClusterPolicy
+	_ = json.Unmarshal(compileTestPolicy, &policy)
+	resource, _ := utils.ConvertToUnstructured(compileTestResources[0])
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.Validate(&engine.PolicyContext{Policy: policy, NewResource: *resource, JSONContext: context.NewContext()})
+	}
+}
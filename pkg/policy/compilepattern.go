@@ -0,0 +1,300 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
+	"github.com/kyverno/kyverno/pkg/engine/validate"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// anchorKind is the anchor of a compiled pattern field
+type anchorKind int
+
+const (
+	noAnchor anchorKind = iota
+	conditionAnchor
+	existenceAnchor
+	equalityAnchor
+	negationAnchor
+)
+
+// errConditionNotSatisfied is returned when a condition anchor does not match the resource,
+// the pattern does not apply to the resource element
+var errConditionNotSatisfied = errors.New("condition anchor did not satisfy")
+
+// patternNode is a node of a pattern tree compiled for repeated evaluation. The anchors of
+// the map keys are parsed and stripped once, and the fields are ordered as done by the
+// engine: anchors are evaluated first, then the fields with nested anchors.
+type patternNode struct {
+	// fields of a map pattern, anchors first
+	fields []patternField
+	// elements of an array pattern
+	elements []*patternNode
+	// value of a scalar pattern
+	value interface{}
+	isMap bool
+	// isArray is set for array patterns, which can be empty
+	isArray bool
+	// trackedAnchors are the condition, existence and negation anchors of a map pattern
+	trackedAnchors []patternField
+}
+
+type patternField struct {
+	// key is the anchor-free key
+	key string
+	// rawKey is the key as written in the pattern
+	rawKey string
+	anchor anchorKind
+	node   *patternNode
+}
+
+// compilePattern compiles the pattern of a validate rule. An error is returned for patterns
+// using features that depend on the evaluated resource, such as references to other pattern
+// values or wildcards in keys, which are evaluated by the engine.
+func compilePattern(pattern interface{}) (*patternNode, error) {
+	switch typed := pattern.(type) {
+	case map[string]interface{}:
+		node := &patternNode{isMap: true}
+		var nested, plain []patternField
+		for rawKey, value := range typed {
+			if strings.ContainsAny(rawKey, "*?") {
+				return nil, fmt.Errorf("wildcards in key %s are expanded per resource", rawKey)
+			}
+
+			child, err := compilePattern(value)
+			if err != nil {
+				return nil, err
+			}
+
+			field := patternField{key: rawKey, rawKey: rawKey, node: child}
+			field.key, _ = commonAnchors.RemoveAnchor(rawKey)
+			switch {
+			case commonAnchors.IsConditionAnchor(rawKey):
+				field.anchor = conditionAnchor
+			case commonAnchors.IsExistenceAnchor(rawKey):
+				field.anchor = existenceAnchor
+			case commonAnchors.IsEqualityAnchor(rawKey):
+				field.anchor = equalityAnchor
+			case commonAnchors.IsNegationAnchor(rawKey):
+				field.anchor = negationAnchor
+			default:
+				field.key = rawKey
+				if child.hasAnchors() {
+					nested = append(nested, field)
+				} else {
+					plain = append(plain, field)
+				}
+				continue
+			}
+
+			if field.anchor == existenceAnchor && (len(child.elements) == 0 || !child.elements[0].isMap) {
+				return nil, fmt.Errorf("existence anchor %s expects a list of maps", rawKey)
+			}
+
+			node.fields = append(node.fields, field)
+			if field.anchor != equalityAnchor {
+				node.trackedAnchors = append(node.trackedAnchors, field)
+			}
+		}
+
+		node.fields = append(append(node.fields, nested...), plain...)
+		return node, nil
+	case []interface{}:
+		node := &patternNode{isArray: true}
+		for _, element := range typed {
+			child, err := compilePattern(element)
+			if err != nil {
+				return nil, err
+			}
+			node.elements = append(node.elements, child)
+		}
+		return node, nil
+	case string:
+		if isPatternReference(typed) {
+			return nil, fmt.Errorf("reference %s is resolved per resource", typed)
+		}
+		return &patternNode{value: typed}, nil
+	case float64, int, int64, bool, nil:
+		return &patternNode{value: typed}, nil
+	default:
+		return nil, fmt.Errorf("pattern contains unknown type %T", pattern)
+	}
+}
+
+func isPatternReference(value string) bool {
+	return strings.HasPrefix(value, "$(") && strings.HasSuffix(value, ")")
+}
+
+// hasAnchors checks if the node or one of its children is a map with anchors
+func (n *patternNode) hasAnchors() bool {
+	for _, field := range n.fields {
+		if field.anchor != noAnchor || field.node.hasAnchors() {
+			return true
+		}
+	}
+
+	for _, element := range n.elements {
+		if element.hasAnchors() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// anchorTracker records if the fields of the condition, existence and negation anchors are
+// found in the resource. As done by the engine, a failure is ignored if one of the anchored
+// fields is never found.
+type anchorTracker map[string]bool
+
+func (t anchorTracker) check(node *patternNode, resource map[string]interface{}) {
+	for _, field := range node.trackedAnchors {
+		if t[field.rawKey] {
+			continue
+		}
+
+		_, ok := resource[field.key]
+		t[field.rawKey] = ok
+	}
+}
+
+func (t anchorTracker) missingAnchor() bool {
+	for _, found := range t {
+		if !found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateResource evaluates the compiled pattern on the resource, and returns the path
+// where the pattern failed. It is equivalent to validate.ValidateResourceWithPattern.
+func (n *patternNode) validateResource(resource interface{}) (string, error) {
+	tracker := anchorTracker{}
+	path, err := n.validate(resource, "/", tracker)
+	if err == nil || err == errConditionNotSatisfied || tracker.missingAnchor() {
+		return "", nil
+	}
+
+	return path, err
+}
+
+func (n *patternNode) validate(resource interface{}, path string, tracker anchorTracker) (string, error) {
+	switch {
+	case n.isMap:
+		resourceMap, ok := resource.(map[string]interface{})
+		if !ok {
+			return path, fmt.Errorf("Pattern and resource have different structures. Path: %s. Expected map[string]interface {}, found %T", path, resource)
+		}
+
+		tracker.check(n, resourceMap)
+		return n.validateMap(resourceMap, path, tracker)
+	case n.isArray:
+		resourceArray, ok := resource.([]interface{})
+		if !ok {
+			return path, fmt.Errorf("Validation rule Failed at path %s, resource does not satisfy the expected overlay pattern", path)
+		}
+
+		return n.validateArray(resourceArray, path, tracker)
+	default:
+		if !validate.ValidateValueWithPattern(log.Log, resource, n.value) {
+			return path, fmt.Errorf("Validation rule failed at '%s' to validate value '%v' with pattern '%v'", path, resource, n.value)
+		}
+		return "", nil
+	}
+}
+
+func (n *patternNode) validateMap(resource map[string]interface{}, path string, tracker anchorTracker) (string, error) {
+	for _, field := range n.fields {
+		currentPath := path + field.key + "/"
+		value, ok := resource[field.key]
+		switch field.anchor {
+		case conditionAnchor:
+			if ok {
+				if _, err := field.node.validate(value, currentPath, tracker); err != nil {
+					return "", errConditionNotSatisfied
+				}
+			}
+		case existenceAnchor:
+			if ok {
+				if errPath, err := field.node.validateExistence(value, currentPath, tracker); err != nil {
+					return errPath, err
+				}
+			}
+		case equalityAnchor:
+			if ok {
+				if errPath, err := field.node.validate(value, currentPath, tracker); err != nil {
+					return errPath, err
+				}
+			}
+		case negationAnchor:
+			if ok {
+				return currentPath, fmt.Errorf("Validation rule failed at %s, field %s is disallowed", currentPath, field.key)
+			}
+		default:
+			if field.node.value == "*" {
+				if value == nil {
+					return path, fmt.Errorf("Validation rule failed at %s, Field %s is not present", path, field.key)
+				}
+				continue
+			}
+
+			if errPath, err := field.node.validate(value, currentPath, tracker); err != nil {
+				return errPath, err
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// validateExistence checks at least one element of the resource list matches the first
+// element of the pattern list
+func (n *patternNode) validateExistence(resource interface{}, path string, tracker anchorTracker) (string, error) {
+	resourceList, ok := resource.([]interface{})
+	if !ok {
+		return path, fmt.Errorf("Invalid resource type %T: Existence ^ () anchor can be used only on list/array type resource", resource)
+	}
+
+	for i, element := range resourceList {
+		if _, err := n.elements[0].validate(element, path+strconv.Itoa(i)+"/", tracker); err == nil {
+			return "", nil
+		}
+	}
+
+	return path, fmt.Errorf("Existence anchor validation failed at path %s", path)
+}
+
+func (n *patternNode) validateArray(resource []interface{}, path string, tracker anchorTracker) (string, error) {
+	if len(n.elements) == 0 {
+		return path, fmt.Errorf("Pattern Array empty")
+	}
+
+	if n.elements[0].isMap {
+		for i, element := range resource {
+			errPath, err := n.elements[0].validate(element, path+strconv.Itoa(i)+"/", tracker)
+			if err != nil && err != errConditionNotSatisfied {
+				return errPath, err
+			}
+		}
+		return "", nil
+	}
+
+	if len(resource) < len(n.elements) {
+		return "", fmt.Errorf("Validate Array failed, array length mismatch, resource Array len is %d and pattern Array len is %d", len(resource), len(n.elements))
+	}
+
+	for i, element := range n.elements {
+		errPath, err := element.validate(resource[i], path+strconv.Itoa(i)+"/", tracker)
+		if err != nil && err != errConditionNotSatisfied {
+			return errPath, err
+		}
+	}
+
+	return "", nil
+}
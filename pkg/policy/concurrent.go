@@ -0,0 +1,84 @@
+package policy
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"github.com/kyverno/kyverno/pkg/openapi"
+)
+
+var regexRulePath = regexp.MustCompile(`^spec\.rules?\[(\d+)\]`)
+
+// ValidatePoliciesConcurrent validates the policies offline, as done by the CLI, with at most
+// workers policies validated at a time. It returns an error result for each invalid policy,
+// in the order of the policies, so results do not depend on the number of workers.
+func ValidatePoliciesConcurrent(policies []kyverno.ClusterPolicy, workers int) []PolicyValidationResult {
+	openAPIController, err := openapi.NewOpenAPIController()
+	if err != nil {
+		results := make([]PolicyValidationResult, 0, len(policies))
+		for _, policy := range policies {
+			results = append(results, PolicyValidationResult{Policy: policy.Name, Severity: SeverityError, Message: err.Error()})
+		}
+		return results
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]*PolicyValidationResult, len(policies))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = validatePolicy(policies[i], openAPIController)
+			}
+		}()
+	}
+
+	for i := range policies {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var invalid []PolicyValidationResult
+	for _, result := range results {
+		if result != nil {
+			invalid = append(invalid, *result)
+		}
+	}
+
+	return invalid
+}
+
+// validatePolicy returns the error result of the policy, or nil if the policy is valid
+func validatePolicy(policy kyverno.ClusterPolicy, openAPIController *openapi.Controller) *PolicyValidationResult {
+	err := Validate(&policy, nil, true, openAPIController)
+	if err == nil {
+		return nil
+	}
+
+	result := &PolicyValidationResult{Policy: policy.Name, Severity: SeverityError, Message: err.Error()}
+
+	// errors are formatted as "path: <path>: <message>"
+	if message := strings.TrimPrefix(result.Message, "path: "); message != result.Message {
+		if i := strings.Index(message, ": "); i >= 0 {
+			result.Path, result.Message = message[:i], message[i+2:]
+		}
+	}
+
+	if groups := regexRulePath.FindStringSubmatch(result.Path); groups != nil {
+		if i, err := strconv.Atoi(groups[1]); err == nil && i < len(policy.Spec.Rules) {
+			result.Rule = policy.Spec.Rules[i].Name
+		}
+	}
+
+	return result
+}
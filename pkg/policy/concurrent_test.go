@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"github.com/kyverno/kyverno/pkg/openapi"
+	"gotest.tools/assert"
+)
+
+func concurrentTestPolicies(t testing.TB, count int) []kyverno.ClusterPolicy {
+	var policies []kyverno.ClusterPolicy
+	for i := 0; i < count; i++ {
+		// every third policy has a duplicate rule name
+		second := "require-team"
+		if i%3 == 0 {
+			second = "require-app"
+		}
+
+		raw := fmt.Sprintf(`{"metadata":{"name":"policy-%d"},"spec":{"rules":[`+
+			`{"name":"require-app","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"labels":{"app":"?*"}}}}},`+
+			`{"name":"%s","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"labels":{"team":"?*"}}}}}]}}`, i, second)
+
+		var policy kyverno.ClusterPolicy
+		err := json.Unmarshal([]byte(raw), &policy)
+		assert.NilError(t, err)
+		policies = append(policies, policy)
+	}
+
+	return policies
+}
+
+func Test_ValidatePoliciesConcurrent(t *testing.T) {
+	policies := concurrentTestPolicies(t, 20)
+
+	openAPIController, err := openapi.NewOpenAPIController()
+	assert.NilError(t, err)
+
+	var serial []PolicyValidationResult
+	for _, policy := range policies {
+		if result := validatePolicy(policy, openAPIController); result != nil {
+			serial = append(serial, *result)
+		}
+	}
+
+	assert.Equal(t, len(serial), 7)
+	assert.Equal(t, serial[0].Policy, "policy-0")
+	assert.Equal(t, serial[0].Rule, "require-app")
+	assert.Equal(t, serial[0].Severity, SeverityError)
+
+	for _, workers := range []int{0, 1, 4, 32} {
+		results := ValidatePoliciesConcurrent(policies, workers)
+		assert.DeepEqual(t, results, serial)
+	}
+}
+
+func Benchmark_ValidatePoliciesConcurrent(b *testing.B) {
+	policies := concurrentTestPolicies(b, 100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ValidatePoliciesConcurrent(policies, 8)
+	}
+}
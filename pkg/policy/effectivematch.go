@@ -0,0 +1,219 @@
+package policy
+
+import (
+	"fmt"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"github.com/minio/minio/pkg/wildcard"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ValidateEffectiveMatch checks that merging the policy level match and exclude blocks
+// with the blocks of each rule leaves every rule with a match that can select resources.
+// It returns the path of the first rule with an empty effective match.
+func ValidateEffectiveMatch(policy kyverno.ClusterPolicy) (string, error) {
+	if policy.Spec.Match != nil && (len(policy.Spec.Match.Any) > 0 || len(policy.Spec.Match.All) > 0) {
+		return "spec.match", fmt.Errorf("any and all are not supported in the policy level match")
+	}
+
+	for i, rule := range policy.Spec.Rules {
+		match, err := EffectiveMatch(policy.Spec.Match, rule)
+		if err != nil {
+			return fmt.Sprintf("spec.rules[%d].match", i), fmt.Errorf("rule %s never matches when merged with spec.match: %v", rule.Name, err)
+		}
+
+		if policy.Spec.Exclude != nil && doMatchAndExcludeConflict(kyverno.Rule{MatchResources: match, ExcludeResources: *policy.Spec.Exclude}) {
+			return fmt.Sprintf("spec.rules[%d].match", i), fmt.Errorf("rule %s never matches as spec.exclude excludes all resources it matches", rule.Name)
+		}
+
+		if policy.Spec.Match != nil && doMatchAndExcludeConflict(kyverno.Rule{MatchResources: match, ExcludeResources: rule.ExcludeResources}) {
+			return fmt.Sprintf("spec.rules[%d].exclude", i), fmt.Errorf("rule %s never matches as its exclude block excludes all resources matched when merged with spec.match", rule.Name)
+		}
+	}
+
+	return "", nil
+}
+
+// EffectiveMatch returns the match of the rule restricted by the policy level match.
+// A resource must satisfy both blocks, so kinds, namespaces and names are intersected
+// and label selectors are combined. An error is returned if the intersection is empty.
+// User info is kept from the rule if set, as it is not a property of the resource.
+func EffectiveMatch(policyMatch *kyverno.MatchResources, rule kyverno.Rule) (kyverno.MatchResources, error) {
+	match := *rule.MatchResources.DeepCopy()
+	if policyMatch == nil {
+		return match, nil
+	}
+
+	if len(match.Roles) == 0 && len(match.ClusterRoles) == 0 && len(match.Subjects) == 0 {
+		match.UserInfo = *policyMatch.UserInfo.DeepCopy()
+	}
+
+	rd, err := mergeResourceDescription(policyMatch.ResourceDescription, match.ResourceDescription)
+	if err != nil {
+		return match, err
+	}
+	match.ResourceDescription = rd
+
+	if len(match.Any) > 0 {
+		var entries []kyverno.ResourceDescription
+		var lastErr error
+		for _, entry := range match.Any {
+			rd, err := mergeResourceDescription(policyMatch.ResourceDescription, entry)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			entries = append(entries, rd)
+		}
+
+		if len(entries) == 0 {
+			return match, fmt.Errorf("no entry of any is compatible: %v", lastErr)
+		}
+		match.Any = entries
+	}
+
+	for i, entry := range match.All {
+		rd, err := mergeResourceDescription(policyMatch.ResourceDescription, entry)
+		if err != nil {
+			return match, fmt.Errorf("all[%d]: %v", i, err)
+		}
+		match.All[i] = rd
+	}
+
+	return match, nil
+}
+
+func mergeResourceDescription(policyRD, ruleRD kyverno.ResourceDescription) (kyverno.ResourceDescription, error) {
+	rd := ruleRD
+	var err error
+	if rd.Kinds, err = intersectPatterns(policyRD.Kinds, ruleRD.Kinds); err != nil {
+		return rd, fmt.Errorf("kinds: %v", err)
+	}
+
+	if rd.Namespaces, err = intersectPatterns(policyRD.Namespaces, ruleRD.Namespaces); err != nil {
+		return rd, fmt.Errorf("namespaces: %v", err)
+	}
+
+	if rd.Name, err = intersectPattern(policyRD.Name, ruleRD.Name); err != nil {
+		return rd, fmt.Errorf("name: %v", err)
+	}
+
+	if rd.Annotations, err = mergeLabels(policyRD.Annotations, ruleRD.Annotations); err != nil {
+		return rd, fmt.Errorf("annotations: %v", err)
+	}
+
+	if rd.Selector, err = mergeSelectors(policyRD.Selector, ruleRD.Selector); err != nil {
+		return rd, fmt.Errorf("selector: %v", err)
+	}
+
+	if rd.NamespaceSelector, err = mergeSelectors(policyRD.NamespaceSelector, ruleRD.NamespaceSelector); err != nil {
+		return rd, fmt.Errorf("namespaceSelector: %v", err)
+	}
+
+	return rd, nil
+}
+
+// intersectPatterns returns the values matched by both lists of wildcard patterns,
+// an empty list matches everything
+func intersectPatterns(policyPatterns, rulePatterns []string) ([]string, error) {
+	if len(policyPatterns) == 0 {
+		return rulePatterns, nil
+	}
+
+	if len(rulePatterns) == 0 {
+		return policyPatterns, nil
+	}
+
+	var result []string
+	for _, rulePattern := range rulePatterns {
+		for _, policyPattern := range policyPatterns {
+			if pattern, err := intersectPattern(policyPattern, rulePattern); err == nil {
+				result = appendUnique(result, pattern)
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%v and %v have no value in common", policyPatterns, rulePatterns)
+	}
+
+	return result, nil
+}
+
+// intersectPattern returns the more specific of the two wildcard patterns if one
+// contains the other, an empty pattern matches everything
+func intersectPattern(policyPattern, rulePattern string) (string, error) {
+	switch {
+	case policyPattern == "":
+		return rulePattern, nil
+	case rulePattern == "":
+		return policyPattern, nil
+	case wildcard.Match(policyPattern, rulePattern):
+		return rulePattern, nil
+	case wildcard.Match(rulePattern, policyPattern):
+		return policyPattern, nil
+	default:
+		return "", fmt.Errorf("%s and %s have no value in common", policyPattern, rulePattern)
+	}
+}
+
+func mergeLabels(policyLabels, ruleLabels map[string]string) (map[string]string, error) {
+	if len(policyLabels) == 0 {
+		return ruleLabels, nil
+	}
+
+	if len(ruleLabels) == 0 {
+		return policyLabels, nil
+	}
+
+	labels := make(map[string]string, len(policyLabels)+len(ruleLabels))
+	for k, v := range ruleLabels {
+		labels[k] = v
+	}
+
+	for k, v := range policyLabels {
+		ruleValue, ok := labels[k]
+		if !ok {
+			labels[k] = v
+			continue
+		}
+
+		value, err := intersectPattern(v, ruleValue)
+		if err != nil {
+			return nil, fmt.Errorf("conflicting values for %s: %v", k, err)
+		}
+		labels[k] = value
+	}
+
+	return labels, nil
+}
+
+func mergeSelectors(policySelector, ruleSelector *metav1.LabelSelector) (*metav1.LabelSelector, error) {
+	if policySelector == nil {
+		return ruleSelector, nil
+	}
+
+	if ruleSelector == nil {
+		return policySelector, nil
+	}
+
+	matchLabels, err := mergeLabels(policySelector.MatchLabels, ruleSelector.MatchLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := &metav1.LabelSelector{MatchLabels: matchLabels}
+	selector.MatchExpressions = append(selector.MatchExpressions, ruleSelector.MatchExpressions...)
+	selector.MatchExpressions = append(selector.MatchExpressions, policySelector.MatchExpressions...)
+	return selector, nil
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+
+	return append(list, value)
+}
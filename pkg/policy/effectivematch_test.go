@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_ValidateEffectiveMatch(t *testing.T) {
+	testcases := []struct {
+		description string
+		spec        []byte
+		path        string
+	}{
+		{
+			description: "no policy level match",
+			spec:        []byte(`{"rules":[{"name":"r","match":{"resources":{"kinds":["Pod"]}}}]}`),
+		},
+		{
+			description: "compatible kinds and namespaces",
+			spec:        []byte(`{"match":{"resources":{"kinds":["Pod","Deployment"],"namespaces":["prod-*"]}},"rules":[{"name":"r","match":{"resources":{"kinds":["Pod"],"namespaces":["prod-web"]}}}]}`),
+		},
+		{
+			description: "compatible labels",
+			spec:        []byte(`{"match":{"resources":{"selector":{"matchLabels":{"team":"*"}}}},"rules":[{"name":"r","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"team":"a","app":"web"}}}}}]}`),
+		},
+		{
+			description: "disjoint kinds",
+			spec:        []byte(`{"match":{"resources":{"kinds":["Deployment"]}},"rules":[{"name":"r","match":{"resources":{"kinds":["Pod"]}}}]}`),
+			path:        "spec.rules[0].match",
+		},
+		{
+			description: "disjoint namespaces",
+			spec:        []byte(`{"match":{"resources":{"namespaces":["prod-*"]}},"rules":[{"name":"ok","match":{"resources":{"kinds":["Pod"]}}},{"name":"r","match":{"resources":{"kinds":["Pod"],"namespaces":["dev"]}}}]}`),
+			path:        "spec.rules[1].match",
+		},
+		{
+			description: "conflicting label values",
+			spec:        []byte(`{"match":{"resources":{"selector":{"matchLabels":{"env":"prod"}}}},"rules":[{"name":"r","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"env":"dev"}}}}}]}`),
+			path:        "spec.rules[0].match",
+		},
+		{
+			description: "no compatible any entry",
+			spec:        []byte(`{"match":{"resources":{"kinds":["Pod"]}},"rules":[{"name":"r","match":{"any":[{"kinds":["Service"]},{"kinds":["Deployment"]}]}}]}`),
+			path:        "spec.rules[0].match",
+		},
+		{
+			description: "policy level exclude covers the rule",
+			spec:        []byte(`{"exclude":{"resources":{"kinds":["Pod"]}},"rules":[{"name":"r","match":{"resources":{"kinds":["Pod"]}}}]}`),
+			path:        "spec.rules[0].match",
+		},
+		{
+			description: "rule exclude covers the merged match",
+			spec:        []byte(`{"match":{"resources":{"namespaces":["prod"]}},"rules":[{"name":"r","match":{"resources":{"kinds":["Pod"]}},"exclude":{"resources":{"namespaces":["prod"]}}}]}`),
+			path:        "spec.rules[0].exclude",
+		},
+		{
+			description: "any in the policy level match",
+			spec:        []byte(`{"match":{"any":[{"kinds":["Pod"]}]},"rules":[{"name":"r","match":{"resources":{"kinds":["Pod"]}}}]}`),
+			path:        "spec.match",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy kyverno.ClusterPolicy
+		err := json.Unmarshal(testcase.spec, &policy.Spec)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := ValidateEffectiveMatch(policy)
+		assert.Equal(t, path, testcase.path, testcase.description)
+		assert.Equal(t, err != nil, testcase.path != "", testcase.description)
+	}
+}
+
+func Test_EffectiveMatch(t *testing.T) {
+	policyMatch := kyverno.MatchResources{ResourceDescription: kyverno.ResourceDescription{
+		Kinds:      []string{"Pod", "Deployment"},
+		Namespaces: []string{"prod-*"},
+	}}
+
+	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: kyverno.ResourceDescription{
+		Kinds: []string{"Pod", "Service"},
+		Name:  "web-*",
+	}}}
+
+	match, err := EffectiveMatch(&policyMatch, rule)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, match.Kinds, []string{"Pod"})
+	assert.DeepEqual(t, match.Namespaces, []string{"prod-*"})
+	assert.Equal(t, match.Name, "web-*")
+	assert.DeepEqual(t, rule.MatchResources.Kinds, []string{"Pod", "Service"})
+}
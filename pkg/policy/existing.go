@@ -22,11 +22,11 @@ func (pc *PolicyController) processExistingResources(policy *kyverno.ClusterPoli
 	pc.rm.Drop()
 
 	for _, rule := range policy.Spec.Rules {
-		if !rule.HasValidate() {
+		if !rule.HasValidate() || rule.SkipBackgroundRequests {
 			continue
 		}
 
-		for _, k := range rule.MatchResources.Kinds {
+		for _, k := range matchKinds(rule.MatchResources) {
 			logger = logger.WithValues("rule", rule.Name, "kind", k)
 			namespaced, err := pc.rm.GetScope(k)
 			if err != nil {
@@ -51,6 +51,25 @@ func (pc *PolicyController) processExistingResources(policy *kyverno.ClusterPoli
 	}
 }
 
+// matchKinds returns the kinds of the resource description or of the any and all
+// descriptions of the match block, without duplicates
+func matchKinds(match kyverno.MatchResources) []string {
+	var kinds []string
+	found := make(map[string]bool)
+	for _, descriptions := range [][]kyverno.ResourceDescription{{match.ResourceDescription}, match.Any, match.All} {
+		for _, description := range descriptions {
+			for _, kind := range description.Kinds {
+				if !found[kind] {
+					found[kind] = true
+					kinds = append(kinds, kind)
+				}
+			}
+		}
+	}
+
+	return kinds
+}
+
 func (pc *PolicyController) registerResource(kind string) (err error) {
 	genericCache, ok := pc.resCache.GetGVRCache(kind)
 	if !ok {
@@ -0,0 +1,29 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_matchKinds(t *testing.T) {
+	testcases := []struct {
+		match []byte
+		kinds []string
+	}{
+		{match: []byte(`{"resources":{"kinds":["Pod","Deployment"]}}`), kinds: []string{"Pod", "Deployment"}},
+		{match: []byte(`{"any":[{"kinds":["Pod"]},{"kinds":["Service","Pod"]}]}`), kinds: []string{"Pod", "Service"}},
+		{match: []byte(`{"all":[{"kinds":["Pod"]},{"name":"web-*"}]}`), kinds: []string{"Pod"}},
+		{match: []byte(`{"any":[{"kinds":["Pod"]}],"all":[{"kinds":["ConfigMap"]}]}`), kinds: []string{"Pod", "ConfigMap"}},
+	}
+
+	for _, testcase := range testcases {
+		var match kyverno.MatchResources
+		err := json.Unmarshal(testcase.match, &match)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, matchKinds(match), testcase.kinds)
+	}
+}
@@ -0,0 +1,50 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// validateFailureActionOverrides checks each override has a valid action and valid namespace
+// patterns, and that no namespace is selected by overrides with different actions
+func validateFailureActionOverrides(overrides []kyverno.FailureActionOverride) (string, error) {
+	for i, override := range overrides {
+		if override.Action != "enforce" && override.Action != "audit" {
+			return fmt.Sprintf("failureActionOverrides[%d].action", i), fmt.Errorf("invalid action %s, expected enforce or audit", override.Action)
+		}
+
+		if len(override.Namespaces) == 0 {
+			return fmt.Sprintf("failureActionOverrides[%d].namespaces", i), fmt.Errorf("at least one namespace must be specified")
+		}
+
+		for j, namespace := range override.Namespaces {
+			// wildcards are replaced with a valid character to check the rest of the pattern
+			name := strings.NewReplacer("*", "a", "?", "a").Replace(namespace)
+			if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+				return fmt.Sprintf("failureActionOverrides[%d].namespaces[%d]", i, j), fmt.Errorf("invalid namespace %s: %s", namespace, strings.Join(errs, ", "))
+			}
+		}
+	}
+
+	for i, override := range overrides {
+		for j := i + 1; j < len(overrides); j++ {
+			other := overrides[j]
+			if other.Action == override.Action {
+				continue
+			}
+
+			for _, namespace := range override.Namespaces {
+				for _, otherNamespace := range other.Namespaces {
+					if _, err := intersectPattern(namespace, otherNamespace); err == nil {
+						return fmt.Sprintf("failureActionOverrides[%d].namespaces", j), fmt.Errorf("namespace %s conflicts with namespace %s of failureActionOverrides[%d], which uses the action %s", otherNamespace, namespace, i, override.Action)
+					}
+				}
+			}
+		}
+	}
+
+	return "", nil
+}
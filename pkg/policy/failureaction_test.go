@@ -0,0 +1,51 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateFailureActionOverrides(t *testing.T) {
+	testcases := []struct {
+		description string
+		overrides   []byte
+		path        string
+	}{
+		{
+			description: "disjoint overrides",
+			overrides:   []byte(`[{"action":"enforce","namespaces":["prod-*"]},{"action":"audit","namespaces":["dev-*","test"]}]`),
+		},
+		{
+			description: "same action",
+			overrides:   []byte(`[{"action":"audit","namespaces":["dev-*"]},{"action":"audit","namespaces":["dev-1"]}]`),
+		},
+		{
+			description: "conflicting overrides",
+			overrides:   []byte(`[{"action":"enforce","namespaces":["prod-*"]},{"action":"audit","namespaces":["dev","prod-eu"]}]`),
+			path:        "failureActionOverrides[1].namespaces",
+		},
+		{
+			description: "invalid action",
+			overrides:   []byte(`[{"action":"block","namespaces":["prod"]}]`),
+			path:        "failureActionOverrides[0].action",
+		},
+		{
+			description: "invalid namespace",
+			overrides:   []byte(`[{"action":"enforce","namespaces":["Prod_*"]}]`),
+			path:        "failureActionOverrides[0].namespaces[0]",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var overrides []kyverno.FailureActionOverride
+		err := json.Unmarshal(testcase.overrides, &overrides)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateFailureActionOverrides(overrides)
+		assert.Equal(t, err != nil, testcase.path != "", testcase.description)
+		assert.Equal(t, path, testcase.path, testcase.description)
+	}
+}
@@ -0,0 +1,32 @@
+package generate
+
+import (
+	"fmt"
+
+	"github.com/kyverno/kyverno/pkg/engine/variables"
+)
+
+// CRDLookup returns true if the kind is a custom resource, and whether its custom resource
+// definition is installed and established
+type CRDLookup func(kind string) (custom bool, established bool)
+
+// SetCRDLookup sets the lookup used to check the custom resource definitions of generated
+// kinds are established. Kinds are not checked if not set.
+func (g *Generate) SetCRDLookup(lookup CRDLookup) {
+	g.crdLookup = lookup
+}
+
+// validateCRDEstablished returns an error if the generated kind is a custom resource whose
+// definition is not established, in which case generating the resource fails until the
+// definition is installed. Kinds using variables are not checked.
+func validateCRDEstablished(kind string, lookup CRDLookup) error {
+	if lookup == nil || kind == "" || variables.IsVariable(kind) {
+		return nil
+	}
+
+	if custom, established := lookup(kind); !custom || established {
+		return nil
+	}
+
+	return fmt.Errorf("the custom resource definition of %s is not established, generating %s fails until it is installed", kind, kind)
+}
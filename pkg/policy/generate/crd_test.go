@@ -0,0 +1,58 @@
+package generate
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateCRDEstablished(t *testing.T) {
+	// Certificate is installed, Backup is not
+	lookup := func(kind string) (bool, bool) {
+		switch kind {
+		case "Certificate":
+			return true, true
+		case "Backup":
+			return true, false
+		}
+		return false, false
+	}
+
+	testcases := []struct {
+		description string
+		generate    []byte
+		expectWarn  bool
+	}{
+		{
+			description: "established CRD",
+			generate:    []byte(`{"apiVersion":"cert-manager.io/v1","kind":"Certificate","name":"cert","namespace":"{{request.object.metadata.name}}","data":{"spec":{"secretName":"tls"}}}`),
+		},
+		{
+			description: "missing CRD",
+			generate:    []byte(`{"apiVersion":"velero.io/v1","kind":"Backup","name":"daily","namespace":"velero","data":{"spec":{"includedNamespaces":["{{request.object.metadata.name}}"]}}}`),
+			expectWarn:  true,
+		},
+		{
+			description: "built-in kind",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.name}}","data":{"data":{"key":"value"}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err, testcase.description)
+
+		err = validateCRDEstablished(genRule.Kind, lookup)
+		assert.Equal(t, err != nil, testcase.expectWarn, testcase.description)
+		assert.NilError(t, validateCRDEstablished(genRule.Kind, nil), testcase.description)
+
+		// a missing CRD is only a warning
+		checker := NewFakeGenerate(genRule)
+		checker.SetCRDLookup(lookup)
+		_, err = checker.Validate()
+		assert.NilError(t, err, testcase.description)
+	}
+}
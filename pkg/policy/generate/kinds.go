@@ -0,0 +1,144 @@
+package generate
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/kyverno/kyverno/pkg/engine/variables"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// kindValidator validates the data of a generated resource of a specific kind.
+// It returns the path of the offending field along with the error.
+type kindValidator func(data map[string]interface{}) (string, error)
+
+// kindValidators holds the kind specific checks applied to the data of a generate rule
+var kindValidators = map[string]kindValidator{
+	"ConfigMap": validateConfigMapData,
+	"Secret":    validateSecretData,
+}
+
+// kindWarnings holds the kind specific checks of the data of a generate rule whose
+// failures are reported as warnings
+var kindWarnings = map[string]kindValidator{
+	"ClusterRole": validateRBACRules,
+	"Role":        validateRBACRules,
+}
+
+// validateKindData runs the kind specific checks for the generated resource, if any
+func validateKindData(kind string, data interface{}) (string, error) {
+	return runKindValidator(kindValidators, kind, data)
+}
+
+// WarnKindData runs the kind specific checks for the generated resource whose failures
+// should be reported as warnings, such as RBAC rules granting every permission
+func WarnKindData(kind string, data interface{}) (string, error) {
+	return runKindValidator(kindWarnings, kind, data)
+}
+
+func runKindValidator(validators map[string]kindValidator, kind string, data interface{}) (string, error) {
+	validator, ok := validators[kind]
+	if !ok {
+		return "", nil
+	}
+
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+
+	return validator(dataMap)
+}
+
+// validateConfigMapData checks the keys under data and binaryData are valid ConfigMap keys,
+// and the binaryData values are base64 encoded
+func validateConfigMapData(data map[string]interface{}) (string, error) {
+	if path, err := validateDataKeys(data, "data"); err != nil {
+		return path, err
+	}
+
+	if path, err := validateDataKeys(data, "binaryData"); err != nil {
+		return path, err
+	}
+
+	return validateBase64Values(data, "binaryData")
+}
+
+// validateSecretData checks the keys under data and stringData are valid Secret keys,
+// and the data values are base64 encoded
+func validateSecretData(data map[string]interface{}) (string, error) {
+	if path, err := validateDataKeys(data, "data"); err != nil {
+		return path, err
+	}
+
+	if path, err := validateDataKeys(data, "stringData"); err != nil {
+		return path, err
+	}
+
+	return validateBase64Values(data, "data")
+}
+
+// validateRBACRules checks the rules of a Role or ClusterRole do not grant all verbs on all
+// resources, which makes every subject bound to the generated role an administrator
+func validateRBACRules(data map[string]interface{}) (string, error) {
+	rules, _ := data["rules"].([]interface{})
+	for i, rule := range rules {
+		ruleMap, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if containsWildcard(ruleMap["verbs"]) && containsWildcard(ruleMap["resources"]) {
+			return fmt.Sprintf("rules[%d]", i), fmt.Errorf("rule grants all verbs on all resources")
+		}
+	}
+
+	return "", nil
+}
+
+func containsWildcard(values interface{}) bool {
+	list, _ := values.([]interface{})
+	for _, value := range list {
+		if value == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateDataKeys(data map[string]interface{}, field string) (string, error) {
+	entries, _ := data[field].(map[string]interface{})
+	for key := range entries {
+		if variables.IsVariable(key) {
+			continue
+		}
+
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return field + "." + key, fmt.Errorf("invalid key '%s': %s", key, strings.Join(errs, ", "))
+		}
+	}
+
+	return "", nil
+}
+
+func validateBase64Values(data map[string]interface{}, field string) (string, error) {
+	entries, _ := data[field].(map[string]interface{})
+	for key, value := range entries {
+		str, ok := value.(string)
+		if !ok {
+			return field + "." + key, fmt.Errorf("value of key '%s' must be a base64 encoded string", key)
+		}
+
+		if variables.IsVariable(str) {
+			continue
+		}
+
+		if _, err := base64.StdEncoding.DecodeString(str); err != nil {
+			return field + "." + key, fmt.Errorf("value of key '%s' is not base64 encoded: %v", key, err)
+		}
+	}
+
+	return "", nil
+}
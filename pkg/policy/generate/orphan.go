@@ -0,0 +1,70 @@
+package generate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"github.com/kyverno/kyverno/pkg/engine/variables"
+)
+
+// validateOrphanDependents returns an error if the orphanDependents setting is unusual
+// for the generate rule. Orphaned resources of a synchronized rule keep being updated from
+// the data or the clone source after the trigger is deleted. Owner references cannot cross
+// namespaces, so resources generated in a fixed namespace cannot be deleted with triggers
+// of other namespaces, and resources generated by cloneList are not owned by the trigger.
+func validateOrphanDependents(rule kyverno.Generation) (string, error) {
+	if rule.OrphanDependents == nil {
+		return "", nil
+	}
+
+	if *rule.OrphanDependents {
+		if rule.Synchronize {
+			mode := "data"
+			if !reflect.DeepEqual(rule.Clone, kyverno.CloneFrom{}) {
+				mode = "clone source"
+			}
+			return "orphanDependents", fmt.Errorf("orphaned resources remain synchronized with the %s after the trigger is deleted", mode)
+		}
+
+		return "", nil
+	}
+
+	if len(rule.CloneList.Kinds) > 0 {
+		return "orphanDependents", fmt.Errorf("resources generated by cloneList are not owned by the trigger")
+	}
+
+	namespace := rule.Namespace
+	if namespace != "" && !variables.IsVariable(namespace) {
+		return "orphanDependents", fmt.Errorf("resources generated in the namespace %s can only be deleted with triggers of the same namespace, "+
+			"use {{request.object.metadata.namespace}} to generate them next to the trigger", namespace)
+	}
+
+	return "", nil
+}
+
+// regexTriggerNamespace matches the variable of the namespace of the trigger
+var regexTriggerNamespace = regexp.MustCompile(`^\{\{\s*request\.object\.metadata\.namespace\s*\}\}$`)
+
+// validateOwnerReferences returns an error if resources generated from data next to a
+// namespaced trigger are silently kept when the trigger is deleted: the data has no owner
+// references, the rule does not synchronize and orphanDependents is not set. Resources
+// generated in other namespaces cannot be owned by the trigger, and resources generated
+// in a Namespace trigger are deleted with it, so they are not reported.
+func validateOwnerReferences(rule kyverno.Generation) (string, error) {
+	if rule.Data == nil || rule.Synchronize || rule.OrphanDependents != nil || !regexTriggerNamespace.MatchString(rule.Namespace) {
+		return "", nil
+	}
+
+	if data, ok := rule.Data.(map[string]interface{}); ok {
+		if metadata, ok := data["metadata"].(map[string]interface{}); ok {
+			if _, ok := metadata["ownerReferences"]; ok {
+				return "", nil
+			}
+		}
+	}
+
+	return "orphanDependents", fmt.Errorf("generated %s %s is kept when the trigger is deleted, "+
+		"set orphanDependents to false to delete it with the trigger, or to true to keep it", rule.Kind, rule.Name)
+}
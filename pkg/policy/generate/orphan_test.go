@@ -0,0 +1,120 @@
+package generate
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateOrphanDependents(t *testing.T) {
+	testcases := []struct {
+		description  string
+		generate     []byte
+		expectedPath string
+	}{
+		{
+			description: "not set",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","synchronize":true,"data":{}}`),
+		},
+		{
+			description: "orphaned data",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","orphanDependents":true,"data":{}}`),
+		},
+		{
+			description:  "orphaned synchronized data",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","orphanDependents":true,"synchronize":true,"data":{}}`),
+			expectedPath: "orphanDependents",
+		},
+		{
+			description:  "orphaned synchronized clone",
+			generate:     []byte(`{"kind":"Secret","name":"regcred","namespace":"{{request.object.metadata.name}}","orphanDependents":true,"synchronize":true,"clone":{"namespace":"default","name":"regcred"}}`),
+			expectedPath: "orphanDependents",
+		},
+		{
+			description: "deleted with the trigger next to it",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.namespace}}","orphanDependents":false,"data":{}}`),
+		},
+		{
+			description: "cloned and deleted with the trigger",
+			generate:    []byte(`{"kind":"Secret","name":"regcred","namespace":"{{request.object.metadata.name}}","orphanDependents":false,"synchronize":true,"clone":{"namespace":"default","name":"regcred"}}`),
+		},
+		{
+			description:  "deleted with a trigger of another namespace",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","orphanDependents":false,"data":{}}`),
+			expectedPath: "orphanDependents",
+		},
+		{
+			description:  "cloneList deleted with the trigger",
+			generate:     []byte(`{"namespace":"{{request.object.metadata.name}}","orphanDependents":false,"cloneList":{"namespace":"default","kinds":["Secret"]}}`),
+			expectedPath: "orphanDependents",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateOrphanDependents(genRule)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+	}
+}
+
+func Test_validateOwnerReferences(t *testing.T) {
+	testcases := []struct {
+		description  string
+		generate     []byte
+		expectedPath string
+	}{
+		{
+			description:  "data without owner references",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.namespace}}","data":{"data":{"key":"value"}}}`),
+			expectedPath: "orphanDependents",
+		},
+		{
+			description: "data generated in the namespace trigger",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.name}}","data":{"data":{"key":"value"}}}`),
+		},
+		{
+			description: "data generated in a fixed namespace",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"key":"value"}}}`),
+		},
+		{
+			description: "explicitly orphaned",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.namespace}}","orphanDependents":true,"data":{"data":{"key":"value"}}}`),
+		},
+		{
+			description: "data with owner references",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.namespace}}","data":{"metadata":{"ownerReferences":[{"apiVersion":"v1","kind":"Pod","name":"{{request.object.metadata.name}}","uid":"{{request.object.metadata.uid}}"}]},"data":{"key":"value"}}}`),
+		},
+		{
+			description: "synchronized data",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.namespace}}","synchronize":true,"data":{"data":{"key":"value"}}}`),
+		},
+		{
+			description: "deleted with the trigger",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.namespace}}","orphanDependents":false,"data":{"data":{"key":"value"}}}`),
+		},
+		{
+			description: "cluster-scoped data",
+			generate:    []byte(`{"kind":"ClusterRole","name":"viewer","data":{"rules":[]}}`),
+		},
+		{
+			description: "clone",
+			generate:    []byte(`{"kind":"Secret","name":"regcred","namespace":"{{request.object.metadata.name}}","clone":{"namespace":"default","name":"regcred"}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateOwnerReferences(genRule)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
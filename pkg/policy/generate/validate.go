@@ -3,6 +3,7 @@ package generate
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/go-logr/logr"
 	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
@@ -10,6 +11,7 @@ import (
 	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
 	"github.com/kyverno/kyverno/pkg/engine/variables"
 	"github.com/kyverno/kyverno/pkg/policy/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Generate provides implementation to validate 'generate' rule
@@ -18,6 +20,8 @@ type Generate struct {
 	rule kyverno.Generation
 	// authCheck to check access for operations
 	authCheck Operations
+	// crdLookup to check custom resource definitions of generated kinds are established
+	crdLookup CRDLookup
 	//logger
 	log logr.Logger
 }
@@ -36,12 +40,43 @@ func NewGenerateFactory(client *dclient.Client, rule kyverno.Generation, log log
 //Validate validates the 'generate' rule
 func (g *Generate) Validate() (string, error) {
 	rule := g.rule
-	if rule.Data != nil && rule.Clone != (kyverno.CloneFrom{}) {
+	if rule.Data != nil && !reflect.DeepEqual(rule.Clone, kyverno.CloneFrom{}) {
 		return "", fmt.Errorf("only one of data or clone can be specified")
 	}
 
 	kind, name, namespace := rule.Kind, rule.Name, rule.Namespace
 
+	if path, err := validateOrphanDependents(rule); err != nil {
+		g.log.V(1).Info(fmt.Sprintf("warning: %s: %v", path, err))
+	}
+
+	if path, err := validateOwnerReferences(rule); err != nil {
+		g.log.V(1).Info(fmt.Sprintf("warning: %s: %v", path, err))
+	}
+
+	if err := validateCRDEstablished(rule.Kind, g.crdLookup); err != nil {
+		g.log.V(1).Info(fmt.Sprintf("warning: kind: %v", err))
+	}
+
+	for i, kind := range rule.CloneList.Kinds {
+		if err := validateCRDEstablished(kind, g.crdLookup); err != nil {
+			g.log.V(1).Info(fmt.Sprintf("warning: cloneList.kinds[%d]: %v", i, err))
+		}
+	}
+
+	// cloneList generates a resource per source, named after it
+	if !reflect.DeepEqual(rule.CloneList, kyverno.CloneList{}) {
+		if rule.Data != nil || !reflect.DeepEqual(rule.Clone, kyverno.CloneFrom{}) {
+			return "cloneList", fmt.Errorf("cloneList cannot be combined with data or clone")
+		}
+
+		if path, err := g.validateCloneList(rule.CloneList, namespace); err != nil {
+			return fmt.Sprintf("cloneList.%s", path), err
+		}
+
+		return "", nil
+	}
+
 	if name == "" {
 		return "name", fmt.Errorf("name cannot be empty")
 	}
@@ -61,6 +96,32 @@ func (g *Generate) Validate() (string, error) {
 		if path, err := common.ValidatePattern(rule.Data, "/", []commonAnchors.IsAnchor{}); err != nil {
 			return fmt.Sprintf("data.%s", path), fmt.Errorf("anchors not supported on generate resources: %v", err)
 		}
+
+		if data, ok := rule.Data.(map[string]interface{}); ok {
+			if _, ok := data["status"]; ok {
+				return "data.status", fmt.Errorf("status is managed by the API server and cannot be generated")
+			}
+		}
+
+		if path, err := validateServerAssignedFields(rule.Data); err != nil {
+			return fmt.Sprintf("data.%s", path), err
+		}
+
+		if path, err := validateDataTypeMeta(rule); err != nil {
+			return fmt.Sprintf("data.%s", path), err
+		}
+
+		if err := validateDataNamespace(rule); err != nil {
+			return "data.metadata.namespace", err
+		}
+
+		if path, err := validateKindData(kind, rule.Data); err != nil {
+			return fmt.Sprintf("data.%s", path), err
+		}
+	}
+
+	if err := validateGenerateExisting(rule); err != nil {
+		g.log.V(1).Info(fmt.Sprintf("warning: generateExisting: %v", err))
 	}
 
 	// Kyverno generate-controller create/update/deletes the resources specified in generate rule of policy
@@ -74,11 +135,30 @@ func (g *Generate) Validate() (string, error) {
 	return "", nil
 }
 
+// validateGenerateExisting returns an error if generateExisting is set for a rule that
+// neither synchronizes nor generates a namespaced resource
+func validateGenerateExisting(rule kyverno.Generation) error {
+	if !rule.GenerateExisting || rule.Synchronize || rule.Namespace != "" {
+		return nil
+	}
+
+	return fmt.Errorf("generateExisting is only meaningful with synchronize or for namespaced resources, " +
+		"and processes every existing trigger when the policy is created which may be slow on large clusters")
+}
+
 func (g *Generate) validateClone(c kyverno.CloneFrom, kind string) (string, error) {
 	if c.Name == "" {
 		return "name", fmt.Errorf("name cannot be empty")
 	}
 
+	if path, err := validateCloneExclude(c.Exclude, kind); err != nil {
+		return path, err
+	}
+
+	if path, err := validateCloneTransformations(c.Transformations, c.Exclude); err != nil {
+		return path, err
+	}
+
 	namespace := c.Namespace
 	// Skip if there is variable defined
 	if !variables.IsVariable(kind) && !variables.IsVariable(namespace) {
@@ -96,6 +176,201 @@ func (g *Generate) validateClone(c kyverno.CloneFrom, kind string) (string, erro
 	return "", nil
 }
 
+// cloneFields are the top-level fields of kinds commonly cloned, used to check the excluded
+// fields exist in the source resource
+var cloneFields = map[string][]string{
+	"ConfigMap": {"binaryData", "data", "immutable", "metadata"},
+	"Secret":    {"data", "immutable", "metadata", "stringData", "type"},
+}
+
+// requiredCloneFields cannot be excluded, the generated resource is invalid without them
+var requiredCloneFields = []string{"/apiVersion", "/kind", "/metadata", "/metadata/name", "/metadata/namespace"}
+
+// validateCloneExclude checks the excluded fields are JSON pointers to fields which are not
+// required in the generated resource and, for known kinds, exist in the source resource
+func validateCloneExclude(exclude []string, kind string) (string, error) {
+	for i, pointer := range exclude {
+		path := fmt.Sprintf("exclude[%d]", i)
+		if err := common.ValidateJSONPointer(pointer); err != nil {
+			return path, err
+		}
+
+		for _, required := range requiredCloneFields {
+			if strings.TrimSuffix(pointer, "/") == required {
+				return path, fmt.Errorf("%s is required in the generated resource and cannot be excluded", pointer)
+			}
+		}
+
+		fields, ok := cloneFields[kind]
+		if !ok {
+			continue
+		}
+
+		field := strings.SplitN(pointer[1:], "/", 2)[0]
+		found := false
+		for _, f := range fields {
+			found = found || f == field
+		}
+
+		if !found {
+			return path, fmt.Errorf("%s has no field %s, expected one of %s", kind, field, strings.Join(fields, ", "))
+		}
+	}
+
+	return "", nil
+}
+
+// serverAssignedFields are the metadata fields set by the API server, the resource cannot be
+// applied when they are set to other values
+var serverAssignedFields = []string{"resourceVersion", "uid", "selfLink"}
+
+// validateServerAssignedFields returns an error if the metadata of the generated data sets
+// a field assigned by the API server
+func validateServerAssignedFields(data interface{}) (string, error) {
+	resource, ok := data.(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+
+	metadata, ok := resource["metadata"].(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+
+	for _, field := range serverAssignedFields {
+		if _, ok := metadata[field]; ok {
+			return "metadata." + field, fmt.Errorf("%s is assigned by the API server and cannot be generated", field)
+		}
+	}
+
+	return "", nil
+}
+
+// validateDataTypeMeta returns an error if the kind or the apiVersion set in the generated
+// data differ from the kind and the apiVersion of the generate rule. The apiVersion is only
+// compared if set in the rule, values using variables are not compared.
+func validateDataTypeMeta(rule kyverno.Generation) (string, error) {
+	data, ok := rule.Data.(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+
+	for _, field := range []struct{ name, expected string }{
+		{"kind", rule.Kind},
+		{"apiVersion", rule.APIVersion},
+	} {
+		value, ok := data[field.name].(string)
+		if !ok || value == "" || field.expected == "" || variables.IsVariable(value) || variables.IsVariable(field.expected) {
+			continue
+		}
+
+		if value != field.expected {
+			return field.name, fmt.Errorf("%s %s conflicts with the generate %s %s", field.name, value, field.name, field.expected)
+		}
+	}
+
+	return "", nil
+}
+
+// validateCloneTransformations checks the transformations set a value for a JSON pointer to
+// a field which is neither required in the generated resource, nor managed by the API server,
+// nor excluded from the clone
+func validateCloneTransformations(transformations []kyverno.CloneTransformation, exclude []string) (string, error) {
+	for i, transformation := range transformations {
+		path := fmt.Sprintf("transformations[%d]", i)
+		pointer := strings.TrimSuffix(transformation.Path, "/")
+		if err := common.ValidateJSONPointer(transformation.Path); err != nil {
+			return path + ".path", err
+		}
+
+		if transformation.Value == nil {
+			return path + ".value", fmt.Errorf("value cannot be empty, use exclude to remove %s", transformation.Path)
+		}
+
+		for _, required := range requiredCloneFields {
+			if pointer == required {
+				return path + ".path", fmt.Errorf("%s is set by the generate rule and cannot be transformed", transformation.Path)
+			}
+		}
+
+		forbidden := []string{"/status"}
+		for _, field := range serverAssignedFields {
+			forbidden = append(forbidden, "/metadata/"+field)
+		}
+
+		for _, field := range forbidden {
+			if pointer == field || strings.HasPrefix(pointer, field+"/") {
+				return path + ".path", fmt.Errorf("%s is managed by the API server and cannot be set", transformation.Path)
+			}
+		}
+
+		for _, excluded := range exclude {
+			if excluded = strings.TrimSuffix(excluded, "/"); pointer == excluded || strings.HasPrefix(pointer, excluded+"/") {
+				return path + ".path", fmt.Errorf("%s is excluded from the clone", transformation.Path)
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// validateDataNamespace checks the namespace set in the metadata of the generated data, if
+// any, is the namespace the resource is generated in
+func validateDataNamespace(rule kyverno.Generation) error {
+	data, ok := rule.Data.(map[string]interface{})
+	if !ok || rule.Namespace == "" {
+		return nil
+	}
+
+	metadata, ok := data["metadata"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	namespace, ok := metadata["namespace"].(string)
+	if !ok || namespace == rule.Namespace {
+		return nil
+	}
+
+	return fmt.Errorf("namespace %s conflicts with the generate namespace %s, remove it from the data", namespace, rule.Namespace)
+}
+
+func (g *Generate) validateCloneList(c kyverno.CloneList, namespace string) (string, error) {
+	if len(c.Kinds) == 0 {
+		return "kinds", fmt.Errorf("kinds cannot be empty")
+	}
+
+	for i, kind := range c.Kinds {
+		if kind == "" || strings.Contains(kind, "*") {
+			return fmt.Sprintf("kinds[%d]", i), fmt.Errorf("invalid kind %q, kinds must be specific", kind)
+		}
+	}
+
+	if c.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(c.Selector); err != nil {
+			return "selector", err
+		}
+	}
+
+	for i, kind := range c.Kinds {
+		if !variables.IsVariable(c.Namespace) {
+			ok, err := g.authCheck.CanIGet(kind, c.Namespace)
+			if err != nil {
+				return fmt.Sprintf("kinds[%d]", i), err
+			}
+			if !ok {
+				return fmt.Sprintf("kinds[%d]", i), fmt.Errorf("kyverno does not have permissions to 'get' resource %s/%s. Update permissions in ClusterRole 'kyverno:generatecontroller'", kind, c.Namespace)
+			}
+		}
+
+		if err := g.canIGenerate(kind, namespace); err != nil {
+			return fmt.Sprintf("kinds[%d]", i), err
+		}
+	}
+
+	return "", nil
+}
+
 //canIGenerate returns a error if kyverno cannot perform operations
 func (g *Generate) canIGenerate(kind, namespace string) error {
 	// Skip if there is variable defined
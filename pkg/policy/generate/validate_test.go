@@ -87,3 +87,446 @@ func Test_Validate_Generate_HasAnchors(t *testing.T) {
 		assert.Assert(t, err != nil)
 	}
 }
+
+func Test_Validate_Generate_KindData(t *testing.T) {
+	testcases := []struct {
+		description  string
+		generate     []byte
+		expectedPath string
+	}{
+		{
+			description:  "valid ConfigMap keys",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"app.properties":"a=b","KEY_1":"value"}}}`),
+			expectedPath: "",
+		},
+		{
+			description:  "invalid ConfigMap key",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"invalid key":"value"}}}`),
+			expectedPath: "data.data.invalid key",
+		},
+		{
+			description:  "ConfigMap binaryData is not base64",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"binaryData":{"cert":"not-base64!"}}}`),
+			expectedPath: "data.binaryData.cert",
+		},
+		{
+			description:  "valid Secret data",
+			generate:     []byte(`{"kind":"Secret","name":"secret","namespace":"default","data":{"data":{"password":"cGFzc3dvcmQ="},"stringData":{"username":"admin"}}}`),
+			expectedPath: "",
+		},
+		{
+			description:  "Secret data is not base64",
+			generate:     []byte(`{"kind":"Secret","name":"secret","namespace":"default","data":{"data":{"password":"password!"}}}`),
+			expectedPath: "data.data.password",
+		},
+		{
+			description:  "Secret data uses a variable",
+			generate:     []byte(`{"kind":"Secret","name":"secret","namespace":"default","data":{"data":{"token":"{{request.object.metadata.uid}}"}}}`),
+			expectedPath: "",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err)
+
+		checker := NewFakeGenerate(genRule)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_Generate_Status(t *testing.T) {
+	testcases := []struct {
+		description  string
+		generate     []byte
+		expectedPath string
+	}{
+		{
+			description:  "data without status",
+			generate:     []byte(`{"kind":"NetworkPolicy","name":"deny-all","namespace":"default","data":{"spec":{"podSelector":{}}}}`),
+			expectedPath: "",
+		},
+		{
+			description:  "data with status",
+			generate:     []byte(`{"kind":"Deployment","name":"nginx","namespace":"default","data":{"spec":{"replicas":1},"status":{"replicas":1}}}`),
+			expectedPath: "data.status",
+		},
+		{
+			description:  "nested status field",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"status":"ready"}}}`),
+			expectedPath: "",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err)
+
+		checker := NewFakeGenerate(genRule)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_Generate_ServerAssignedFields(t *testing.T) {
+	testcases := []struct {
+		description  string
+		generate     []byte
+		expectedPath string
+	}{
+		{
+			description: "clean data",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"metadata":{"labels":{"app":"cm"}},"data":{"key":"value"}}}`),
+		},
+		{
+			description:  "resourceVersion",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"metadata":{"resourceVersion":"12345"},"data":{"key":"value"}}}`),
+			expectedPath: "data.metadata.resourceVersion",
+		},
+		{
+			description:  "uid",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"metadata":{"uid":"0b7c5a8e-3f1d-4c2a-9e8b-1a2b3c4d5e6f"},"data":{"key":"value"}}}`),
+			expectedPath: "data.metadata.uid",
+		},
+		{
+			description:  "selfLink",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"metadata":{"selfLink":"/api/v1/namespaces/default/configmaps/cm"},"data":{"key":"value"}}}`),
+			expectedPath: "data.metadata.selfLink",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err)
+
+		checker := NewFakeGenerate(genRule)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_Generate_DataTypeMeta(t *testing.T) {
+	testcases := []struct {
+		description  string
+		generate     []byte
+		expectedPath string
+	}{
+		{
+			description: "matching kind and apiVersion",
+			generate:    []byte(`{"apiVersion":"networking.k8s.io/v1","kind":"NetworkPolicy","name":"deny-all","namespace":"default","data":{"apiVersion":"networking.k8s.io/v1","kind":"NetworkPolicy","spec":{"podSelector":{}}}}`),
+		},
+		{
+			description: "apiVersion not declared in the rule",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"apiVersion":"v1","kind":"ConfigMap","data":{"key":"value"}}}`),
+		},
+		{
+			description:  "mismatching kind",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"kind":"Secret","data":{"key":"dmFsdWU="}}}`),
+			expectedPath: "data.kind",
+		},
+		{
+			description:  "mismatching apiVersion",
+			generate:     []byte(`{"apiVersion":"networking.k8s.io/v1","kind":"NetworkPolicy","name":"deny-all","namespace":"default","data":{"apiVersion":"extensions/v1beta1","spec":{"podSelector":{}}}}`),
+			expectedPath: "data.apiVersion",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err)
+
+		checker := NewFakeGenerate(genRule)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_Generate_DataNamespace(t *testing.T) {
+	testcases := []struct {
+		description  string
+		generate     []byte
+		expectedPath string
+	}{
+		{
+			description: "matching namespaces",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"metadata":{"namespace":"default"},"data":{"key":"value"}}}`),
+		},
+		{
+			description:  "conflicting namespaces",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"metadata":{"namespace":"prod"},"data":{"key":"value"}}}`),
+			expectedPath: "data.metadata.namespace",
+		},
+		{
+			description: "data namespace only",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","data":{"metadata":{"namespace":"prod"},"data":{"key":"value"}}}`),
+		},
+		{
+			description:  "conflicting variable",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.name}}","data":{"metadata":{"namespace":"default"}}}`),
+			expectedPath: "data.metadata.namespace",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err)
+
+		checker := NewFakeGenerate(genRule)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_Generate_CloneList(t *testing.T) {
+	testcases := []struct {
+		description  string
+		generate     []byte
+		expectedPath string
+		expectError  bool
+	}{
+		{
+			description: "valid cloneList",
+			generate:    []byte(`{"namespace":"{{request.object.metadata.name}}","synchronize":true,"cloneList":{"namespace":"default","kinds":["Secret","ConfigMap"],"selector":{"matchLabels":{"allowedToBeCloned":"true"}}}}`),
+		},
+		{
+			description:  "cloneList with data",
+			generate:     []byte(`{"namespace":"default","data":{"data":{"key":"value"}},"cloneList":{"namespace":"default","kinds":["Secret"]}}`),
+			expectedPath: "cloneList",
+			expectError:  true,
+		},
+		{
+			description:  "cloneList with clone",
+			generate:     []byte(`{"namespace":"default","clone":{"namespace":"default","name":"source"},"cloneList":{"namespace":"default","kinds":["Secret"]}}`),
+			expectedPath: "cloneList",
+			expectError:  true,
+		},
+		{
+			description:  "cloneList without kinds",
+			generate:     []byte(`{"namespace":"default","cloneList":{"namespace":"default"}}`),
+			expectedPath: "cloneList.kinds",
+			expectError:  true,
+		},
+		{
+			description:  "cloneList with invalid selector",
+			generate:     []byte(`{"namespace":"default","cloneList":{"namespace":"default","kinds":["Secret"],"selector":{"matchExpressions":[{"key":"app","operator":"Equals","values":["nginx"]}]}}}`),
+			expectedPath: "cloneList.selector",
+			expectError:  true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err, testcase.description)
+
+		checker := NewFakeGenerate(genRule)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_validateGenerateExisting(t *testing.T) {
+	testcases := []struct {
+		description string
+		generate    []byte
+		expectError bool
+	}{
+		{
+			description: "generateExisting with synchronize",
+			generate:    []byte(`{"kind":"ClusterRole","name":"role","generateExisting":true,"synchronize":true,"data":{}}`),
+		},
+		{
+			description: "generateExisting for a namespaced resource",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","generateExisting":true,"data":{}}`),
+		},
+		{
+			description: "generateExisting without synchronize for a cluster-scoped resource",
+			generate:    []byte(`{"kind":"ClusterRole","name":"role","generateExisting":true,"data":{}}`),
+			expectError: true,
+		},
+		{
+			description: "without generateExisting",
+			generate:    []byte(`{"kind":"ClusterRole","name":"role","data":{}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err, testcase.description)
+
+		err = validateGenerateExisting(genRule)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_WarnKindData_RBAC(t *testing.T) {
+	testcases := []struct {
+		description  string
+		generate     []byte
+		expectedPath string
+	}{
+		{
+			description:  "wildcard ClusterRole",
+			generate:     []byte(`{"kind":"ClusterRole","name":"admin","data":{"rules":[{"apiGroups":[""],"resources":["pods"],"verbs":["get"]},{"apiGroups":["*"],"resources":["*"],"verbs":["*"]}]}}`),
+			expectedPath: "rules[1]",
+		},
+		{
+			description:  "scoped Role",
+			generate:     []byte(`{"kind":"Role","name":"viewer","namespace":"default","data":{"rules":[{"apiGroups":[""],"resources":["*"],"verbs":["get","list","watch"]}]}}`),
+			expectedPath: "",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err)
+
+		path, err := WarnKindData(genRule.Kind, genRule.Data)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_Generate_CloneExclude(t *testing.T) {
+	testcases := []struct {
+		description  string
+		generate     []byte
+		expectedPath string
+	}{
+		{
+			description: "Secret keys",
+			generate:    []byte(`{"kind":"Secret","name":"regcred","namespace":"{{request.object.metadata.name}}","clone":{"namespace":"default","name":"regcred","exclude":["/data/admin-token","/metadata/annotations/kubectl.kubernetes.io~1last-applied-configuration"]}}`),
+		},
+		{
+			description: "unknown kind",
+			generate:    []byte(`{"kind":"Widget","name":"w","namespace":"default","clone":{"namespace":"default","name":"w","exclude":["/spec/owner"]}}`),
+		},
+		{
+			description:  "not a pointer",
+			generate:     []byte(`{"kind":"Secret","name":"regcred","namespace":"default","clone":{"namespace":"default","name":"regcred","exclude":["data.token"]}}`),
+			expectedPath: "clone.exclude[0]",
+		},
+		{
+			description:  "field missing from the kind",
+			generate:     []byte(`{"kind":"Secret","name":"regcred","namespace":"default","clone":{"namespace":"default","name":"regcred","exclude":["/data/token","/spec/token"]}}`),
+			expectedPath: "clone.exclude[1]",
+		},
+		{
+			description:  "required field",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","clone":{"namespace":"default","name":"cm","exclude":["/metadata/name"]}}`),
+			expectedPath: "clone.exclude[0]",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err)
+
+		checker := NewFakeGenerate(genRule)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_Generate_CloneTransformations(t *testing.T) {
+	testcases := []struct {
+		description  string
+		generate     []byte
+		expectedPath string
+	}{
+		{
+			description: "renamed label",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.name}}","clone":{"namespace":"default","name":"cm","exclude":["/metadata/labels/owner"],"transformations":[{"path":"/metadata/labels/team","value":"platform"},{"path":"/data/env","value":"{{request.object.metadata.name}}"}]}}`),
+		},
+		{
+			description:  "invalid path",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","clone":{"namespace":"default","name":"cm","transformations":[{"path":"metadata.labels.team","value":"platform"}]}}`),
+			expectedPath: "clone.transformations[0].path",
+		},
+		{
+			description:  "missing value",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","clone":{"namespace":"default","name":"cm","transformations":[{"path":"/data/env"}]}}`),
+			expectedPath: "clone.transformations[0].value",
+		},
+		{
+			description:  "status",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","clone":{"namespace":"default","name":"cm","transformations":[{"path":"/data/env","value":"prod"},{"path":"/status/phase","value":"Ready"}]}}`),
+			expectedPath: "clone.transformations[1].path",
+		},
+		{
+			description:  "required field",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","clone":{"namespace":"default","name":"cm","transformations":[{"path":"/metadata/name","value":"other"}]}}`),
+			expectedPath: "clone.transformations[0].path",
+		},
+		{
+			description:  "excluded field",
+			generate:     []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","clone":{"namespace":"default","name":"cm","exclude":["/data"],"transformations":[{"path":"/data/env","value":"prod"}]}}`),
+			expectedPath: "clone.transformations[0].path",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err)
+
+		checker := NewFakeGenerate(genRule)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_Generate_LiteralData(t *testing.T) {
+	testcases := []struct {
+		description string
+		generate    []byte
+	}{
+		{
+			description: "comparison operator literals",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"index.html":"<html><body>ok</body></html>","limit":">= 2Gx"}}}`),
+		},
+		{
+			description: "wildcard literals",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"crontab":"*/5 * * * * /usr/bin/backup > /dev/null"}}}`),
+		},
+		{
+			description: "regex operator literals",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"filter":"regex:(","pattern":"regex:^[a-z]+$"}}}`),
+		},
+		{
+			description: "operator family literals",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"rule":">5 | <3","window":">1h & <2Gi"}}}`),
+		},
+		{
+			description: "keys with parentheses",
+			generate:    []byte(`{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"metadata":{"annotations":{"example.com/fn(x)":"x + 1","max(a":"b"}}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &genRule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := NewFakeGenerate(genRule).Validate()
+		assert.NilError(t, err, testcase.description)
+		assert.Equal(t, path, "", testcase.description)
+	}
+}
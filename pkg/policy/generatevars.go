@@ -0,0 +1,122 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+)
+
+// builtInVariableRoots are the variables available to rules besides context entries
+var builtInVariableRoots = []string{"images", "request", "serviceAccountName", "serviceAccountNamespace"}
+
+// foreachVariableRoots are the variables available to the patterns of foreach declarations
+// besides the built-in variables and context entries
+var foreachVariableRoots = []string{"element", "elementIndex"}
+
+var regexVariableRoot = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*(\()?`)
+
+// validateGenerateDataVariables returns the path of the first value of the generate data
+// referencing a variable which is neither built-in nor declared in the rule context.
+// Variables starting with a JMESPath function call or a literal are not checked.
+func validateGenerateDataVariables(rule kyverno.Rule) (string, error) {
+	check := checkVariableRoots(builtInVariableRoots, rule.Context)
+	for i, generation := range rule.GenerateTargets() {
+		if generation.Data == nil {
+			continue
+		}
+
+		if path, err := walkVariables(generation.Data, "", check); err != nil {
+			if path != "" {
+				return generateTargetPath(rule, i) + ".data." + path, err
+			}
+
+			return generateTargetPath(rule, i) + ".data", err
+		}
+	}
+
+	return "", nil
+}
+
+// validateForEachVariables returns the path of the first value of the foreach patterns
+// referencing a variable which is neither an iteration variable, built-in nor declared in
+// the rule context
+func validateForEachVariables(rule kyverno.Rule) (string, error) {
+	roots := append(append([]string{}, foreachVariableRoots...), builtInVariableRoots...)
+	sort.Strings(roots)
+	check := checkVariableRoots(roots, rule.Context)
+	for i, forEach := range rule.Validation.ForEach {
+		if path, err := walkVariables(forEach.Pattern, "", check); err != nil {
+			if path != "" {
+				return fmt.Sprintf("validate.foreach[%d].pattern.%s", i, path), err
+			}
+
+			return fmt.Sprintf("validate.foreach[%d].pattern", i), err
+		}
+	}
+
+	return "", nil
+}
+
+// checkVariableRoots returns a check failing for variables whose root is neither one of the
+// roots nor a context entry. Variables starting with a JMESPath function call or a literal
+// are not checked.
+func checkVariableRoots(roots []string, context []kyverno.ContextEntry) func(variable string) error {
+	available := make(map[string]bool, len(roots)+len(context))
+	for _, root := range roots {
+		available[root] = true
+	}
+
+	for _, entry := range context {
+		available[strings.SplitN(entry.Name, ".", 2)[0]] = true
+	}
+
+	return func(variable string) error {
+		groups := regexVariableRoot.FindStringSubmatch(variable[2 : len(variable)-2])
+		if groups == nil || groups[2] != "" || available[groups[1]] {
+			return nil
+		}
+
+		return fmt.Errorf("unknown variable %s, expected one of %s or a context entry", variable, strings.Join(roots, ", "))
+	}
+}
+
+// walkVariables calls check for each variable in the string values of the element, and
+// returns the path of the first value failing the check
+func walkVariables(element interface{}, path string, check func(variable string) error) (string, error) {
+	switch typed := element.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+
+			if p, err := walkVariables(typed[key], keyPath, check); err != nil {
+				return p, err
+			}
+		}
+	case []interface{}:
+		for i, value := range typed {
+			if p, err := walkVariables(value, fmt.Sprintf("%s[%d]", path, i), check); err != nil {
+				return p, err
+			}
+		}
+	case string:
+		for _, variable := range regexVariable.FindAllString(typed, -1) {
+			if err := check(variable); err != nil {
+				return path, err
+			}
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,93 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateGenerateDataVariables(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		path        string
+		err         string
+	}{
+		{
+			description: "label set to the trigger name",
+			rule:        []byte(`{"name":"gen","generate":{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"metadata":{"labels":{"owner":"{{request.object.metadata.name}}"}}}}}`),
+		},
+		{
+			description: "context entry and function call",
+			rule:        []byte(`{"name":"gen","context":[{"name":"team","configMap":{"name":"teams","namespace":"default"}}],"generate":{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"team":"{{team.data.name}}","length":"{{ to_string(length(request.object.metadata.name)) }}"}}}}`),
+		},
+		{
+			description: "unknown variable",
+			rule:        []byte(`{"name":"gen","generate":{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"metadata":{"labels":{"app":"web","owner":"team-{{owner.name}}"}}}}}`),
+			path:        "generate.data.metadata.labels.owner",
+			err:         "unknown variable {{owner.name}}, expected one of images, request, serviceAccountName, serviceAccountNamespace or a context entry",
+		},
+		{
+			description: "unknown variable in a list",
+			rule:        []byte(`{"name":"gen","generate":{"kind":"Role","name":"r","namespace":"default","data":{"rules":[{"resourceNames":["{{ target }}"]}]}}}`),
+			path:        "generate.data.rules[0].resourceNames[0]",
+			err:         "unknown variable {{ target }}, expected one of images, request, serviceAccountName, serviceAccountNamespace or a context entry",
+		},
+		{
+			description: "unknown variable in generations",
+			rule:        []byte(`{"name":"gen","generations":[{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"owner":"{{request.object.metadata.name}}"}}},{"kind":"Secret","name":"s","namespace":"default","data":{"data":{"team":"{{team}}"}}}]}`),
+			path:        "generations[1].data.data.team",
+			err:         "unknown variable {{team}}, expected one of images, request, serviceAccountName, serviceAccountNamespace or a context entry",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateGenerateDataVariables(rule)
+		assert.Equal(t, path, testcase.path, testcase.description)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
+
+func Test_validateForEachVariables(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		path        string
+		err         string
+	}{
+		{
+			description: "element references",
+			rule:        []byte(`{"name":"r","validate":{"foreach":[{"list":"request.object.spec.containers","pattern":{"image":"{{element.name}}:*","env":[{"name":"INDEX","value":"{{ elementIndex }}"},{"name":"NS","value":"{{request.namespace}}"}]}}]}}`),
+		},
+		{
+			description: "undefined iteration variable",
+			rule:        []byte(`{"name":"r","validate":{"foreach":[{"list":"request.object.spec.containers","pattern":{"name":"*"}},{"list":"request.object.spec.volumes","pattern":{"name":"{{item.name}}"}}]}}`),
+			path:        "validate.foreach[1].pattern.name",
+			err:         "unknown variable {{item.name}}, expected one of element, elementIndex, images, request, serviceAccountName, serviceAccountNamespace or a context entry",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateForEachVariables(rule)
+		assert.Equal(t, path, testcase.path, testcase.description)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"github.com/kyverno/kyverno/pkg/engine/variables"
+)
+
+// APIVersionLookup returns the API versions serving the kind, e.g. "v1" or "apps/v1".
+// nil is returned if the kind is unknown.
+type APIVersionLookup func(kind string) []string
+
+// multiGroupKinds are the built-in kinds served by more than one API group. Kinds
+// only kept in the removed extensions/v1beta1 group, e.g. NetworkPolicy, are not listed.
+var multiGroupKinds = map[string][]string{
+	"Event": {"v1", "events.k8s.io/v1"},
+}
+
+// DefaultAPIVersionLookup resolves the built-in kinds served by more than one API group
+func DefaultAPIVersionLookup(kind string) []string {
+	return multiGroupKinds[kind]
+}
+
+// validateGenerateAPIVersion returns an error if the generated kind is served by more than
+// one API group and the apiVersion is not set, as the resource to create is ambiguous
+func validateGenerateAPIVersion(generation kyverno.Generation, lookup APIVersionLookup) error {
+	kind := generation.Kind
+	if kind == "" || generation.APIVersion != "" || variables.IsVariable(kind) {
+		return nil
+	}
+
+	apiVersions := lookup(kind)
+	groups := make(map[string]bool)
+	for _, apiVersion := range apiVersions {
+		group := ""
+		if i := strings.Index(apiVersion, "/"); i >= 0 {
+			group = apiVersion[:i]
+		}
+		groups[group] = true
+	}
+
+	if len(groups) < 2 {
+		return nil
+	}
+
+	sorted := append([]string{}, apiVersions...)
+	sort.Strings(sorted)
+	return fmt.Errorf("kind %s is served by multiple API groups %v, set apiVersion to select one", kind, sorted)
+}
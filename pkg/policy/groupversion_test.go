@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateGenerateAPIVersion(t *testing.T) {
+	crdLookup := func(kind string) []string {
+		if kind == "Certificate" {
+			return []string{"cert-manager.io/v1", "certmanager.k8s.io/v1alpha1"}
+		}
+		return nil
+	}
+
+	testcases := []struct {
+		description string
+		generate    []byte
+		lookup      APIVersionLookup
+		expectedErr string
+	}{
+		{
+			description: "ambiguous kind without apiVersion",
+			generate:    []byte(`{"kind":"Event","name":"created","namespace":"default","data":{}}`),
+			lookup:      DefaultAPIVersionLookup,
+			expectedErr: "kind Event is served by multiple API groups [events.k8s.io/v1 v1], set apiVersion to select one",
+		},
+		{
+			description: "ambiguous kind with apiVersion",
+			generate:    []byte(`{"apiVersion":"events.k8s.io/v1","kind":"Event","name":"created","namespace":"default","data":{}}`),
+			lookup:      DefaultAPIVersionLookup,
+		},
+		{
+			description: "unambiguous kind",
+			generate:    []byte(`{"kind":"NetworkPolicy","name":"deny","namespace":"default","data":{}}`),
+			lookup:      DefaultAPIVersionLookup,
+		},
+		{
+			description: "ambiguous custom resource from the injected lookup",
+			generate:    []byte(`{"kind":"Certificate","name":"cert","namespace":"default","data":{}}`),
+			lookup:      crdLookup,
+			expectedErr: "kind Certificate is served by multiple API groups [cert-manager.io/v1 certmanager.k8s.io/v1alpha1], set apiVersion to select one",
+		},
+		{
+			description: "multiple versions of the same group",
+			generate:    []byte(`{"kind":"Widget","name":"w","namespace":"default","data":{}}`),
+			lookup:      func(string) []string { return []string{"example.com/v1", "example.com/v1beta1"} },
+		},
+	}
+
+	for _, testcase := range testcases {
+		var generation kyverno.Generation
+		err := json.Unmarshal(testcase.generate, &generation)
+		assert.NilError(t, err, testcase.description)
+
+		err = validateGenerateAPIVersion(generation, testcase.lookup)
+		if testcase.expectedErr == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.expectedErr, testcase.description)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// validateRuleLabelKeys returns the path of the first resource description of the match
+// and exclude blocks using a deprecated label or annotation key
+func validateRuleLabelKeys(rule kyverno.Rule, deprecated map[string]string) (string, error) {
+	if len(deprecated) == 0 {
+		return "", nil
+	}
+
+	blocks := []struct {
+		path string
+		rd   kyverno.ResourceDescription
+		any  []kyverno.ResourceDescription
+		all  []kyverno.ResourceDescription
+	}{
+		{"match", rule.MatchResources.ResourceDescription, rule.MatchResources.Any, rule.MatchResources.All},
+		{"exclude", rule.ExcludeResources.ResourceDescription, rule.ExcludeResources.Any, rule.ExcludeResources.All},
+	}
+
+	for _, block := range blocks {
+		if path, err := validateLabelKeys(block.rd, deprecated); err != nil {
+			return fmt.Sprintf("%s.resources.%s", block.path, path), err
+		}
+
+		for i, rd := range block.any {
+			if path, err := validateLabelKeys(rd, deprecated); err != nil {
+				return fmt.Sprintf("%s.any[%d].%s", block.path, i, path), err
+			}
+		}
+
+		for i, rd := range block.all {
+			if path, err := validateLabelKeys(rd, deprecated); err != nil {
+				return fmt.Sprintf("%s.all[%d].%s", block.path, i, path), err
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// validateLabelKeys returns an error if the selectors or annotations of the resource
+// description use a deprecated key, suggesting its replacement
+func validateLabelKeys(rd kyverno.ResourceDescription, deprecated map[string]string) (string, error) {
+	keys := map[string][]string{"annotations": mapKeys(rd.Annotations)}
+	if rd.Selector != nil {
+		keys["selector"] = selectorKeys(rd.Selector)
+	}
+
+	if rd.NamespaceSelector != nil {
+		keys["namespaceSelector"] = selectorKeys(rd.NamespaceSelector)
+	}
+
+	for _, path := range []string{"annotations", "selector", "namespaceSelector"} {
+		for _, key := range keys[path] {
+			if replacement, ok := deprecated[key]; ok {
+				return path, fmt.Errorf("key %s is deprecated, use %s instead", key, replacement)
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// selectorKeys returns the sorted keys of the match labels and expressions of the selector
+func selectorKeys(selector *metav1.LabelSelector) []string {
+	keys := mapKeys(selector.MatchLabels)
+	for _, expression := range selector.MatchExpressions {
+		keys = append(keys, expression.Key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,58 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateRuleLabelKeys(t *testing.T) {
+	deprecated := map[string]string{"app": "app.kubernetes.io/name"}
+
+	testcases := []struct {
+		description  string
+		rule         []byte
+		deprecated   map[string]string
+		expectedPath string
+	}{
+		{
+			description:  "deprecated key in match selector",
+			rule:         []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"app":"nginx"}}}}}`),
+			deprecated:   deprecated,
+			expectedPath: "match.resources.selector",
+		},
+		{
+			description:  "deprecated key in exclude all expression",
+			rule:         []byte(`{"name":"r","match":{"any":[{"kinds":["Pod"]}]},"exclude":{"all":[{"kinds":["Pod"]},{"namespaceSelector":{"matchExpressions":[{"key":"app","operator":"Exists"}]}}]}}`),
+			deprecated:   deprecated,
+			expectedPath: "exclude.all[1].namespaceSelector",
+		},
+		{
+			description:  "deprecated key in annotations",
+			rule:         []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"],"annotations":{"app":"*"}}}}`),
+			deprecated:   deprecated,
+			expectedPath: "match.resources.annotations",
+		},
+		{
+			description: "non-deprecated key",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"app.kubernetes.io/name":"nginx"}}}}}`),
+			deprecated:  deprecated,
+		},
+		{
+			description: "off by default",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"],"selector":{"matchLabels":{"app":"nginx"}}}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateRuleLabelKeys(rule, testcase.deprecated)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+	}
+}
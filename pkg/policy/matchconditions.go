@@ -0,0 +1,41 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	policycommon "github.com/kyverno/kyverno/pkg/policy/common"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// validateMatchConditions checks the match conditions have unique qualified names and
+// non-empty expressions, and reports the syntax errors of the expressions with the name of
+// the condition
+func validateMatchConditions(conditions []kyverno.MatchCondition) (string, error) {
+	names := make(map[string]bool, len(conditions))
+	for i, condition := range conditions {
+		if condition.Name == "" {
+			return fmt.Sprintf("matchConditions[%d].name", i), fmt.Errorf("name cannot be empty")
+		}
+
+		if errs := validation.IsQualifiedName(condition.Name); len(errs) > 0 {
+			return fmt.Sprintf("matchConditions[%d].name", i), fmt.Errorf("invalid name %s: %s", condition.Name, strings.Join(errs, ", "))
+		}
+
+		if names[condition.Name] {
+			return fmt.Sprintf("matchConditions[%d].name", i), fmt.Errorf("duplicate name %s", condition.Name)
+		}
+		names[condition.Name] = true
+
+		if strings.TrimSpace(condition.Expression) == "" {
+			return fmt.Sprintf("matchConditions[%d].expression", i), fmt.Errorf("expression cannot be empty")
+		}
+
+		if err := policycommon.PrecheckExpressionSyntax(condition.Expression); err != nil {
+			return fmt.Sprintf("matchConditions[%d].expression", i), fmt.Errorf("condition %s: expression syntax pre-check failed: %v", condition.Name, err)
+		}
+	}
+
+	return "", nil
+}
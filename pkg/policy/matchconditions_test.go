@@ -0,0 +1,74 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateMatchConditions(t *testing.T) {
+	testcases := []struct {
+		description string
+		conditions  []byte
+		path        string
+		err         string
+	}{
+		{
+			description: "unique names",
+			conditions:  []byte(`[{"name":"exclude-leases","expression":"!(request.resource.group == 'coordination.k8s.io' && request.resource.resource == 'leases')"},{"name":"not-kube-system","expression":"request.namespace != \"kube-system\""}]`),
+		},
+		{
+			description: "duplicate names",
+			conditions:  []byte(`[{"name":"a","expression":"true"},{"name":"a","expression":"false"}]`),
+			path:        "matchConditions[1].name",
+			err:         "duplicate name a",
+		},
+		{
+			description: "empty expression",
+			conditions:  []byte(`[{"name":"a","expression":" "}]`),
+			path:        "matchConditions[0].expression",
+			err:         "expression cannot be empty",
+		},
+		{
+			description: "invalid name",
+			conditions:  []byte(`[{"name":"not valid","expression":"true"}]`),
+			path:        "matchConditions[0].name",
+		},
+		{
+			description: "unbalanced parentheses",
+			conditions:  []byte(`[{"name":"leases","expression":"!(request.resource.resource == 'leases'"}]`),
+			path:        "matchConditions[0].expression",
+			err:         "condition leases: expression syntax pre-check failed: missing closing bracket for '('",
+		},
+		{
+			description: "unterminated string",
+			conditions:  []byte(`[{"name":"ns","expression":"request.namespace != 'kube-system"}]`),
+			path:        "matchConditions[0].expression",
+			err:         "condition ns: expression syntax pre-check failed: unterminated string literal at offset 21",
+		},
+		{
+			description: "trailing operator",
+			conditions:  []byte(`[{"name":"ns","expression":"request.namespace != 'default' &&"}]`),
+			path:        "matchConditions[0].expression",
+			err:         "condition ns: expression syntax pre-check failed: expression ends with operator '&'",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var conditions []kyverno.MatchCondition
+		err := json.Unmarshal(testcase.conditions, &conditions)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateMatchConditions(conditions)
+		assert.Equal(t, path, testcase.path, testcase.description)
+		if testcase.path == "" {
+			assert.NilError(t, err, testcase.description)
+		} else if testcase.err != "" {
+			assert.Error(t, err, testcase.err, testcase.description)
+		} else {
+			assert.Assert(t, err != nil, testcase.description)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
+	"github.com/kyverno/kyverno/pkg/engine/validate"
+	"github.com/kyverno/kyverno/pkg/engine/variables"
+	"github.com/minio/minio/pkg/wildcard"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// MinimalFailingResource synthesizes a small resource that is selected by the match block
+// of the validate rule but violates its pattern, to show authors what the rule rejects.
+// The resource is derived from the anchor-stripped pattern with a single field changed or
+// removed. Only equality, presence, negation, wildcard and numeric comparison values are
+// supported.
+func MinimalFailingResource(policy kyverno.ClusterPolicy, ruleName string) (map[string]interface{}, error) {
+	var rule *kyverno.Rule
+	for i := range policy.Spec.Rules {
+		if policy.Spec.Rules[i].Name == ruleName {
+			rule = &policy.Spec.Rules[i]
+			break
+		}
+	}
+
+	if rule == nil {
+		return nil, fmt.Errorf("rule %s not found", ruleName)
+	}
+
+	pattern, ok := rule.Validation.Pattern.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("rule %s has no validate pattern", ruleName)
+	}
+
+	for target := 0; ; target++ {
+		s := synthesizer{target: target}
+		resource, err := s.object(pattern, true)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %v", ruleName, err)
+		}
+
+		// every field of the pattern was tried
+		if s.current <= target {
+			return nil, fmt.Errorf("rule %s: unable to synthesize a resource violating the pattern", ruleName)
+		}
+
+		if err := addMatchedMetadata(resource, rule.MatchResources); err != nil {
+			return nil, fmt.Errorf("rule %s: %v", ruleName, err)
+		}
+
+		if _, err := validate.ValidateResourceWithPattern(log.Log, resource, pattern); err != nil {
+			return resource, nil
+		}
+	}
+}
+
+// addMatchedMetadata sets the kind, name, namespace and labels selected by the match block
+func addMatchedMetadata(resource map[string]interface{}, match kyverno.MatchResources) error {
+	kind := ""
+	for _, k := range matchedKinds(match) {
+		if !HasWildcard(k) {
+			kind = k
+			break
+		}
+	}
+
+	if kind == "" {
+		return fmt.Errorf("match does not select a specific kind")
+	}
+	resource["kind"] = kind
+
+	metadata, ok := resource["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = make(map[string]interface{})
+		resource["metadata"] = metadata
+	}
+
+	rd := match.ResourceDescription
+	if _, ok := metadata["name"]; !ok {
+		metadata["name"] = "example"
+		if rd.Name != "" {
+			metadata["name"] = satisfyWildcard(rd.Name)
+		}
+	}
+
+	if _, ok := metadata["namespace"]; !ok && len(rd.Namespaces) > 0 {
+		metadata["namespace"] = satisfyWildcard(rd.Namespaces[0])
+	}
+
+	if rd.Selector != nil && len(rd.Selector.MatchLabels) > 0 {
+		labels, ok := metadata["labels"].(map[string]interface{})
+		if !ok {
+			labels = make(map[string]interface{})
+			metadata["labels"] = labels
+		}
+
+		for k, v := range rd.Selector.MatchLabels {
+			if _, ok := labels[k]; !ok {
+				labels[satisfyWildcard(k)] = satisfyWildcard(v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// synthesizer builds a resource satisfying a pattern, except for the target-th field
+// in traversal order which is violated. Fields under anchors are never violated, as
+// failing an anchor condition skips the pattern instead of failing it.
+type synthesizer struct {
+	target  int
+	current int
+}
+
+// next returns true if the current field is the one to violate
+func (s *synthesizer) next() bool {
+	violate := s.current == s.target
+	s.current++
+	return violate
+}
+
+func (s *synthesizer) object(pattern map[string]interface{}, violable bool) (map[string]interface{}, error) {
+	keys := make([]string, 0, len(pattern))
+	for key := range pattern {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	object := make(map[string]interface{})
+	for _, key := range keys {
+		value := pattern[key]
+		if commonAnchors.IsNegationAnchor(key) {
+			if violable && s.next() {
+				field, _ := commonAnchors.RemoveAnchor(key)
+				object[field] = "example"
+			}
+			continue
+		}
+
+		field, _ := commonAnchors.RemoveAnchor(key)
+		plain := field == key
+		if plain && violable && isPresencePattern(value) {
+			if !s.next() {
+				object[field] = "example"
+			}
+			continue
+		}
+
+		result, err := s.value(value, violable && plain)
+		if err != nil {
+			return nil, err
+		}
+		object[field] = result
+	}
+
+	return object, nil
+}
+
+func (s *synthesizer) value(pattern interface{}, violable bool) (interface{}, error) {
+	switch typed := pattern.(type) {
+	case map[string]interface{}:
+		return s.object(typed, violable)
+	case []interface{}:
+		if len(typed) == 0 {
+			return []interface{}{}, nil
+		}
+
+		if element, ok := typed[0].(map[string]interface{}); ok {
+			object, err := s.object(element, violable)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{object}, nil
+		}
+
+		return typed, nil
+	case string:
+		if variables.IsVariable(typed) {
+			return nil, fmt.Errorf("variables are not supported: %s", typed)
+		}
+
+		if strings.HasPrefix(typed, validate.RegexPrefix) {
+			return nil, fmt.Errorf("regex values are not supported: %s", typed)
+		}
+
+		if violable && s.next() {
+			return violateString(typed)
+		}
+		return satisfyString(typed)
+	case bool:
+		if violable && s.next() {
+			return !typed, nil
+		}
+		return typed, nil
+	case float64:
+		if violable && s.next() {
+			return typed + 1, nil
+		}
+		return typed, nil
+	case int64:
+		if violable && s.next() {
+			return typed + 1, nil
+		}
+		return typed, nil
+	default:
+		return typed, nil
+	}
+}
+
+func isPresencePattern(value interface{}) bool {
+	str, ok := value.(string)
+	return ok && (str == "*" || str == "?*")
+}
+
+// satisfyString returns a value matched by the string pattern
+func satisfyString(pattern string) (interface{}, error) {
+	if strings.Contains(pattern, "|") {
+		return satisfyString(strings.TrimSpace(strings.Split(pattern, "|")[0]))
+	}
+
+	if strings.HasPrefix(pattern, "!") {
+		negated := strings.TrimSpace(strings.TrimPrefix(pattern, "!"))
+		for _, candidate := range []string{"example", "example-0"} {
+			if !wildcard.Match(negated, candidate) {
+				return candidate, nil
+			}
+		}
+		return nil, fmt.Errorf("unsupported pattern %s", pattern)
+	}
+
+	if operator, number, ok := parseComparison(pattern); ok {
+		switch operator {
+		case ">":
+			return number + 1, nil
+		case "<":
+			return number - 1, nil
+		default:
+			return number, nil
+		}
+	}
+
+	return satisfyWildcard(pattern), nil
+}
+
+// violateString returns a value not matched by the string pattern
+func violateString(pattern string) (interface{}, error) {
+	if strings.HasPrefix(pattern, "!") && !strings.Contains(pattern, "|") {
+		return satisfyWildcard(strings.TrimSpace(strings.TrimPrefix(pattern, "!"))), nil
+	}
+
+	if operator, number, ok := parseComparison(pattern); ok {
+		switch operator {
+		case ">", "<":
+			return number, nil
+		case ">=":
+			return number - 1, nil
+		default:
+			return number + 1, nil
+		}
+	}
+
+	for _, candidate := range []string{satisfyWildcard(pattern) + "-invalid", "invalid", ""} {
+		matched := false
+		for _, alternative := range strings.Split(pattern, "|") {
+			if wildcard.Match(strings.TrimSpace(alternative), candidate) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return candidate, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported pattern %s", pattern)
+}
+
+// satisfyWildcard replaces the wildcards of the pattern with literal characters
+func satisfyWildcard(pattern string) string {
+	if pattern == "*" || pattern == "?*" {
+		return "example"
+	}
+
+	return strings.ReplaceAll(strings.ReplaceAll(pattern, "*", "example"), "?", "x")
+}
+
+// parseComparison parses numeric comparison patterns such as ">=2"
+func parseComparison(pattern string) (string, int64, bool) {
+	for _, operator := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(pattern, operator) {
+			number, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(pattern, operator)), 10, 64)
+			if err != nil {
+				return "", 0, false
+			}
+			return operator, number, true
+		}
+	}
+
+	return "", 0, false
+}
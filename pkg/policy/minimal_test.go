@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"github.com/kyverno/kyverno/pkg/engine"
+	"github.com/kyverno/kyverno/pkg/engine/context"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_MinimalFailingResource(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+	}{
+		{
+			description: "equality",
+			rule:        []byte(`{"name":"check","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"hostNetwork":false}}}}`),
+		},
+		{
+			description: "presence",
+			rule:        []byte(`{"name":"check","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"labels":{"app.kubernetes.io/name":"?*"}}}}}`),
+		},
+		{
+			description: "negation with wildcard in array",
+			rule:        []byte(`{"name":"check","match":{"resources":{"kinds":["Pod"],"namespaces":["prod-*"]}},"validate":{"pattern":{"spec":{"containers":[{"name":"*","image":"!*:latest"}]}}}}`),
+		},
+		{
+			description: "negation anchor",
+			rule:        []byte(`{"name":"check","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"X(hostPath)":"null"}}}}`),
+		},
+		{
+			description: "numeric comparison",
+			rule:        []byte(`{"name":"check","match":{"resources":{"kinds":["Deployment"],"selector":{"matchLabels":{"app":"nginx"}}}},"validate":{"pattern":{"spec":{"replicas":">=2"}}}}`),
+		},
+		{
+			description: "conditional anchor",
+			rule:        []byte(`{"name":"check","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"containers":[{"(name)":"nginx","imagePullPolicy":"Always"}]}}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		policy := kyverno.ClusterPolicy{Spec: kyverno.Spec{Rules: []kyverno.Rule{rule}}}
+		policy.SetName("minimal")
+
+		resource, err := MinimalFailingResource(policy, rule.Name)
+		assert.NilError(t, err, testcase.description)
+
+		unstructuredResource := unstructured.Unstructured{Object: resource}
+		err = engine.MatchesResourceDescription(unstructuredResource, rule, kyverno.RequestInfo{}, "", nil, nil)
+		assert.NilError(t, err, testcase.description)
+
+		response := engine.Validate(&engine.PolicyContext{Policy: policy, NewResource: unstructuredResource, JSONContext: context.NewContext()})
+		assert.Equal(t, len(response.PolicyResponse.Rules), 1, testcase.description)
+		assert.Equal(t, response.PolicyResponse.Rules[0].Success, false, testcase.description)
+	}
+}
+
+func Test_MinimalFailingResource_Errors(t *testing.T) {
+	rawPolicy := []byte(`{"metadata":{"name":"minimal"},"spec":{"rules":[
+		{"name":"wildcard-kind","match":{"resources":{"kinds":["*"]}},"validate":{"pattern":{"spec":{"hostNetwork":false}}}},
+		{"name":"deny","match":{"resources":{"kinds":["Pod"]}},"validate":{"deny":{}}},
+		{"name":"variables","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"name":"{{request.object.metadata.namespace}}"}}}}
+	]}}`)
+
+	var policy kyverno.ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	_, err = MinimalFailingResource(policy, "missing")
+	assert.Error(t, err, "rule missing not found")
+
+	_, err = MinimalFailingResource(policy, "wildcard-kind")
+	assert.Error(t, err, "rule wildcard-kind: match does not select a specific kind")
+
+	_, err = MinimalFailingResource(policy, "deny")
+	assert.Error(t, err, "rule deny has no validate pattern")
+
+	_, err = MinimalFailingResource(policy, "variables")
+	assert.ErrorContains(t, err, "variables are not supported")
+}
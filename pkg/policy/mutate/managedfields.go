@@ -0,0 +1,62 @@
+package mutate
+
+import (
+	"fmt"
+	"strings"
+
+	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
+)
+
+// ServerManagedFields are the dot separated paths of fields set by the API server.
+// Overlays writing to them fail when applied. Callers may append paths to the list.
+var ServerManagedFields = []string{
+	"metadata.creationTimestamp",
+	"metadata.deletionTimestamp",
+	"metadata.generation",
+	"metadata.managedFields",
+	"metadata.resourceVersion",
+	"metadata.selfLink",
+	"metadata.uid",
+	"status",
+}
+
+// validateManagedFields returns the path of the first field of the overlay that is
+// managed by the API server
+func validateManagedFields(overlay interface{}) (string, error) {
+	for _, field := range ServerManagedFields {
+		if path, ok := writesField(overlay, strings.Split(field, "."), ""); ok {
+			return path, fmt.Errorf("%s is managed by the API server and cannot be mutated", field)
+		}
+	}
+
+	return "", nil
+}
+
+func writesField(overlay interface{}, field []string, path string) (string, bool) {
+	object, ok := overlay.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	for key, value := range object {
+		// keys under condition anchors are not written
+		if commonAnchors.IsConditionAnchor(key) {
+			continue
+		}
+
+		name, _ := commonAnchors.RemoveAnchor(key)
+		if name != field[0] {
+			continue
+		}
+
+		if len(field) == 1 {
+			return path + key, true
+		}
+
+		if childPath, ok := writesField(value, field[1:], path+key+"."); ok {
+			return childPath, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,100 @@
+package mutate
+
+import (
+	"fmt"
+	"sort"
+
+	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
+	"github.com/minio/minio/pkg/wildcard"
+)
+
+// MergeKeys maps the dot separated paths of mergeable lists, without list indexes, to the
+// field identifying their entries. Paths may contain wildcards. Overlay entries of these
+// lists must set the merge key, otherwise the entry they merge into is ambiguous. Callers
+// may add paths to the registry.
+var MergeKeys = map[string]string{
+	"*spec.containers":                  "name",
+	"*spec.containers.env":              "name",
+	"*spec.containers.ports":            "containerPort",
+	"*spec.containers.volumeMounts":     "mountPath",
+	"*spec.ephemeralContainers":         "name",
+	"*spec.imagePullSecrets":            "name",
+	"*spec.initContainers":              "name",
+	"*spec.initContainers.env":          "name",
+	"*spec.initContainers.volumeMounts": "mountPath",
+	"*spec.volumes":                     "name",
+}
+
+// validateMergeKeys returns the path of the first entry of a mergeable list of the overlay
+// that does not set the merge key of the list. Entries made only of condition anchors are
+// not merged and need no merge key.
+func validateMergeKeys(overlay interface{}, path, fieldPath string) (string, error) {
+	switch typed := overlay.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			name, _ := commonAnchors.RemoveAnchor(key)
+			childPath, childFieldPath := key, name
+			if fieldPath != "" {
+				childPath, childFieldPath = path+"."+key, fieldPath+"."+name
+			}
+
+			if errPath, err := validateMergeKeys(typed[key], childPath, childFieldPath); err != nil {
+				return errPath, err
+			}
+		}
+	case []interface{}:
+		mergeKey := mergeKeyOf(fieldPath)
+		for i, entry := range typed {
+			entryPath := fmt.Sprintf("%s[%d]", path, i)
+			if object, ok := entry.(map[string]interface{}); ok && mergeKey != "" && !hasField(object, mergeKey) && !onlyConditions(object) {
+				return entryPath, fmt.Errorf("entries of %s must set the merge key %s", fieldPath, mergeKey)
+			}
+
+			if errPath, err := validateMergeKeys(entry, entryPath, fieldPath); err != nil {
+				return errPath, err
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// mergeKeyOf returns the merge key registered for the list path, if any
+func mergeKeyOf(fieldPath string) string {
+	for pattern, key := range MergeKeys {
+		if wildcard.Match(pattern, fieldPath) {
+			return key
+		}
+	}
+
+	return ""
+}
+
+// hasField checks if the object sets the field, with or without anchor
+func hasField(object map[string]interface{}, field string) bool {
+	for key := range object {
+		if name, _ := commonAnchors.RemoveAnchor(key); name == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// onlyConditions checks if all the keys of the object are condition anchors, in which case
+// the object is a condition on the list and no entry is merged
+func onlyConditions(object map[string]interface{}) bool {
+	for key := range object {
+		if !commonAnchors.IsConditionAnchor(key) {
+			return false
+		}
+	}
+
+	return len(object) > 0
+}
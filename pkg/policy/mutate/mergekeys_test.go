@@ -0,0 +1,54 @@
+package mutate
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_Validate_Mutate_MergeKeys(t *testing.T) {
+	testcases := []struct {
+		description  string
+		mutate       []byte
+		expectedPath string
+	}{
+		{
+			description: "containers with name",
+			mutate:      []byte(`{"overlay":{"spec":{"containers":[{"name":"nginx","imagePullPolicy":"Always"}]}}}`),
+		},
+		{
+			description: "containers with anchored name",
+			mutate:      []byte(`{"overlay":{"spec":{"containers":[{"(name)":"*","imagePullPolicy":"Always"}]}}}`),
+		},
+		{
+			description:  "containers without name",
+			mutate:       []byte(`{"overlay":{"spec":{"containers":[{"imagePullPolicy":"Always"}]}}}`),
+			expectedPath: "overlay.spec.containers[0]",
+		},
+		{
+			description:  "template volume mounts without mount path",
+			mutate:       []byte(`{"patchStrategicMerge":{"spec":{"template":{"spec":{"containers":[{"name":"nginx","volumeMounts":[{"name":"cache"}]}]}}}}}`),
+			expectedPath: "patchStrategicMerge.spec.template.spec.containers[0].volumeMounts[0]",
+		},
+		{
+			description: "condition on volumes",
+			mutate:      []byte(`{"patchStrategicMerge":{"metadata":{"annotations":{"+(cluster-autoscaler.kubernetes.io/safe-to-evict)":"true"}},"spec":{"volumes":[{"(emptyDir)":{}}]}}}`),
+		},
+		{
+			description: "list without merge key",
+			mutate:      []byte(`{"overlay":{"spec":{"containers":[{"name":"nginx","args":["--verbose"]}]}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var mutate kyverno.Mutation
+		err := json.Unmarshal(testcase.mutate, &mutate)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := NewMutateFactory(mutate).Validate()
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+	}
+}
@@ -0,0 +1,54 @@
+package mutate
+
+import (
+	"fmt"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ValidatePatchOrdering checks each patch of the list targets a path that still exists
+// when it is applied, given the paths added and removed by the other patches. A patch
+// targeting /spec/x/y must not be followed by a patch adding /spec/x, which would
+// overwrite it, nor follow a patch removing /spec/x.
+// It returns the index of the offending patch along with the error.
+func ValidatePatchOrdering(patches []kyverno.Patch) (int, error) {
+	for i, patch := range patches {
+		for j, other := range patches {
+			if i == j || !isParentPath(other.Path, patch.Path) {
+				continue
+			}
+
+			if j < i && other.Operation == "remove" {
+				return i, fmt.Errorf("path %s is targeted after its parent %s is removed by patch %d", patch.Path, other.Path, j)
+			}
+
+			if j > i && other.Operation == "add" {
+				return i, fmt.Errorf("path %s is targeted before its parent %s is added by patch %d", patch.Path, other.Path, j)
+			}
+		}
+	}
+
+	return -1, nil
+}
+
+// isParentPath checks if parent is a strict prefix of the JSON pointer path
+func isParentPath(parent, path string) bool {
+	parent = strings.TrimSuffix(parent, "/")
+	return parent != "" && strings.HasPrefix(path, parent+"/")
+}
+
+// decodePatchesJSON6902 decodes the JSON or YAML patch list, or returns nil if it cannot be parsed
+func decodePatchesJSON6902(patchesJSON6902 string) []kyverno.Patch {
+	if patchesJSON6902 == "" {
+		return nil
+	}
+
+	var patches []kyverno.Patch
+	if err := yaml.Unmarshal([]byte(patchesJSON6902), &patches); err != nil {
+		return nil
+	}
+
+	return patches
+}
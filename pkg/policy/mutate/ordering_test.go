@@ -0,0 +1,70 @@
+package mutate
+
+import (
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_ValidatePatchOrdering(t *testing.T) {
+	testcases := []struct {
+		description   string
+		patches       []kyverno.Patch
+		expectedIndex int
+	}{
+		{
+			description: "add parent then child",
+			patches: []kyverno.Patch{
+				{Path: "/spec/x", Operation: "add", Value: map[string]interface{}{}},
+				{Path: "/spec/x/y", Operation: "add", Value: "value"},
+			},
+			expectedIndex: -1,
+		},
+		{
+			description: "child before parent",
+			patches: []kyverno.Patch{
+				{Path: "/spec/x/y", Operation: "add", Value: "value"},
+				{Path: "/spec/x", Operation: "add", Value: map[string]interface{}{}},
+			},
+			expectedIndex: 0,
+		},
+		{
+			description: "child after parent is removed",
+			patches: []kyverno.Patch{
+				{Path: "/spec/x", Operation: "remove"},
+				{Path: "/spec/x/y", Operation: "replace", Value: "value"},
+			},
+			expectedIndex: 1,
+		},
+		{
+			description: "sibling paths",
+			patches: []kyverno.Patch{
+				{Path: "/spec/xy", Operation: "add", Value: "value"},
+				{Path: "/spec/x", Operation: "add", Value: "value"},
+			},
+			expectedIndex: -1,
+		},
+	}
+
+	for _, testcase := range testcases {
+		index, err := ValidatePatchOrdering(testcase.patches)
+		assert.Equal(t, index, testcase.expectedIndex, testcase.description)
+		assert.Equal(t, err != nil, testcase.expectedIndex >= 0, testcase.description)
+	}
+}
+
+func Test_Validate_Mutate_PatchesJSON6902Ordering(t *testing.T) {
+	mutate := kyverno.Mutation{PatchesJSON6902: `
+- op: add
+  path: /metadata/annotations/team
+  value: a
+- op: add
+  path: /metadata/annotations
+  value: {}`}
+
+	checker := NewMutateFactory(mutate)
+	path, err := checker.Validate()
+	assert.Error(t, err, "path /metadata/annotations/team is targeted before its parent /metadata/annotations is added by patch 1")
+	assert.Equal(t, path, "patchesJson6902[0]")
+}
@@ -0,0 +1,62 @@
+package mutate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// keyedMaps are the maps whose keys commonly contain '/', such as annotation keys
+// example.com/foo, and whose values are strings
+var keyedMaps = []string{"annotations", "labels"}
+
+// validatePatchPath checks the JSON pointer path of a patch is correctly escaped.
+// A '~' must be followed by '0' or '1', and a label or annotation key containing '/'
+// or '~' must be escaped as a single token, otherwise the patch silently targets a
+// nested path instead of the key.
+func validatePatchPath(path string) error {
+	tokens := strings.Split(path, "/")
+	for _, token := range tokens {
+		if err := validatePointerToken(token); err != nil {
+			return fmt.Errorf("invalid path %s: %v", path, err)
+		}
+	}
+
+	for i := 1; i < len(tokens)-2; i++ {
+		if tokens[i] != "metadata" || !isKeyedMap(tokens[i+1]) {
+			continue
+		}
+
+		if len(tokens) > i+3 {
+			escaped := strings.Join(append(tokens[:i+2:i+2], strings.Join(tokens[i+2:], "~1")), "/")
+			return fmt.Errorf("path %s targets a nested field of %s, %s keys containing '/' must be escaped as %s", path, tokens[i+1], tokens[i+1], escaped)
+		}
+	}
+
+	return nil
+}
+
+// validatePointerToken checks every '~' of the token is an escape sequence
+func validatePointerToken(token string) error {
+	for i := 0; i < len(token); i++ {
+		if token[i] != '~' {
+			continue
+		}
+
+		if i+1 == len(token) || (token[i+1] != '0' && token[i+1] != '1') {
+			return fmt.Errorf("'~' in %s must be escaped as '~0'", token)
+		}
+		i++
+	}
+
+	return nil
+}
+
+func isKeyedMap(token string) bool {
+	for _, name := range keyedMaps {
+		if token == name {
+			return true
+		}
+	}
+
+	return false
+}
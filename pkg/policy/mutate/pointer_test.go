@@ -0,0 +1,52 @@
+package mutate
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_validatePatchPath(t *testing.T) {
+	testcases := []struct {
+		description string
+		path        string
+		err         string
+	}{
+		{
+			description: "escaped annotation key",
+			path:        "/metadata/annotations/example.com~1foo",
+		},
+		{
+			description: "unescaped slash in annotation key",
+			path:        "/metadata/annotations/example.com/foo",
+			err:         "path /metadata/annotations/example.com/foo targets a nested field of annotations, annotations keys containing '/' must be escaped as /metadata/annotations/example.com~1foo",
+		},
+		{
+			description: "unescaped slash in template label key",
+			path:        "/spec/template/metadata/labels/app.kubernetes.io/name",
+			err:         "path /spec/template/metadata/labels/app.kubernetes.io/name targets a nested field of labels, labels keys containing '/' must be escaped as /spec/template/metadata/labels/app.kubernetes.io~1name",
+		},
+		{
+			description: "whole labels map",
+			path:        "/metadata/labels",
+		},
+		{
+			description: "invalid escape",
+			path:        "/metadata/annotations/a~b",
+			err:         "invalid path /metadata/annotations/a~b: '~' in a~b must be escaped as '~0'",
+		},
+		{
+			description: "escaped tilde",
+			path:        "/spec/a~0b/c",
+		},
+	}
+
+	for _, testcase := range testcases {
+		err := validatePatchPath(testcase.path)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
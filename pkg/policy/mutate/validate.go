@@ -9,16 +9,38 @@ import (
 	"github.com/kyverno/kyverno/pkg/policy/common"
 )
 
+// DefaultMaxPatchOps is the default limit of JSON patch operations in a single mutation
+const DefaultMaxPatchOps = 1000
+
 // Mutate provides implementation to validate 'mutate' rule
 type Mutate struct {
 	// rule to hold 'mutate' rule specifications
 	rule kyverno.Mutation
+	// maxPatchOps is the limit of JSON patch operations
+	maxPatchOps int
+	// maxDepth is the limit of nested maps and arrays in overlays
+	maxDepth int
 }
 
 //NewMutateFactory returns a new instance of Mutate validation checker
 func NewMutateFactory(rule kyverno.Mutation) *Mutate {
+	return NewMutateFactoryWithLimit(rule, DefaultMaxPatchOps)
+}
+
+//NewMutateFactoryWithLimit returns a new instance of Mutate validation checker
+//that allows at most maxPatchOps JSON patch operations
+func NewMutateFactoryWithLimit(rule kyverno.Mutation, maxPatchOps int) *Mutate {
+	return NewMutateFactoryWithLimits(rule, maxPatchOps, common.DefaultMaxDepth)
+}
+
+//NewMutateFactoryWithLimits returns a new instance of Mutate validation checker
+//that allows at most maxPatchOps JSON patch operations and overlays nested at most
+//maxDepth levels
+func NewMutateFactoryWithLimits(rule kyverno.Mutation, maxPatchOps, maxDepth int) *Mutate {
 	m := Mutate{
-		rule: rule,
+		rule:        rule,
+		maxPatchOps: maxPatchOps,
+		maxDepth:    maxDepth,
 	}
 	return &m
 }
@@ -26,6 +48,10 @@ func NewMutateFactory(rule kyverno.Mutation) *Mutate {
 //Validate validates the 'mutate' rule
 func (m *Mutate) Validate() (string, error) {
 	rule := m.rule
+	if path, err := m.validatePatchCount(); err != nil {
+		return path, err
+	}
+
 	// JSON Patches
 	if len(rule.Patches) != 0 {
 		for i, patch := range rule.Patches {
@@ -34,21 +60,77 @@ func (m *Mutate) Validate() (string, error) {
 			}
 		}
 	}
+	if i, err := ValidatePatchOrdering(rule.Patches); err != nil {
+		return fmt.Sprintf("patch[%d]", i), err
+	}
+
+	for i, patch := range decodePatchesJSON6902(rule.PatchesJSON6902) {
+		if err := validatePatchPath(patch.Path); err != nil {
+			return fmt.Sprintf("patchesJson6902[%d]", i), err
+		}
+	}
+
+	if i, err := ValidatePatchOrdering(decodePatchesJSON6902(rule.PatchesJSON6902)); err != nil {
+		return fmt.Sprintf("patchesJson6902[%d]", i), err
+	}
+
+	if err := rule.ValidateOverlayAnchors(); err != nil {
+		return "", err
+	}
+
 	// Overlay
 	if rule.Overlay != nil {
-		path, err := common.ValidatePattern(rule.Overlay, "/", []commonAnchors.IsAnchor{commonAnchors.IsConditionAnchor, commonAnchors.IsAddingAnchor})
+		path, err := common.ValidatePatternWithDepth(rule.Overlay, "/", []commonAnchors.IsAnchor{commonAnchors.IsConditionAnchor, commonAnchors.IsAddingAnchor}, m.maxDepth)
 		if err != nil {
 			return path, err
 		}
+
+		if path, err := validateManagedFields(rule.Overlay); err != nil {
+			return "overlay." + path, err
+		}
+
+		if path, err := validateMergeKeys(rule.Overlay, "", ""); err != nil {
+			return "overlay." + path, err
+		}
+	}
+
+	if rule.PatchStrategicMerge != nil {
+		if path, err := validateManagedFields(rule.PatchStrategicMerge); err != nil {
+			return "patchStrategicMerge." + path, err
+		}
+
+		if path, err := validateMergeKeys(rule.PatchStrategicMerge, "", ""); err != nil {
+			return "patchStrategicMerge." + path, err
+		}
 	}
 	return "", nil
 }
 
+// validatePatchCount checks the number of JSON patch operations against the limit
+func (m *Mutate) validatePatchCount() (string, error) {
+	if len(m.rule.Patches) > m.maxPatchOps {
+		return "patches", fmt.Errorf("mutation has %d JSON patch operations, the limit is %d", len(m.rule.Patches), m.maxPatchOps)
+	}
+
+	count := len(decodePatchesJSON6902(m.rule.PatchesJSON6902))
+	if count > m.maxPatchOps {
+		return "patchesJson6902", fmt.Errorf("mutation has %d JSON patch operations, the limit is %d", count, m.maxPatchOps)
+	}
+
+	return "", nil
+}
+
 // Validate if all mandatory PolicyPatch fields are set
 func validatePatch(pp kyverno.Patch) error {
 	if pp.Path == "" {
 		return errors.New("JSONPatch field 'path' is mandatory")
 	}
+	if err := validatePatchPath(pp.Path); err != nil {
+		return err
+	}
+	if pp.AllowMissing && pp.Operation != "remove" {
+		return fmt.Errorf("JSONPatch field 'allowMissing' is only supported for operation 'remove', not '%s'", pp.Operation)
+	}
 	if pp.Operation == "add" || pp.Operation == "replace" {
 		if pp.Value == nil {
 			return fmt.Errorf("JSONPatch field 'value' is mandatory for operation '%s'", pp.Operation)
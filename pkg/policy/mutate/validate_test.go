@@ -2,6 +2,7 @@ package mutate
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
@@ -149,3 +150,176 @@ func Test_Validate_Mutate_Unsupported(t *testing.T) {
 		assert.Assert(t, err != nil)
 	}
 }
+
+func Test_Validate_Mutate_MaxPatchOps(t *testing.T) {
+	patches := func(count int) []kyverno.Patch {
+		var result []kyverno.Patch
+		for i := 0; i < count; i++ {
+			result = append(result, kyverno.Patch{Path: "/metadata/labels/app", Operation: "add", Value: "nginx"})
+		}
+		return result
+	}
+
+	testcases := []struct {
+		description string
+		mutate      kyverno.Mutation
+		limit       int
+		expectError bool
+	}{
+		{
+			description: "at limit",
+			mutate:      kyverno.Mutation{Patches: patches(3)},
+			limit:       3,
+			expectError: false,
+		},
+		{
+			description: "over limit",
+			mutate:      kyverno.Mutation{Patches: patches(4)},
+			limit:       3,
+			expectError: true,
+		},
+		{
+			description: "patchesJson6902 over limit",
+			mutate: kyverno.Mutation{PatchesJSON6902: `
+- op: add
+  path: /metadata/labels/a
+  value: a
+- op: add
+  path: /metadata/labels/b
+  value: b`},
+			limit:       1,
+			expectError: true,
+		},
+		{
+			description: "patchesJson6902 as JSON at limit",
+			mutate:      kyverno.Mutation{PatchesJSON6902: `[{"op": "remove", "path": "/metadata/labels/a"}]`},
+			limit:       1,
+			expectError: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		checker := NewMutateFactoryWithLimit(testcase.mutate, testcase.limit)
+		_, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+
+	checker := NewMutateFactory(kyverno.Mutation{Patches: patches(DefaultMaxPatchOps)})
+	_, err := checker.Validate()
+	assert.NilError(t, err)
+
+	checker = NewMutateFactory(kyverno.Mutation{Patches: patches(DefaultMaxPatchOps + 1)})
+	_, err = checker.Validate()
+	assert.Error(t, err, fmt.Sprintf("mutation has %d JSON patch operations, the limit is %d", DefaultMaxPatchOps+1, DefaultMaxPatchOps))
+}
+
+func Test_Validate_Mutate_ManagedFields(t *testing.T) {
+	testcases := []struct {
+		description  string
+		mutate       []byte
+		expectedPath string
+	}{
+		{
+			description:  "overlay writing creationTimestamp",
+			mutate:       []byte(`{"overlay":{"metadata":{"creationTimestamp":"2021-01-01T00:00:00Z","labels":{"app":"nginx"}}}}`),
+			expectedPath: "overlay.metadata.creationTimestamp",
+		},
+		{
+			description:  "overlay adding status",
+			mutate:       []byte(`{"overlay":{"+(status)":{"phase":"Running"}}}`),
+			expectedPath: "overlay.+(status)",
+		},
+		{
+			description:  "patchStrategicMerge writing uid",
+			mutate:       []byte(`{"patchStrategicMerge":{"metadata":{"uid":"1234"}}}`),
+			expectedPath: "patchStrategicMerge.metadata.uid",
+		},
+		{
+			description:  "overlay writing a user field",
+			mutate:       []byte(`{"overlay":{"metadata":{"labels":{"app":"nginx"}},"spec":{"replicas":2}}}`),
+			expectedPath: "",
+		},
+		{
+			description:  "overlay conditioned on status",
+			mutate:       []byte(`{"overlay":{"(status)":{"phase":"Running"},"metadata":{"labels":{"app":"nginx"}}}}`),
+			expectedPath: "",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var mutate kyverno.Mutation
+		err := json.Unmarshal(testcase.mutate, &mutate)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := NewMutateFactory(mutate).Validate()
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+	}
+}
+
+func Test_Validate_Mutate_AllowMissing(t *testing.T) {
+	testcases := []struct {
+		description  string
+		mutate       []byte
+		expectedPath string
+	}{
+		{
+			description:  "allowMissing on remove",
+			mutate:       []byte(`{"patches":[{"op":"remove","path":"/metadata/annotations/deprecated","allowMissing":true}]}`),
+			expectedPath: "",
+		},
+		{
+			description:  "allowMissing on add",
+			mutate:       []byte(`{"patches":[{"op":"add","path":"/metadata/labels/app","value":"nginx","allowMissing":true}]}`),
+			expectedPath: "patch[0]",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var mutate kyverno.Mutation
+		err := json.Unmarshal(testcase.mutate, &mutate)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := NewMutateFactory(mutate).Validate()
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+	}
+}
+
+func Test_Validate_Mutate_LiteralOverlay(t *testing.T) {
+	testcases := []struct {
+		description string
+		mutate      []byte
+	}{
+		{
+			description: "comparison operator literals",
+			mutate:      []byte(`{"overlay":{"metadata":{"annotations":{"owner":"<none>","threshold":"> 5Gx"}}}}`),
+		},
+		{
+			description: "wildcard literals",
+			mutate:      []byte(`{"overlay":{"metadata":{"annotations":{"schedule":"*/5 * * * * > /dev/null"}}}}`),
+		},
+		{
+			description: "regex operator literals",
+			mutate:      []byte(`{"overlay":{"metadata":{"annotations":{"filter":"regex:("}}}}`),
+		},
+		{
+			description: "operator family literals",
+			mutate:      []byte(`{"overlay":{"metadata":{"annotations":{"rule":">5 | <3"}}}}`),
+		},
+		{
+			description: "keys with parentheses",
+			mutate:      []byte(`{"overlay":{"metadata":{"annotations":{"fn(x)":"x + 1"}}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var mutate kyverno.Mutation
+		err := json.Unmarshal(testcase.mutate, &mutate)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := NewMutateFactory(mutate).Validate()
+		assert.NilError(t, err, testcase.description)
+		assert.Equal(t, path, "", testcase.description)
+	}
+}
@@ -0,0 +1,68 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+)
+
+var regexAnchoredKey = regexp.MustCompile(`^.?\(.+\)$`)
+
+// validateNullPatternValues returns an error if a key of the validate patterns is set to
+// null without an anchor. The pattern then requires the field to be null, while authors
+// usually mean the field must not be set, which is written with the negation anchor X(key).
+func validateNullPatternValues(rule kyverno.Rule) (string, error) {
+	validation := rule.Validation
+	if path, err := findNullValue(validation.Pattern, "validate.pattern"); err != nil {
+		return path, err
+	}
+
+	if validation.AnyPattern != nil {
+		if entries, err := validation.DeserializeAnyPatternEntries(); err == nil {
+			for i, entry := range entries {
+				if path, err := findNullValue(entry.Pattern, fmt.Sprintf("validate.anyPattern[%d]", i)); err != nil {
+					return path, err
+				}
+			}
+		}
+	}
+
+	for i, fe := range validation.ForEach {
+		if path, err := findNullValue(fe.Pattern, fmt.Sprintf("validate.foreach[%d].pattern", i)); err != nil {
+			return path, err
+		}
+	}
+
+	return "", nil
+}
+
+func findNullValue(element interface{}, path string) (string, error) {
+	switch typed := element.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if typed[key] == nil && !regexAnchoredKey.MatchString(key) {
+				return path + "." + key, fmt.Errorf("null requires the field to be null, use the negation anchor X(%s) if the field must not be set", key)
+			}
+
+			if path, err := findNullValue(typed[key], path+"."+key); err != nil {
+				return path, err
+			}
+		}
+	case []interface{}:
+		for i, value := range typed {
+			if path, err := findNullValue(value, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return path, err
+			}
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,46 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateNullPatternValues(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		path        string
+	}{
+		{
+			description: "null value",
+			rule:        []byte(`{"name":"r","validate":{"pattern":{"spec":{"containers":[{"securityContext":{"privileged":null}}]}}}}`),
+			path:        "validate.pattern.spec.containers[0].securityContext.privileged",
+		},
+		{
+			description: "null value in anyPattern",
+			rule:        []byte(`{"name":"r","validate":{"anyPattern":[{"spec":{"hostNetwork":false}},{"spec":{"hostNetwork":null}}]}}`),
+			path:        "validate.anyPattern[1].spec.hostNetwork",
+		},
+		{
+			description: "negation anchor",
+			rule:        []byte(`{"name":"r","validate":{"pattern":{"spec":{"X(hostNetwork)":null}}}}`),
+		},
+		{
+			description: "no null values",
+			rule:        []byte(`{"name":"r","validate":{"pattern":{"spec":{"hostNetwork":false}}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateNullPatternValues(rule)
+		assert.Equal(t, err != nil, testcase.path != "", testcase.description)
+		assert.Equal(t, path, testcase.path, testcase.description)
+	}
+}
@@ -0,0 +1,138 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+)
+
+// ValidatePolicies runs the checks that span multiple policies, such as clone cycles
+// between generate rules. Each policy should also be checked with Validate.
+func ValidatePolicies(policies []kyverno.ClusterPolicy) error {
+	if err := validateCloneCycles(policies); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var regexVariable = regexp.MustCompile(`{{[^{}]*}}`)
+
+// cloneRule is a generate rule cloning a resource
+type cloneRule struct {
+	policy string
+	rule   string
+	kind   string
+	source [2]string
+	target [2]string
+}
+
+func (c cloneRule) String() string {
+	return c.policy + "/" + c.rule
+}
+
+// validateCloneCycles returns an error if generate rules clone resources in a cycle, where a
+// rule clones a resource generated by another rule which is, directly or not, cloned from
+// the resource generated by the first rule. Variables in namespaces and names can resolve
+// to any value, so they are treated as wildcards.
+func validateCloneCycles(policies []kyverno.ClusterPolicy) error {
+	var rules []cloneRule
+	for _, policy := range policies {
+		for _, rule := range policy.Spec.Rules {
+			for _, generation := range rule.GenerateTargets() {
+				if generation.Clone.Name == "" {
+					continue
+				}
+
+				rules = append(rules, cloneRule{
+					policy: policy.Name,
+					rule:   rule.Name,
+					kind:   generation.Kind,
+					source: [2]string{variableToWildcard(generation.Clone.Namespace), variableToWildcard(generation.Clone.Name)},
+					target: [2]string{variableToWildcard(generation.Namespace), variableToWildcard(generation.Name)},
+				})
+			}
+		}
+	}
+
+	// edges[i] lists the rules cloning the resource generated by rule i
+	edges := make([][]int, len(rules))
+	for i := range rules {
+		for j := range rules {
+			if i != j && rules[i].kind == rules[j].kind && patternsOverlap(rules[i].target, rules[j].source) {
+				edges[i] = append(edges[i], j)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make([]int, len(rules))
+	var stack []int
+	var cycle []int
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		state[i] = visiting
+		stack = append(stack, i)
+		for _, j := range edges[i] {
+			if state[j] == visiting {
+				for k := range stack {
+					if stack[k] == j {
+						cycle = append(append([]int{}, stack[k:]...), j)
+						break
+					}
+				}
+				return true
+			}
+
+			if state[j] == unvisited && visit(j) {
+				return true
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[i] = visited
+		return false
+	}
+
+	for i := range rules {
+		if state[i] == unvisited && visit(i) {
+			names := make([]string, len(cycle))
+			for k, r := range cycle {
+				names[k] = rules[r].String()
+			}
+
+			return fmt.Errorf("generate rules clone resources in a cycle: %s", strings.Join(names, " -> "))
+		}
+	}
+
+	return nil
+}
+
+// patternsOverlap returns true if the namespace and name patterns can select the same resource
+func patternsOverlap(a, b [2]string) bool {
+	for i := range a {
+		if a[i] == "" || b[i] == "" {
+			if a[i] != b[i] {
+				return false
+			}
+			continue
+		}
+
+		if _, err := intersectPattern(a[i], b[i]); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func variableToWildcard(value string) string {
+	return regexVariable.ReplaceAllString(value, "*")
+}
@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_ValidatePolicies_CloneCycles(t *testing.T) {
+	testcases := []struct {
+		description string
+		policies    []string
+		expectedErr string
+	}{
+		{
+			description: "two policy clone cycle",
+			policies: []string{
+				`{"metadata":{"name":"a"},"spec":{"rules":[{"name":"copy-to-prod","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"Secret","name":"regcred","namespace":"prod","synchronize":true,"clone":{"namespace":"default","name":"regcred"}}}]}}`,
+				`{"metadata":{"name":"b"},"spec":{"rules":[{"name":"copy-to-default","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"Secret","name":"regcred","namespace":"default","synchronize":true,"clone":{"namespace":"prod","name":"regcred"}}}]}}`,
+			},
+			expectedErr: "generate rules clone resources in a cycle: a/copy-to-prod -> b/copy-to-default -> a/copy-to-prod",
+		},
+		{
+			description: "cycle through a variable namespace",
+			policies: []string{
+				`{"metadata":{"name":"a"},"spec":{"rules":[{"name":"copy","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"Secret","name":"regcred","namespace":"{{request.object.metadata.name}}","clone":{"namespace":"default","name":"regcred"}}}]}}`,
+				`{"metadata":{"name":"b"},"spec":{"rules":[{"name":"copy-back","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"Secret","name":"regcred","namespace":"default","clone":{"namespace":"prod","name":"regcred"}}}]}}`,
+			},
+			expectedErr: "generate rules clone resources in a cycle: a/copy -> b/copy-back -> a/copy",
+		},
+		{
+			description: "acyclic chain",
+			policies: []string{
+				`{"metadata":{"name":"a"},"spec":{"rules":[{"name":"copy-to-staging","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"Secret","name":"regcred","namespace":"staging","clone":{"namespace":"default","name":"regcred"}}}]}}`,
+				`{"metadata":{"name":"b"},"spec":{"rules":[{"name":"copy-to-prod","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"Secret","name":"regcred","namespace":"prod","clone":{"namespace":"staging","name":"regcred"}}}]}}`,
+			},
+		},
+		{
+			description: "same names for different kinds",
+			policies: []string{
+				`{"metadata":{"name":"a"},"spec":{"rules":[{"name":"secret","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"Secret","name":"cfg","namespace":"prod","clone":{"namespace":"default","name":"cfg"}}}]}}`,
+				`{"metadata":{"name":"b"},"spec":{"rules":[{"name":"configmap","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"cfg","namespace":"default","clone":{"namespace":"prod","name":"cfg"}}}]}}`,
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policies []kyverno.ClusterPolicy
+		for _, raw := range testcase.policies {
+			var policy kyverno.ClusterPolicy
+			err := json.Unmarshal([]byte(raw), &policy)
+			assert.NilError(t, err, testcase.description)
+			policies = append(policies, policy)
+		}
+
+		err := ValidatePolicies(policies)
+		if testcase.expectedErr == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.expectedErr, testcase.description)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
+	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// booleanFields are the names of well-known boolean fields of Kubernetes resources
+var booleanFields = map[string]bool{
+	"allowPrivilegeEscalation":     true,
+	"automountServiceAccountToken": true,
+	"enableServiceLinks":           true,
+	"hostIPC":                      true,
+	"hostNetwork":                  true,
+	"hostPID":                      true,
+	"immutable":                    true,
+	"optional":                     true,
+	"paused":                       true,
+	"privileged":                   true,
+	"readOnly":                     true,
+	"readOnlyRootFilesystem":       true,
+	"runAsNonRoot":                 true,
+	"setHostnameAsFQDN":            true,
+	"shareProcessNamespace":        true,
+	"stdin":                        true,
+	"stdinOnce":                    true,
+	"suspend":                      true,
+	"tty":                          true,
+	"unschedulable":                true,
+}
+
+// validateQuotedBooleans returns an error if a pattern or overlay of the rule sets a
+// well-known boolean field to the string "true" or "false", which never matches the
+// boolean value of the resource. Some fields share their name with string enums, so
+// the result is reported as a warning.
+func validateQuotedBooleans(rule kyverno.Rule) (string, error) {
+	var found []string
+	findQuotedBooleans(rule.Validation.Pattern, "validate.pattern", &found)
+	if rule.Validation.AnyPattern != nil {
+		if anyPattern, err := rule.Validation.DeserializeAnyPattern(); err == nil {
+			for i, pattern := range anyPattern {
+				findQuotedBooleans(pattern, fmt.Sprintf("validate.anyPattern[%d]", i), &found)
+			}
+		}
+	}
+
+	for i, forEach := range rule.Validation.ForEach {
+		findQuotedBooleans(forEach.Pattern, fmt.Sprintf("validate.foreach[%d].pattern", i), &found)
+	}
+
+	findQuotedBooleans(rule.Mutation.Overlay, "mutate.overlay", &found)
+	findQuotedBooleans(rule.Mutation.PatchStrategicMerge, "mutate.patchStrategicMerge", &found)
+
+	if len(found) > 0 {
+		sort.Strings(found)
+		return found[0], fmt.Errorf("boolean fields are set to quoted strings, which never match boolean values: %s", strings.Join(found, ", "))
+	}
+
+	return "", nil
+}
+
+func findQuotedBooleans(element interface{}, path string, found *[]string) {
+	switch typed := element.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			field, _ := commonAnchors.RemoveAnchor(key)
+			if booleanFields[field] && isQuotedBoolean(value) {
+				*found = append(*found, path+"."+key)
+				continue
+			}
+
+			findQuotedBooleans(value, path+"."+key, found)
+		}
+	case []interface{}:
+		for i, value := range typed {
+			findQuotedBooleans(value, fmt.Sprintf("%s[%d]", path, i), found)
+		}
+	}
+}
+
+func isQuotedBoolean(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && (s == "true" || s == "false")
+}
+
+// warnQuotedBooleans logs a warning listing, with their line numbers, the well-known boolean
+// fields set to quoted "true" or "false" in the policy source
+func warnQuotedBooleans(source []byte) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(source, &document); err != nil {
+		return
+	}
+
+	var found []string
+	findQuotedBooleanNodes(&document, "", &found)
+	if len(found) > 0 {
+		log.Log.V(1).Info(fmt.Sprintf("warning: boolean fields are set to quoted strings, which never match boolean values: %s", strings.Join(found, "; ")))
+	}
+}
+
+// findQuotedBooleanNodes reports the quoted "true" and "false" values of well-known boolean
+// fields in the policy source, with their line numbers
+func findQuotedBooleanNodes(node *yaml.Node, path string, found *[]string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, content := range node.Content {
+			findQuotedBooleanNodes(content, path, found)
+		}
+	case yaml.SequenceNode:
+		for i, content := range node.Content {
+			findQuotedBooleanNodes(content, fmt.Sprintf("%s[%d]", path, i), found)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := key.Value
+			if path != "" {
+				keyPath = path + "." + key.Value
+			}
+
+			field, _ := commonAnchors.RemoveAnchor(key.Value)
+			if booleanFields[field] && value.Kind == yaml.ScalarNode && value.Tag == "!!str" && isQuotedBoolean(value.Value) {
+				*found = append(*found, fmt.Sprintf("path: %s at line %d has value %q", keyPath, value.Line, value.Value))
+				continue
+			}
+
+			findQuotedBooleanNodes(value, keyPath, found)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gopkg.in/yaml.v3"
+	"gotest.tools/assert"
+)
+
+func Test_validateQuotedBooleans(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		path        string
+	}{
+		{
+			description: "boolean value",
+			rule:        []byte(`{"name":"r","validate":{"pattern":{"spec":{"containers":[{"securityContext":{"readOnlyRootFilesystem":true}}]}}}}`),
+		},
+		{
+			description: "quoted boolean value",
+			rule:        []byte(`{"name":"r","validate":{"pattern":{"spec":{"containers":[{"securityContext":{"readOnlyRootFilesystem":"true"}}]}}}}`),
+			path:        "validate.pattern.spec.containers[0].securityContext.readOnlyRootFilesystem",
+		},
+		{
+			description: "quoted boolean value in anchor",
+			rule:        []byte(`{"name":"r","validate":{"anyPattern":[{"spec":{"=(hostNetwork)":"false"}}]}}`),
+			path:        "validate.anyPattern[0].spec.=(hostNetwork)",
+		},
+		{
+			description: "quoted boolean value in overlay",
+			rule:        []byte(`{"name":"r","mutate":{"patchStrategicMerge":{"spec":{"automountServiceAccountToken":"false"}}}}`),
+			path:        "mutate.patchStrategicMerge.spec.automountServiceAccountToken",
+		},
+		{
+			description: "string field",
+			rule:        []byte(`{"name":"r","validate":{"pattern":{"metadata":{"labels":{"enabled":"true"}}}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateQuotedBooleans(rule)
+		assert.Equal(t, path, testcase.path, testcase.description)
+		assert.Equal(t, err != nil, testcase.path != "", testcase.description)
+	}
+}
+
+func Test_findQuotedBooleanNodes(t *testing.T) {
+	source := []byte(`
+spec:
+  rules:
+  - name: r
+    validate:
+      pattern:
+        spec:
+          hostPID: false
+          containers:
+          - securityContext:
+              privileged: "false"
+              runAsNonRoot: 'true'
+`)
+
+	var document yaml.Node
+	err := yaml.Unmarshal(source, &document)
+	assert.NilError(t, err)
+
+	var found []string
+	findQuotedBooleanNodes(&document, "", &found)
+	assert.DeepEqual(t, found, []string{
+		`path: spec.rules[0].validate.pattern.spec.containers[0].securityContext.privileged at line 11 has value "false"`,
+		`path: spec.rules[0].validate.pattern.spec.containers[0].securityContext.runAsNonRoot at line 12 has value "true"`,
+	})
+}
@@ -0,0 +1,150 @@
+package policy
+
+import (
+	"encoding/json"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// ExportSARIF converts policy validation results to a SARIF 2.1.0 log, with one
+// reporting rule per policy rule identified as <policy>/<rule>
+func ExportSARIF(results []PolicyValidationResult) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "kyverno",
+				InformationURI: "https://kyverno.io",
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	ruleIndexes := make(map[string]int)
+	for _, result := range results {
+		id := sarifRuleID(result)
+		index, ok := ruleIndexes[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndexes[id] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               id,
+				Name:             id,
+				ShortDescription: sarifMessage{Text: "Validation of " + id},
+			})
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    id,
+			RuleIndex: index,
+			Level:     sarifLevel(result.Severity),
+			Message:   sarifMessage{Text: result.Message},
+			Locations: sarifLocations(result),
+		})
+	}
+
+	return json.MarshalIndent(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}}, "", "  ")
+}
+
+func sarifRuleID(result PolicyValidationResult) string {
+	if result.Rule == "" {
+		return result.Policy
+	}
+
+	return result.Policy + "/" + result.Rule
+}
+
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func sarifLocations(result PolicyValidationResult) []sarifLocation {
+	if result.File == "" && result.Path == "" {
+		return nil
+	}
+
+	var location sarifLocation
+	if result.File != "" {
+		location.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: result.File}}
+		if result.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: result.Line}
+		}
+	}
+
+	if result.Path != "" {
+		location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: result.Path}}
+	}
+
+	return []sarifLocation{location}
+}
@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_ExportSARIF(t *testing.T) {
+	results := []PolicyValidationResult{
+		{
+			Policy:   "disallow-latest-tag",
+			Rule:     "validate-image-tag",
+			Severity: SeverityError,
+			Message:  "Malformed anchor =(image: missing closing parenthesis",
+			Path:     "spec.rules[0].validate.pattern",
+			File:     "policies/disallow-latest-tag.yaml",
+			Line:     18,
+		},
+		{
+			Policy:   "disallow-latest-tag",
+			Rule:     "validate-image-tag",
+			Severity: SeverityWarning,
+			Message:  "kind pods looks like a resource name",
+			Path:     "spec.rules[0].match.resources.kinds",
+		},
+		{
+			Policy:   "add-labels",
+			Severity: SeverityError,
+			Message:  "invalid webhook timeout 40",
+		},
+	}
+
+	raw, err := ExportSARIF(results)
+	assert.NilError(t, err)
+
+	var log map[string]interface{}
+	err = json.Unmarshal(raw, &log)
+	assert.NilError(t, err)
+
+	// required by the SARIF 2.1.0 schema
+	assert.Equal(t, log["version"], "2.1.0")
+	runs, ok := log["runs"].([]interface{})
+	assert.Assert(t, ok)
+	assert.Equal(t, len(runs), 1)
+
+	run := runs[0].(map[string]interface{})
+	driver := run["tool"].(map[string]interface{})["driver"].(map[string]interface{})
+	assert.Equal(t, driver["name"], "kyverno")
+
+	rules := driver["rules"].([]interface{})
+	assert.Equal(t, len(rules), 2)
+	assert.Equal(t, rules[0].(map[string]interface{})["id"], "disallow-latest-tag/validate-image-tag")
+	assert.Equal(t, rules[1].(map[string]interface{})["id"], "add-labels")
+
+	sarifResults := run["results"].([]interface{})
+	assert.Equal(t, len(sarifResults), 3)
+	for _, r := range sarifResults {
+		result := r.(map[string]interface{})
+		message, ok := result["message"].(map[string]interface{})
+		assert.Assert(t, ok)
+		assert.Assert(t, message["text"] != "")
+	}
+
+	first := sarifResults[0].(map[string]interface{})
+	assert.Equal(t, first["ruleId"], "disallow-latest-tag/validate-image-tag")
+	assert.Equal(t, first["level"], "error")
+	location := first["locations"].([]interface{})[0].(map[string]interface{})
+	physical := location["physicalLocation"].(map[string]interface{})
+	assert.Equal(t, physical["artifactLocation"].(map[string]interface{})["uri"], "policies/disallow-latest-tag.yaml")
+	assert.Equal(t, physical["region"].(map[string]interface{})["startLine"], float64(18))
+
+	second := sarifResults[1].(map[string]interface{})
+	assert.Equal(t, second["level"], "warning")
+	assert.Equal(t, second["ruleIndex"], float64(0))
+
+	third := sarifResults[2].(map[string]interface{})
+	assert.Equal(t, third["ruleIndex"], float64(1))
+	_, hasLocations := third["locations"]
+	assert.Assert(t, !hasLocations)
+}
+
+func Test_ExportSARIF_NoResults(t *testing.T) {
+	raw, err := ExportSARIF(nil)
+	assert.NilError(t, err)
+
+	var log map[string]interface{}
+	err = json.Unmarshal(raw, &log)
+	assert.NilError(t, err)
+
+	run := log["runs"].([]interface{})[0].(map[string]interface{})
+	assert.DeepEqual(t, run["results"], []interface{}{})
+}
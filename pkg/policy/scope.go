@@ -0,0 +1,207 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+)
+
+// ScopeLookup returns true if the kind is namespaced. known is false if the
+// scope of the kind cannot be determined, e.g. for custom resources.
+type ScopeLookup func(kind string) (namespaced bool, known bool)
+
+// clusterScopedKinds are the built-in kinds that are not namespaced
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"CSIDriver":                      true,
+	"CSINode":                        true,
+	"CertificateSigningRequest":      true,
+	"ClusterPolicy":                  true,
+	"ClusterPolicyReport":            true,
+	"ClusterReportChangeRequest":     true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"ComponentStatus":                true,
+	"CustomResourceDefinition":       true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+	"VolumeAttachment":               true,
+}
+
+// namespacedKinds are the built-in kinds that are namespaced
+var namespacedKinds = map[string]bool{
+	"ConfigMap":               true,
+	"ControllerRevision":      true,
+	"CronJob":                 true,
+	"DaemonSet":               true,
+	"Deployment":              true,
+	"Endpoints":               true,
+	"Event":                   true,
+	"HorizontalPodAutoscaler": true,
+	"Ingress":                 true,
+	"Job":                     true,
+	"LimitRange":              true,
+	"NetworkPolicy":           true,
+	"PersistentVolumeClaim":   true,
+	"Pod":                     true,
+	"PodDisruptionBudget":     true,
+	"PolicyReport":            true,
+	"ReplicaSet":              true,
+	"ReplicationController":   true,
+	"ResourceQuota":           true,
+	"Role":                    true,
+	"RoleBinding":             true,
+	"Secret":                  true,
+	"Service":                 true,
+	"ServiceAccount":          true,
+	"StatefulSet":             true,
+}
+
+// DefaultScopeLookup resolves the scope of built-in kinds
+func DefaultScopeLookup(kind string) (namespaced bool, known bool) {
+	if clusterScopedKinds[kind] {
+		return false, true
+	}
+
+	if namespacedKinds[kind] {
+		return true, true
+	}
+
+	return false, false
+}
+
+var regexRequestNamespace = regexp.MustCompile(`\{\{\s*request\.namespace\s*\}\}`)
+
+// validateGenerateNamespaceScope returns an error if the generate rule references
+// request.namespace but the rule only matches cluster-scoped kinds, in which case
+// the variable is always empty
+func validateGenerateNamespaceScope(rule kyverno.Rule, lookup ScopeLookup) error {
+	generations, err := json.Marshal(rule.GenerateTargets())
+	if err != nil || !regexRequestNamespace.Match(generations) {
+		return nil
+	}
+
+	kinds := matchedKinds(rule.MatchResources)
+	if len(kinds) == 0 {
+		return nil
+	}
+
+	for _, kind := range kinds {
+		if namespaced, known := lookup(kind); namespaced || !known {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("generate references request.namespace but match only selects cluster-scoped kinds %v, which have no namespace", kinds)
+}
+
+// validateClusterScopedCloneSync returns an error if a synchronized generate rule clones a
+// cluster-scoped kind into a namespace. The clone source and the generated resource share
+// the kind, so the resource cannot be fanned out into namespaces.
+func validateClusterScopedCloneSync(rule kyverno.Rule, lookup ScopeLookup) (string, error) {
+	for i, generation := range rule.GenerateTargets() {
+		if !generation.Synchronize || generation.Clone.Name == "" {
+			continue
+		}
+
+		if namespaced, known := lookup(generation.Kind); namespaced || !known {
+			continue
+		}
+
+		if generation.Namespace != "" {
+			return generateTargetPath(rule, i) + ".namespace", fmt.Errorf("%s is cluster-scoped and cannot be cloned into the namespace %s", generation.Kind, generation.Namespace)
+		}
+
+		if generation.Clone.Namespace != "" {
+			return generateTargetPath(rule, i) + ".clone.namespace", fmt.Errorf("%s is cluster-scoped and has no namespace", generation.Kind)
+		}
+	}
+
+	return "", nil
+}
+
+// warnClusterScopedCloneSync returns the path of the first synchronized generate block
+// cloning a cluster-scoped kind, whose single generated resource is shared by all the
+// triggering resources and rewritten whenever the source changes
+func warnClusterScopedCloneSync(rule kyverno.Rule, lookup ScopeLookup) (string, error) {
+	for i, generation := range rule.GenerateTargets() {
+		if !generation.Synchronize || generation.Clone.Name == "" {
+			continue
+		}
+
+		if namespaced, known := lookup(generation.Kind); namespaced || !known {
+			continue
+		}
+
+		return generateTargetPath(rule, i) + ".clone", fmt.Errorf("synchronizing the cluster-scoped %s %s generates a single resource shared by all triggers, changes to %s are applied to it and it is deleted with the policy", generation.Kind, generation.Name, generation.Clone.Name)
+	}
+
+	return "", nil
+}
+
+// validateWebhookScope checks the webhook scope is one of the scopes of admission webhook rules
+func validateWebhookScope(scope string) error {
+	switch scope {
+	case "", "*", "Cluster", "Namespaced":
+		return nil
+	}
+
+	return fmt.Errorf("invalid webhook scope %s, expected one of Cluster, Namespaced, *", scope)
+}
+
+// warnWebhookScope returns an error if the rule matches kinds of a scope excluded by the
+// webhook scope, whose resources are never sent to the policy
+func warnWebhookScope(rule kyverno.Rule, scope string, lookup ScopeLookup) error {
+	if scope != "Cluster" && scope != "Namespaced" {
+		return nil
+	}
+
+	var missed []string
+	for _, kind := range matchedKinds(rule.MatchResources) {
+		namespaced, known := lookup(kind)
+		if known && namespaced != (scope == "Namespaced") {
+			missed = append(missed, kind)
+		}
+	}
+
+	if len(missed) > 0 {
+		return fmt.Errorf("kinds %v are not in the %s webhook scope and are never matched", missed, scope)
+	}
+
+	return nil
+}
+
+// ValidateScopeCoherence checks the kinds matched by the rules can be admitted in the scope of
+// the policy. A ClusterPolicy matches resources of any scope, while a namespaced Policy only
+// receives resources from its namespace and never sees cluster-scoped kinds. It returns the
+// path of the first rule matching cluster-scoped kinds from a namespaced policy.
+func ValidateScopeCoherence(policy kyverno.ClusterPolicy, lookup ScopeLookup) (string, error) {
+	if policy.Kind != "Policy" && policy.Namespace == "" {
+		return "", nil
+	}
+
+	for i, rule := range policy.Spec.Rules {
+		var clusterScoped []string
+		for _, kind := range matchedKinds(rule.MatchResources) {
+			if namespaced, known := lookup(kind); known && !namespaced {
+				clusterScoped = append(clusterScoped, kind)
+			}
+		}
+
+		if len(clusterScoped) > 0 {
+			return fmt.Sprintf("spec.rules[%d].match", i), fmt.Errorf("rule %s of a namespaced policy matches cluster-scoped kinds %v, which are never admitted in a namespace", rule.Name, clusterScoped)
+		}
+	}
+
+	return "", nil
+}
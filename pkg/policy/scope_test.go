@@ -0,0 +1,207 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateGenerateNamespaceScope(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		lookup      ScopeLookup
+		expectError bool
+	}{
+		{
+			description: "namespaced kind",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["ConfigMap"]}},"generate":{"kind":"Secret","name":"s","namespace":"{{request.namespace}}","data":{}}}`),
+			expectError: false,
+		},
+		{
+			description: "cluster-scoped kind",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"NetworkPolicy","name":"deny","namespace":"{{request.namespace}}","data":{}}}`),
+			expectError: true,
+		},
+		{
+			description: "cluster-scoped kind referencing the object name",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"NetworkPolicy","name":"deny","namespace":"{{request.object.metadata.name}}","data":{}}}`),
+			expectError: false,
+		},
+		{
+			description: "request.namespace in data",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Node","ClusterRole"]}},"generate":{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"ns":"{{ request.namespace }}"}}}}`),
+			expectError: true,
+		},
+		{
+			description: "request.namespace in generations",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generations":[{"kind":"ConfigMap","name":"cm","namespace":"default","data":{}},{"kind":"NetworkPolicy","name":"deny","namespace":"{{request.namespace}}","data":{}}]}`),
+			expectError: true,
+		},
+		{
+			description: "unknown kind",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Widget"]}},"generate":{"kind":"Secret","name":"s","namespace":"{{request.namespace}}","data":{}}}`),
+			expectError: false,
+		},
+		{
+			description: "custom lookup",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Widget"]}},"generate":{"kind":"Secret","name":"s","namespace":"{{request.namespace}}","data":{}}}`),
+			lookup:      func(kind string) (bool, bool) { return false, true },
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		lookup := testcase.lookup
+		if lookup == nil {
+			lookup = DefaultScopeLookup
+		}
+
+		err = validateGenerateNamespaceScope(rule, lookup)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_validateClusterScopedCloneSync(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		path        string
+		expectError bool
+		expectWarn  bool
+	}{
+		{
+			description: "cluster-scoped source cloned into a namespace",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ClusterRole","name":"view","namespace":"{{request.object.metadata.name}}","synchronize":true,"clone":{"name":"base-view"}}}`),
+			path:        "generate.namespace",
+			expectError: true,
+			expectWarn:  true,
+		},
+		{
+			description: "cluster-scoped source with a namespace",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ClusterRole","name":"view","synchronize":true,"clone":{"namespace":"default","name":"base-view"}}}`),
+			path:        "generate.clone.namespace",
+			expectError: true,
+			expectWarn:  true,
+		},
+		{
+			description: "cluster-scoped clone",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ClusterRole","name":"view","synchronize":true,"clone":{"name":"base-view"}}}`),
+			expectWarn:  true,
+		},
+		{
+			description: "cluster-scoped source cloned into a namespace in generations",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generations":[{"kind":"Secret","name":"regcred","namespace":"{{request.object.metadata.name}}","synchronize":true,"clone":{"namespace":"default","name":"regcred"}},{"kind":"ClusterRole","name":"view","namespace":"{{request.object.metadata.name}}","synchronize":true,"clone":{"name":"base-view"}}]}`),
+			path:        "generations[1].namespace",
+			expectError: true,
+			expectWarn:  true,
+		},
+		{
+			description: "same-scope clone",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"Secret","name":"regcred","namespace":"{{request.object.metadata.name}}","synchronize":true,"clone":{"namespace":"default","name":"regcred"}}}`),
+		},
+		{
+			description: "cluster-scoped clone without synchronize",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ClusterRole","name":"view","namespace":"default","clone":{"name":"base-view"}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateClusterScopedCloneSync(rule, DefaultScopeLookup)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+		assert.Equal(t, path, testcase.path, testcase.description)
+
+		_, err = warnClusterScopedCloneSync(rule, DefaultScopeLookup)
+		assert.Equal(t, err != nil, testcase.expectWarn, testcase.description)
+	}
+}
+
+func Test_warnWebhookScope(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		scope       string
+		expectWarn  bool
+	}{
+		{
+			description: "cluster-scoped match with namespaced scope",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod","Namespace"]}},"validate":{"deny":{}}}`),
+			scope:       "Namespaced",
+			expectWarn:  true,
+		},
+		{
+			description: "namespaced match with cluster scope",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["ConfigMap"]}},"validate":{"deny":{}}}`),
+			scope:       "Cluster",
+			expectWarn:  true,
+		},
+		{
+			description: "namespaced match with namespaced scope",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod","Widget"]}},"validate":{"deny":{}}}`),
+			scope:       "Namespaced",
+		},
+		{
+			description: "any scope",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod","Namespace"]}},"validate":{"deny":{}}}`),
+			scope:       "*",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		err = warnWebhookScope(rule, testcase.scope, DefaultScopeLookup)
+		assert.Equal(t, err != nil, testcase.expectWarn, testcase.description)
+	}
+
+	assert.NilError(t, validateWebhookScope("Namespaced"))
+	assert.Error(t, validateWebhookScope("Namespace"), "invalid webhook scope Namespace, expected one of Cluster, Namespaced, *")
+}
+
+func Test_ValidateScopeCoherence(t *testing.T) {
+	testcases := []struct {
+		description  string
+		policy       []byte
+		expectedPath string
+	}{
+		{
+			description: "ClusterPolicy matching namespaced kinds",
+			policy:      []byte(`{"kind":"ClusterPolicy","metadata":{"name":"p"},"spec":{"rules":[{"name":"r","match":{"resources":{"kinds":["Pod","ConfigMap"]}},"validate":{"deny":{}}}]}}`),
+		},
+		{
+			description: "ClusterPolicy matching cluster-scoped kinds",
+			policy:      []byte(`{"kind":"ClusterPolicy","metadata":{"name":"p"},"spec":{"rules":[{"name":"r","match":{"resources":{"kinds":["Namespace"]}},"validate":{"deny":{}}}]}}`),
+		},
+		{
+			description: "Policy matching namespaced and custom kinds",
+			policy:      []byte(`{"kind":"Policy","metadata":{"name":"p","namespace":"default"},"spec":{"rules":[{"name":"r","match":{"resources":{"kinds":["Pod","Widget"]}},"validate":{"deny":{}}}]}}`),
+		},
+		{
+			description:  "Policy matching cluster-scoped kinds",
+			policy:       []byte(`{"kind":"Policy","metadata":{"name":"p","namespace":"default"},"spec":{"rules":[{"name":"r1","match":{"resources":{"kinds":["Pod"]}},"validate":{"deny":{}}},{"name":"r2","match":{"any":[{"kinds":["ClusterRole"]}]},"validate":{"deny":{}}}]}}`),
+			expectedPath: "spec.rules[1].match",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy kyverno.ClusterPolicy
+		err := json.Unmarshal(testcase.policy, &policy)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := ValidateScopeCoherence(policy, DefaultScopeLookup)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
@@ -0,0 +1,162 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	dclient "github.com/kyverno/kyverno/pkg/dclient"
+	"github.com/kyverno/kyverno/pkg/openapi"
+	"gopkg.in/yaml.v3"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ValidateSource decodes a single YAML or JSON policy document and validates it.
+// Duplicate keys are silently dropped, and numbers are decoded as float64, by the
+// decoders used for policies, so both are detected on the source and reported with
+// their line numbers before the policy is decoded. Quoted boolean values are reported
+// as warnings with their line numbers.
+func ValidateSource(source []byte, client *dclient.Client, mock bool, openAPIController *openapi.Controller) (*kyverno.ClusterPolicy, error) {
+	if err := validateDuplicateKeys(source); err != nil {
+		return nil, err
+	}
+
+	if err := validateNumericPrecision(source); err != nil {
+		return nil, err
+	}
+
+	warnQuotedBooleans(source)
+
+	policyBytes, err := k8syaml.ToJSON(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to JSON: %v", err)
+	}
+
+	policy := &kyverno.ClusterPolicy{}
+	if err := json.Unmarshal(policyBytes, policy); err != nil {
+		return nil, fmt.Errorf("failed to decode policy: %v", err)
+	}
+
+	if err := Validate(policy, client, mock, openAPIController); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// validateDuplicateKeys returns an error listing all keys defined more than once in the same map
+func validateDuplicateKeys(source []byte) error {
+	var document yaml.Node
+	if err := yaml.Unmarshal(source, &document); err != nil {
+		return fmt.Errorf("failed to parse policy: %v", err)
+	}
+
+	var duplicates []string
+	findDuplicateKeys(&document, "", &duplicates)
+	if len(duplicates) > 0 {
+		return fmt.Errorf("duplicate keys found: %s", strings.Join(duplicates, "; "))
+	}
+
+	return nil
+}
+
+func findDuplicateKeys(node *yaml.Node, path string, duplicates *[]string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, content := range node.Content {
+			findDuplicateKeys(content, path, duplicates)
+		}
+	case yaml.SequenceNode:
+		for i, content := range node.Content {
+			findDuplicateKeys(content, fmt.Sprintf("%s[%d]", path, i), duplicates)
+		}
+	case yaml.MappingNode:
+		lines := make(map[string]int, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := key.Value
+			if path != "" {
+				keyPath = path + "." + key.Value
+			}
+
+			if line, ok := lines[key.Value]; ok {
+				*duplicates = append(*duplicates, fmt.Sprintf("path: %s at line %d is already defined at line %d", keyPath, key.Line, line))
+			} else {
+				lines[key.Value] = key.Line
+			}
+
+			findDuplicateKeys(value, keyPath, duplicates)
+		}
+	}
+}
+
+// validateNumericPrecision returns an error listing all numbers that change value when
+// decoded as float64, such as integers larger than 2^53
+func validateNumericPrecision(source []byte) error {
+	var document yaml.Node
+	if err := yaml.Unmarshal(source, &document); err != nil {
+		return fmt.Errorf("failed to parse policy: %v", err)
+	}
+
+	var imprecise []string
+	findImpreciseNumbers(&document, "", &imprecise)
+	if len(imprecise) > 0 {
+		return fmt.Errorf("numbers lose precision when decoded, quote them to keep them as strings: %s", strings.Join(imprecise, "; "))
+	}
+
+	return nil
+}
+
+func findImpreciseNumbers(node *yaml.Node, path string, imprecise *[]string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, content := range node.Content {
+			findImpreciseNumbers(content, path, imprecise)
+		}
+	case yaml.SequenceNode:
+		for i, content := range node.Content {
+			findImpreciseNumbers(content, fmt.Sprintf("%s[%d]", path, i), imprecise)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyPath := node.Content[i].Value
+			if path != "" {
+				keyPath = path + "." + keyPath
+			}
+
+			findImpreciseNumbers(node.Content[i+1], keyPath, imprecise)
+		}
+	case yaml.ScalarNode:
+		if node.Tag != "!!int" && node.Tag != "!!float" {
+			return
+		}
+
+		if !isPreciseNumber(node.Value) {
+			*imprecise = append(*imprecise, fmt.Sprintf("path: %s at line %d has value %s", path, node.Line, node.Value))
+		}
+	}
+}
+
+// isPreciseNumber returns true if the decimal number keeps its value when decoded as float64.
+// Numbers in other notations, such as hexadecimal, are not checked.
+func isPreciseNumber(value string) bool {
+	original, _, err := big.ParseFloat(value, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return true
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+
+	decoded, _, err := big.ParseFloat(strconv.FormatFloat(f, 'g', -1, 64), 10, 256, big.ToNearestEven)
+	if err != nil {
+		return false
+	}
+
+	return original.Cmp(decoded) == 0
+}
@@ -0,0 +1,185 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/kyverno/kyverno/pkg/openapi"
+	"gotest.tools/assert"
+)
+
+func Test_validateDuplicateKeys(t *testing.T) {
+	testcases := []struct {
+		description string
+		source      string
+		expectedErr string
+	}{
+		{
+			description: "no duplicates",
+			source: `
+spec:
+  rules:
+  - name: a
+  - name: b
+`,
+		},
+		{
+			description: "top level",
+			source: `
+spec: a
+spec: b
+`,
+			expectedErr: "duplicate keys found: path: spec at line 3 is already defined at line 2",
+		},
+		{
+			description: "nested map",
+			source: `
+spec:
+  background: false
+  validationFailureAction: audit
+  background: true
+`,
+			expectedErr: "duplicate keys found: path: spec.background at line 5 is already defined at line 3",
+		},
+		{
+			description: "inside a list",
+			source: `
+spec:
+  rules:
+  - name: check-image
+    validate:
+      pattern:
+        spec:
+          containers:
+          - image: "!*:latest"
+            image: "*:*"
+`,
+			expectedErr: "duplicate keys found: path: spec.rules[0].validate.pattern.spec.containers[0].image at line 10 is already defined at line 9",
+		},
+		{
+			description: "multiple duplicates",
+			source: `
+metadata:
+  name: a
+  name: b
+spec:
+  rules: []
+  rules: []
+`,
+			expectedErr: "duplicate keys found: path: metadata.name at line 4 is already defined at line 3; path: spec.rules at line 7 is already defined at line 6",
+		},
+		{
+			description: "same key in different maps",
+			source: `
+metadata:
+  name: a
+spec:
+  rules:
+  - name: a
+`,
+		},
+		{
+			description: "JSON",
+			source:      `{"spec": {"rules": [], "rules": []}}`,
+			expectedErr: "duplicate keys found: path: spec.rules at line 1 is already defined at line 1",
+		},
+	}
+
+	for _, testcase := range testcases {
+		err := validateDuplicateKeys([]byte(testcase.source))
+		if testcase.expectedErr == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.expectedErr, testcase.description)
+		}
+	}
+}
+
+func Test_validateNumericPrecision(t *testing.T) {
+	testcases := []struct {
+		description string
+		source      string
+		expectedErr string
+	}{
+		{
+			description: "safe numbers",
+			source: `
+spec:
+  rules:
+  - validate:
+      pattern:
+        spec:
+          replicas: 3
+          ratio: 0.1
+          limit: 9007199254740992
+`,
+		},
+		{
+			description: "large integer",
+			source: `
+spec:
+  rules:
+  - validate:
+      pattern:
+        spec:
+          limit: 9223372036854775807
+`,
+			expectedErr: "numbers lose precision when decoded, quote them to keep them as strings: path: spec.rules[0].validate.pattern.spec.limit at line 7 has value 9223372036854775807",
+		},
+		{
+			description: "quoted large integer",
+			source: `
+spec:
+  rules:
+  - validate:
+      pattern:
+        spec:
+          limit: "9223372036854775807"
+`,
+		},
+		{
+			description: "JSON",
+			source:      `{"spec": {"limit": 12345678901234567891}}`,
+			expectedErr: "numbers lose precision when decoded, quote them to keep them as strings: path: spec.limit at line 1 has value 12345678901234567891",
+		},
+	}
+
+	for _, testcase := range testcases {
+		err := validateNumericPrecision([]byte(testcase.source))
+		if testcase.expectedErr == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.expectedErr, testcase.description)
+		}
+	}
+}
+
+func Test_ValidateSource(t *testing.T) {
+	source := []byte(`
+apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  name: disallow-latest-tag
+spec:
+  rules:
+  - name: validate-image-tag
+    match:
+      resources:
+        kinds:
+        - Pod
+    validate:
+      message: "Using a mutable image tag e.g. 'latest' is not allowed"
+      pattern:
+        spec:
+          containers:
+          - image: "!*:latest"
+`)
+
+	openAPIController, _ := openapi.NewOpenAPIController()
+	policy, err := ValidateSource(source, nil, true, openAPIController)
+	assert.NilError(t, err)
+	assert.Equal(t, policy.Name, "disallow-latest-tag")
+
+	duplicated := append(source, []byte("  rules: []\n")...)
+	_, err = ValidateSource(duplicated, nil, true, openAPIController)
+	assert.Error(t, err, "duplicate keys found: path: spec.rules at line 19 is already defined at line 7")
+}
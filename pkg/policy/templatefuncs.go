@@ -0,0 +1,86 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+)
+
+// templateFunctions are the JMESPath functions available in variables. map is missing as
+// the JMESPath library registers it under a wrong name.
+var templateFunctions = map[string]bool{
+	"abs":         true,
+	"avg":         true,
+	"ceil":        true,
+	"contains":    true,
+	"ends_with":   true,
+	"floor":       true,
+	"join":        true,
+	"keys":        true,
+	"length":      true,
+	"max":         true,
+	"max_by":      true,
+	"merge":       true,
+	"min":         true,
+	"min_by":      true,
+	"not_null":    true,
+	"reverse":     true,
+	"sort":        true,
+	"sort_by":     true,
+	"starts_with": true,
+	"sum":         true,
+	"to_array":    true,
+	"to_number":   true,
+	"to_string":   true,
+	"type":        true,
+	"values":      true,
+}
+
+var (
+	regexStringLiteral = regexp.MustCompile("'(?:[^'\\\\]|\\\\.)*'|`(?:[^`\\\\]|\\\\.)*`|\"(?:[^\"\\\\]|\\\\.)*\"")
+	regexFunctionCall  = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+)
+
+// validateTemplateFunctions returns the path of the first variable of the generate rule or
+// of the validation message calling a function which is not a JMESPath function
+func validateTemplateFunctions(rule kyverno.Rule) (string, error) {
+	type templateField struct {
+		path    string
+		element interface{}
+	}
+
+	fields := []templateField{{"validate.message", rule.Validation.Message}}
+	for i, generation := range rule.GenerateTargets() {
+		path := generateTargetPath(rule, i)
+		fields = append(fields,
+			templateField{path + ".name", generation.Name},
+			templateField{path + ".namespace", generation.Namespace},
+			templateField{path + ".data", generation.Data},
+		)
+	}
+
+	for _, field := range fields {
+		path, err := walkVariables(field.element, "", checkTemplateFunctions)
+		if err != nil {
+			if path != "" {
+				return field.path + "." + path, err
+			}
+
+			return field.path, err
+		}
+	}
+
+	return "", nil
+}
+
+func checkTemplateFunctions(variable string) error {
+	expression := regexStringLiteral.ReplaceAllString(variable[2:len(variable)-2], "''")
+	for _, groups := range regexFunctionCall.FindAllStringSubmatch(expression, -1) {
+		if !templateFunctions[groups[1]] {
+			return fmt.Errorf("unknown function %s in %s", groups[1], variable)
+		}
+	}
+
+	return nil
+}
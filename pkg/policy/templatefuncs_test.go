@@ -0,0 +1,57 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+)
+
+func Test_validateTemplateFunctions(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		path        string
+		err         string
+	}{
+		{
+			description: "known function",
+			rule:        []byte(`{"name":"r","validate":{"message":"{{ length(request.object.spec.containers) }} containers","deny":{}}}`),
+		},
+		{
+			description: "plain variable",
+			rule:        []byte(`{"name":"r","generate":{"kind":"ConfigMap","name":"{{request.object.metadata.name}}","namespace":"default","data":{"data":{"owner":"{{request.userInfo.username}}"}}}}`),
+		},
+		{
+			description: "parenthesis in a string literal",
+			rule:        []byte(`{"name":"r","validate":{"message":"{{ join(', ', ['lower(a)', 'b']) }}","deny":{}}}`),
+		},
+		{
+			description: "unknown function",
+			rule:        []byte(`{"name":"r","generate":{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"metadata":{"labels":{"owner":"{{ lower(request.object.metadata.name) }}"}}}}}`),
+			path:        "generate.data.metadata.labels.owner",
+			err:         "unknown function lower in {{ lower(request.object.metadata.name) }}",
+		},
+		{
+			description: "unknown function in generations",
+			rule:        []byte(`{"name":"r","generations":[{"kind":"ConfigMap","name":"cm","namespace":"default","data":{}},{"kind":"Secret","name":"{{ upper(request.object.metadata.name) }}","namespace":"default","data":{}}]}`),
+			path:        "generations[1].name",
+			err:         "unknown function upper in {{ upper(request.object.metadata.name) }}",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateTemplateFunctions(rule)
+		assert.Equal(t, path, testcase.path, testcase.description)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"fmt"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"github.com/kyverno/kyverno/pkg/engine"
+	"github.com/kyverno/kyverno/pkg/engine/context"
+	"github.com/kyverno/kyverno/pkg/engine/response"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Verdict is the outcome of a rule for a resource
+type Verdict string
+
+const (
+	// VerdictPass means the rule matched the resource and succeeded
+	VerdictPass Verdict = "pass"
+	// VerdictFail means the rule matched the resource and failed
+	VerdictFail Verdict = "fail"
+	// VerdictSkip means the rule did not apply to the resource
+	VerdictSkip Verdict = "skip"
+)
+
+// TestCase is a sample resource with the expected verdict of each rule, by rule name.
+// Rules without an expected verdict are not checked.
+type TestCase struct {
+	Resource unstructured.Unstructured
+	Expected map[string]Verdict
+}
+
+// TestResult is the verdict of a rule for the resource of a test case
+type TestResult struct {
+	// Resource is the namespace/kind/name of the resource
+	Resource string
+	Rule     string
+	Expected Verdict
+	Actual   Verdict
+	// Message is the engine message for the rule, if it applied
+	Message string
+}
+
+// Mismatch returns true if the actual verdict is not the expected one
+func (r TestResult) Mismatch() bool {
+	return r.Expected != r.Actual
+}
+
+// TestSuite applies the mutate and validate rules of the policy to the resource of each
+// test case, without a cluster, and returns the verdict of every rule with an expectation.
+// Validate rules are applied to the mutated resource, as done at admission.
+func TestSuite(policy kyverno.ClusterPolicy, cases []TestCase) ([]TestResult, error) {
+	rules := make(map[string]kyverno.Rule, len(policy.Spec.Rules))
+	for _, rule := range policy.Spec.Rules {
+		rules[rule.Name] = rule
+	}
+
+	var results []TestResult
+	for i, testCase := range cases {
+		for ruleName := range testCase.Expected {
+			rule, ok := rules[ruleName]
+			if !ok {
+				return nil, fmt.Errorf("cases[%d]: rule %s not found", i, ruleName)
+			}
+
+			if len(rule.GenerateTargets()) > 0 {
+				return nil, fmt.Errorf("cases[%d]: rule %s is a generate rule, only mutate and validate rules are supported", i, ruleName)
+			}
+		}
+
+		responses, err := applyTestCase(policy, testCase.Resource)
+		if err != nil {
+			return nil, fmt.Errorf("cases[%d]: %v", i, err)
+		}
+
+		resource := fmt.Sprintf("%s/%s/%s", testCase.Resource.GetNamespace(), testCase.Resource.GetKind(), testCase.Resource.GetName())
+		for _, rule := range policy.Spec.Rules {
+			expected, ok := testCase.Expected[rule.Name]
+			if !ok {
+				continue
+			}
+
+			result := TestResult{Resource: resource, Rule: rule.Name, Expected: expected, Actual: VerdictSkip}
+			if ruleResponse, ok := responses[rule.Name]; ok {
+				result.Message = ruleResponse.Message
+				result.Actual = VerdictFail
+				if ruleResponse.Success {
+					result.Actual = VerdictPass
+				}
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// applyTestCase returns the responses of the rules that applied to the resource, by rule name
+func applyTestCase(policy kyverno.ClusterPolicy, resource unstructured.Unstructured) (map[string]response.RuleResponse, error) {
+	raw, err := resource.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource: %v", err)
+	}
+
+	ctx := context.NewContext()
+	if err := ctx.AddResource(raw); err != nil {
+		return nil, fmt.Errorf("failed to add resource to context: %v", err)
+	}
+
+	if err := ctx.AddNamespace(resource.GetNamespace()); err != nil {
+		return nil, fmt.Errorf("failed to add namespace to context: %v", err)
+	}
+
+	mutateResponse := engine.Mutate(&engine.PolicyContext{Policy: policy, NewResource: resource, JSONContext: ctx})
+	validateResponse := engine.Validate(&engine.PolicyContext{Policy: policy, NewResource: mutateResponse.PatchedResource, JSONContext: ctx})
+
+	responses := make(map[string]response.RuleResponse)
+	for _, ruleResponse := range mutateResponse.PolicyResponse.Rules {
+		responses[ruleResponse.Name] = ruleResponse
+	}
+
+	for _, ruleResponse := range validateResponse.PolicyResponse.Rules {
+		responses[ruleResponse.Name] = ruleResponse
+	}
+
+	return responses, nil
+}
@@ -0,0 +1,64 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_TestSuite(t *testing.T) {
+	rawPolicy := []byte(`{"metadata":{"name":"suite"},"spec":{"rules":[
+		{"name":"add-label","match":{"resources":{"kinds":["Pod"]}},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"team":"web"}}}}},
+		{"name":"require-team","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"labels":{"team":"?*"}}}}},
+		{"name":"no-latest","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"containers":[{"image":"!*:latest"}]}}}},
+		{"name":"replicas","match":{"resources":{"kinds":["Deployment"]}},"validate":{"pattern":{"spec":{"replicas":">=2"}}}}
+	]}}`)
+
+	var policy kyverno.ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	pod := func(image string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+			"spec":       map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "web", "image": image}}},
+		}}
+	}
+
+	cases := []TestCase{
+		{
+			Resource: pod("nginx:1.19"),
+			Expected: map[string]Verdict{"add-label": VerdictPass, "require-team": VerdictPass, "no-latest": VerdictPass, "replicas": VerdictSkip},
+		},
+		{
+			Resource: pod("nginx:latest"),
+			Expected: map[string]Verdict{"no-latest": VerdictPass, "replicas": VerdictFail},
+		},
+	}
+
+	results, err := TestSuite(policy, cases)
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 6)
+
+	var mismatches []TestResult
+	for _, result := range results {
+		if result.Mismatch() {
+			mismatches = append(mismatches, result)
+		}
+	}
+
+	assert.Equal(t, len(mismatches), 2)
+	assert.Equal(t, mismatches[0].Rule, "no-latest")
+	assert.Equal(t, mismatches[0].Actual, VerdictFail)
+	assert.Equal(t, mismatches[0].Resource, "default/Pod/web")
+	assert.Equal(t, mismatches[1].Rule, "replicas")
+	assert.Equal(t, mismatches[1].Actual, VerdictSkip)
+
+	_, err = TestSuite(policy, []TestCase{{Resource: pod("nginx"), Expected: map[string]Verdict{"missing": VerdictPass}}})
+	assert.Error(t, err, "cases[0]: rule missing not found")
+}
@@ -5,15 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/jmespath/go-jmespath"
 	"github.com/kyverno/kyverno/pkg/engine"
 	"github.com/kyverno/kyverno/pkg/kyverno/common"
+	policycommon "github.com/kyverno/kyverno/pkg/policy/common"
 
 	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
 	dclient "github.com/kyverno/kyverno/pkg/dclient"
 	"github.com/kyverno/kyverno/pkg/openapi"
+	"github.com/kyverno/kyverno/pkg/policy/analysis"
+	"github.com/kyverno/kyverno/pkg/policy/generate"
+	"github.com/kyverno/kyverno/pkg/policy/mutate"
 	"github.com/kyverno/kyverno/pkg/utils"
 	"github.com/minio/minio/pkg/wildcard"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -21,27 +26,171 @@ import (
 	log "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	// minWebhookTimeoutSeconds and maxWebhookTimeoutSeconds are the bounds of
+	// the admission webhook timeout enforced by the API server
+	minWebhookTimeoutSeconds int32 = 1
+	maxWebhookTimeoutSeconds int32 = 30
+)
+
+// ValidateOptions configures the limits enforced by ValidateWithOptions
+type ValidateOptions struct {
+	// MaxPatchOps is the limit of JSON patch operations in a single mutation,
+	// mutate.DefaultMaxPatchOps is used if not set
+	MaxPatchOps int
+
+	// ScopeLookup resolves whether kinds are namespaced,
+	// DefaultScopeLookup is used if not set
+	ScopeLookup ScopeLookup
+
+	// APIVersionLookup resolves the API versions serving a kind,
+	// DefaultAPIVersionLookup is used if not set
+	APIVersionLookup APIVersionLookup
+
+	// DeprecatedLabelKeys maps deprecated label and annotation keys to their replacement.
+	// Selectors and annotations of match and exclude blocks using them are reported.
+	// No keys are checked if not set
+	DeprecatedLabelKeys map[string]string
+
+	// MaxDepth is the limit of nested maps and arrays in mutate overlays and validate
+	// patterns, the DefaultMaxDepth of pkg/policy/common is used if not set
+	MaxDepth int
+
+	// MaxRules is the limit of rules in a single policy, the number of rules is not
+	// limited if not set
+	MaxRules int
+
+	// MaxMessageLength is the limit of characters in validate messages, excluding variables
+	// which are only resolved on admission. The length is not limited if not set
+	MaxMessageLength int
+
+	// FieldDefaults maps field paths, such as spec.containers.imagePullPolicy, to their default
+	// values. Mutations setting a field to its default are reported. Not checked if not set
+	FieldDefaults map[string]interface{}
+
+	// CRDLookup resolves whether generated kinds are custom resources with an established
+	// definition. Rules generating custom resources without one are reported. Not checked if
+	// not set
+	CRDLookup generate.CRDLookup
+
+	// AllowWildcardRBAC disables the warning for generated Roles and ClusterRoles granting
+	// all verbs on all resources
+	AllowWildcardRBAC bool
+}
+
+func (o ValidateOptions) maxPatchOps() int {
+	if o.MaxPatchOps <= 0 {
+		return mutate.DefaultMaxPatchOps
+	}
+
+	return o.MaxPatchOps
+}
+
+func (o ValidateOptions) maxDepth() int {
+	if o.MaxDepth <= 0 {
+		return policycommon.DefaultMaxDepth
+	}
+
+	return o.MaxDepth
+}
+
+func (o ValidateOptions) apiVersionLookup() APIVersionLookup {
+	if o.APIVersionLookup == nil {
+		return DefaultAPIVersionLookup
+	}
+
+	return o.APIVersionLookup
+}
+
+func (o ValidateOptions) scopeLookup() ScopeLookup {
+	if o.ScopeLookup == nil {
+		return DefaultScopeLookup
+	}
+
+	return o.ScopeLookup
+}
+
 // Validate does some initial check to verify some conditions
 // - One operation per rule
 // - ResourceDescription mandatory checks
 func Validate(policy *kyverno.ClusterPolicy, client *dclient.Client, mock bool, openAPIController *openapi.Controller) error {
+	return ValidateWithOptions(policy, client, mock, openAPIController, ValidateOptions{})
+}
+
+// ValidateWithOptions validates the policy like Validate, with the limits set in opts
+func ValidateWithOptions(policy *kyverno.ClusterPolicy, client *dclient.Client, mock bool, openAPIController *openapi.Controller, opts ValidateOptions) error {
 	p := *policy
 	if len(common.PolicyHasVariables(p)) > 0 && common.PolicyHasNonAllowedVariables(p) {
 		return fmt.Errorf("policy contains invalid variables")
 	}
 
+	warnings, err := p.ValidateAPIVersion()
+	if err != nil {
+		return fmt.Errorf("path: apiVersion: %v", err)
+	}
+
+	for _, warning := range warnings {
+		log.Log.V(1).Info("warning: path: " + warning)
+	}
+
 	// policy name is stored in the label of the report change request
 	if len(p.Name) > 63 {
 		return fmt.Errorf("invalid policy name %s: must be no more than 63 characters", p.Name)
 	}
 
+	if err := validateRuleCount(p.Spec.Rules, opts.MaxRules); err != nil {
+		return fmt.Errorf("path: spec.rules: %v", err)
+	}
+
 	if path, err := validateUniqueRuleName(p); err != nil {
 		return fmt.Errorf("path: spec.%s: %v", path, err)
 	}
+
+	if path, err := validateUniqueRulePriority(p); err != nil {
+		return fmt.Errorf("path: spec.%s: %v", path, err)
+	}
+
+	if path, err := ValidateEffectiveMatch(p); err != nil {
+		return fmt.Errorf("path: %s: %v", path, err)
+	}
+
+	if err := validateWebhookTimeout(p.Spec.WebhookTimeoutSeconds); err != nil {
+		return fmt.Errorf("path: spec.webhookTimeoutSeconds: %v", err)
+	}
+
+	if err := validateWebhookScope(p.Spec.WebhookScope); err != nil {
+		return fmt.Errorf("path: spec.webhookScope: %v", err)
+	}
+
+	if path, err := validateFailureActionOverrides(p.Spec.FailureActionOverrides); err != nil {
+		return fmt.Errorf("path: spec.%s: %v", path, err)
+	}
+
+	if err := p.ValidateMatchDoesNotDependOnMutation(); err != nil {
+		log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules: %v", err))
+	}
+
+	if path, err := ValidateScopeCoherence(p, opts.scopeLookup()); err != nil {
+		log.Log.V(1).Info(fmt.Sprintf("warning: path: %s: %v", path, err))
+	}
+
+	if path, err := validateMatchConditions(p.Spec.MatchConditions); err != nil {
+		return fmt.Errorf("path: spec.%s: %v", path, err)
+	}
 	if p.Spec.Background == nil || *p.Spec.Background == true {
 		if err := ContainsVariablesOtherThanObject(p); err != nil {
 			return fmt.Errorf("only select variables are allowed in background mode. Set spec.background=false to disable background mode for this policy rule: %s ", err)
 		}
+
+		for i, rule := range p.Spec.Rules {
+			if rule.SkipBackgroundRequests {
+				continue
+			}
+
+			if path, err := validateBackgroundGenerateVariables(rule); err != nil {
+				return fmt.Errorf("path: spec.rules[%d].%s: %v", i, path, err)
+			}
+		}
 	}
 
 	for i, rule := range p.Spec.Rules {
@@ -53,6 +202,74 @@ func Validate(policy *kyverno.ClusterPolicy, client *dclient.Client, mock bool,
 			return fmt.Errorf("path: spec.rules[%d].%s: %v", i, path, err)
 		}
 
+		if err := validateKindNames(rule.MatchResources.Kinds); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].match.resources.kinds: %v", i, err))
+		}
+
+		if err := validateKindNames(rule.ExcludeResources.Kinds); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].exclude.resources.kinds: %v", i, err))
+		}
+
+		if err := validateExcludeSubjectsScope(rule.ExcludeResources); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].exclude: %v", i, err))
+		}
+
+		if err := validateAnyAllStructure(rule); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].exclude: %v", i, err))
+		}
+
+		if path, err := validateRuleLabelKeys(rule, opts.DeprecatedLabelKeys); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].%s: %v", i, path, err))
+		}
+
+		if path, err := validateGenerateSelfTarget(rule); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].%s: %v", i, path, err))
+		}
+
+		if err := validateSystemNamespaceWildcards(rule, p.Spec.AllowSystemNamespaces); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].match: %v", i, err))
+		}
+
+		if path, err := validateGenerateSystemNamespace(rule, p.Spec.AllowSystemNamespaces); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].%s: %v", i, path, err))
+		}
+
+		if err := validateSkipBackgroundRequests(rule); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].skipBackgroundRequests: %v", i, err))
+		}
+
+		if path, err := validateQuotedBooleans(rule); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].%s: %v", i, path, err))
+		}
+
+		if path, err := validateAnyPatternShadowing(rule); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].%s: %v", i, path, err))
+		}
+
+		if path, err := validateNullPatternValues(rule); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].%s: %v", i, path, err))
+		}
+
+		if err := rule.ValidateAPIVersionConsistency(); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d]: %v", i, err))
+		}
+
+		if err := rule.ValidateGenerateDataImmutableFields(); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].%v", i, err))
+		}
+
+		if err := rule.Mutation.ValidateIdempotentListMerge(); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].mutate.%v", i, err))
+		}
+
+		if err := rule.Mutation.ValidateNonRedundant(opts.FieldDefaults); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].mutate.%v", i, err))
+		}
+
+		if err := validateMessageLength(rule.Validation.Message, opts.MaxMessageLength); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].validate.message: %v", i, err))
+		}
+
 		// validate rule types
 		// only one type of rule is allowed per rule
 		if err := validateRuleType(rule); err != nil {
@@ -96,17 +313,65 @@ func Validate(policy *kyverno.ClusterPolicy, client *dclient.Client, mock bool,
 			return fmt.Errorf("path: spec.rules[%v]: rule is matching an empty set", rule.Name)
 		}
 
+		if err := rule.ValidateMatchExcludeSelectorEquality(); err != nil {
+			return fmt.Errorf("path: spec.rules[%d].exclude: %v", i, err)
+		}
+
+		if err := analysis.ValidateNameGlobCoherence(rule); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].exclude.resources.name: %v", i, err))
+		}
+
+		if err := validateGenerateNamespaceScope(rule, opts.scopeLookup()); err != nil {
+			return fmt.Errorf("path: spec.rules[%d]: %v", i, err)
+		}
+
+		if path, err := validateClusterScopedCloneSync(rule, opts.scopeLookup()); err != nil {
+			return fmt.Errorf("path: spec.rules[%d].%s: %v", i, path, err)
+		}
+
+		if err := warnWebhookScope(rule, p.Spec.WebhookScope, opts.scopeLookup()); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].match: %v", i, err))
+		}
+
+		if path, err := warnClusterScopedCloneSync(rule, opts.scopeLookup()); err != nil {
+			log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].%s: %v", i, path, err))
+		}
+
+		for j, generation := range rule.GenerateTargets() {
+			if !opts.AllowWildcardRBAC {
+				if path, err := generate.WarnKindData(generation.Kind, generation.Data); err != nil {
+					log.Log.V(1).Info(fmt.Sprintf("warning: path: spec.rules[%d].%s.data.%s: %v", i, generateTargetPath(rule, j), path, err))
+				}
+			}
+
+			if err := validateGenerateAPIVersion(generation, opts.apiVersionLookup()); err != nil {
+				return fmt.Errorf("path: spec.rules[%d].%s.apiVersion: %v", i, generateTargetPath(rule, j), err)
+			}
+		}
+
+		if path, err := validateGenerateDataVariables(rule); err != nil {
+			return fmt.Errorf("path: spec.rules[%d].%s: %v", i, path, err)
+		}
+
+		if path, err := validateTemplateFunctions(rule); err != nil {
+			return fmt.Errorf("path: spec.rules[%d].%s: %v", i, path, err)
+		}
+
+		if path, err := validateForEachVariables(rule); err != nil {
+			return fmt.Errorf("path: spec.rules[%d].%s: %v", i, path, err)
+		}
+
 		// validate rule actions
 		// - Mutate
 		// - Validate
 		// - Generate
-		if err := validateActions(i, rule, client, mock); err != nil {
+		if err := validateActions(i, rule, client, mock, opts); err != nil {
 			return err
 		}
 
 		// If a rules match block does not match any kind,
 		// we should only allow such rules to have metadata in its overlay
-		if len(rule.MatchResources.Kinds) == 0 {
+		if len(matchedKinds(rule.MatchResources)) == 0 {
 			if !ruleOnlyDealsWithResourceMetaData(rule) {
 				return fmt.Errorf("policy can only deal with the metadata field of the resource if" +
 					" the rule does not match an kind")
@@ -119,10 +384,14 @@ func Validate(policy *kyverno.ClusterPolicy, client *dclient.Client, mock bool,
 		}
 
 		// add label to source mentioned in policy
-		if !mock && rule.Generation.Clone.Name != "" {
-			obj, err := client.GetResource("", rule.Generation.Kind, rule.Generation.Clone.Namespace, rule.Generation.Clone.Name)
+		for _, generation := range rule.GenerateTargets() {
+			if mock || generation.Clone.Name == "" {
+				continue
+			}
+
+			obj, err := client.GetResource("", generation.Kind, generation.Clone.Namespace, generation.Clone.Name)
 			if err != nil {
-				log.Log.Error(err, fmt.Sprintf("source resource %s/%s/%s not found.", rule.Generation.Kind, rule.Generation.Clone.Namespace, rule.Generation.Clone.Name))
+				log.Log.Error(err, fmt.Sprintf("source resource %s/%s/%s not found.", generation.Kind, generation.Clone.Namespace, generation.Clone.Name))
 				continue
 			}
 
@@ -149,7 +418,7 @@ func Validate(policy *kyverno.ClusterPolicy, client *dclient.Client, mock bool,
 			if updateSource {
 				log.Log.V(4).Info("updating existing clone source")
 				obj.SetLabels(label)
-				_, err = client.UpdateResource(obj.GetAPIVersion(), rule.Generation.Kind, rule.Generation.Clone.Namespace, obj, false)
+				_, err = client.UpdateResource(obj.GetAPIVersion(), generation.Kind, generation.Clone.Namespace, obj, false)
 				if err != nil {
 					log.Log.Error(err, "failed to update source  name:%v namespace:%v kind:%v", obj.GetName(), obj.GetNamespace(), obj.GetKind())
 					continue
@@ -159,6 +428,19 @@ func Validate(policy *kyverno.ClusterPolicy, client *dclient.Client, mock bool,
 		}
 	}
 
+	contradictions, err := p.ValidateContradictoryRules()
+	if err != nil {
+		return fmt.Errorf("path: spec.rules: %v", err)
+	}
+
+	for _, contradiction := range contradictions {
+		log.Log.V(1).Info("warning: path: spec.rules: " + contradiction)
+	}
+
+	if err := p.ValidateMutateGenerateInteraction(); err != nil {
+		log.Log.V(1).Info("warning: " + err.Error())
+	}
+
 	if !mock {
 		if err := openAPIController.ValidatePolicyFields(p); err != nil {
 			return err
@@ -180,6 +462,11 @@ func doMatchAndExcludeConflict(rule kyverno.Rule) bool {
 		return false
 	}
 
+	// only single resource descriptions are compared
+	if len(rule.ExcludeResources.Any) > 0 || len(rule.ExcludeResources.All) > 0 {
+		return false
+	}
+
 	excludeRoles := make(map[string]bool)
 	for _, role := range rule.ExcludeResources.UserInfo.Roles {
 		excludeRoles[role] = true
@@ -474,11 +761,23 @@ func validateResources(rule kyverno.Rule) (string, error) {
 	}
 
 	// matched resources
-	if path, err := validateMatchedResourceDescription(rule.MatchResources.ResourceDescription); err != nil {
+	if len(rule.MatchResources.Any) > 0 || len(rule.MatchResources.All) > 0 {
+		if path, err := validateAnyAllResourceDescriptions(rule.MatchResources.ResourceDescription, rule.MatchResources.Any, rule.MatchResources.All); err != nil {
+			return fmt.Sprintf("match.%s", path), err
+		}
+	} else if path, err := validateMatchedResourceDescription(rule.MatchResources.ResourceDescription); err != nil {
 		return fmt.Sprintf("match.resources.%s", path), err
 	}
+	if path, err := validateOperations(rule); err != nil {
+		return fmt.Sprintf("match.%s", path), err
+	}
+
 	// exclude resources
-	if path, err := validateExcludeResourceDescription(rule.ExcludeResources.ResourceDescription); err != nil {
+	if len(rule.ExcludeResources.Any) > 0 || len(rule.ExcludeResources.All) > 0 {
+		if path, err := validateAnyAllResourceDescriptions(rule.ExcludeResources.ResourceDescription, rule.ExcludeResources.Any, rule.ExcludeResources.All); err != nil {
+			return fmt.Sprintf("exclude.%s", path), err
+		}
+	} else if path, err := validateExcludeResourceDescription(rule.ExcludeResources.ResourceDescription); err != nil {
 		return fmt.Sprintf("exclude.resources.%s", path), err
 	}
 
@@ -493,6 +792,14 @@ func validateResources(rule kyverno.Rule) (string, error) {
 		if path, err := validateConditions(rule.Validation.Deny.Conditions, "conditions"); err != nil {
 			return fmt.Sprintf("validate.deny.%s", path), err
 		}
+
+		if errs := rule.Validation.ValidateDenyConditions(); len(errs) > 0 {
+			messages := make([]string, len(errs))
+			for i, err := range errs {
+				messages[i] = err.Error()
+			}
+			return "validate.deny", fmt.Errorf("invalid conditions: %s", strings.Join(messages, "; "))
+		}
 	}
 
 	return "", nil
@@ -571,6 +878,67 @@ func validateUniqueRuleName(p kyverno.ClusterPolicy) (string, error) {
 	return "", nil
 }
 
+// validateUniqueRulePriority checks the rules with a priority have distinct, positive priorities
+func validateUniqueRulePriority(p kyverno.ClusterPolicy) (string, error) {
+	priorities := make(map[int]string)
+	for i, rule := range p.Spec.Rules {
+		if rule.Priority == 0 {
+			continue
+		}
+
+		if rule.Priority < 0 {
+			return fmt.Sprintf("rules[%d].priority", i), fmt.Errorf("invalid priority %d: must be positive", rule.Priority)
+		}
+
+		if name, ok := priorities[rule.Priority]; ok {
+			return fmt.Sprintf("rules[%d].priority", i), fmt.Errorf("duplicate priority %d: already set on rule '%s'", rule.Priority, name)
+		}
+		priorities[rule.Priority] = rule.Name
+	}
+
+	return "", nil
+}
+
+// validateRuleCount checks the policy has no more rules than the limit, policies with many
+// rules are slow to evaluate and hard to maintain, and are better split. A limit of zero
+// or less disables the check.
+func validateRuleCount(rules []kyverno.Rule, maxRules int) error {
+	if maxRules > 0 && len(rules) > maxRules {
+		return fmt.Errorf("policy has %d rules, which exceeds the maximum of %d", len(rules), maxRules)
+	}
+
+	return nil
+}
+
+// validateMessageLength checks the message, without its variables, has no more characters
+// than the limit. Long messages are truncated in the output of kubectl. A limit of zero or
+// less disables the check.
+func validateMessageLength(message string, maxLength int) error {
+	if maxLength <= 0 {
+		return nil
+	}
+
+	length := len([]rune(regexVariable.ReplaceAllString(message, "")))
+	if length > maxLength {
+		return fmt.Errorf("message has %d characters, which exceeds the maximum of %d", length, maxLength)
+	}
+
+	return nil
+}
+
+// validateWebhookTimeout checks the webhook timeout is within the range accepted by the API server
+func validateWebhookTimeout(timeout *int32) error {
+	if timeout == nil {
+		return nil
+	}
+
+	if *timeout < minWebhookTimeoutSeconds || *timeout > maxWebhookTimeoutSeconds {
+		return fmt.Errorf("invalid webhook timeout %d: must be between %d and %d seconds", *timeout, minWebhookTimeoutSeconds, maxWebhookTimeoutSeconds)
+	}
+
+	return nil
+}
+
 // validateRuleType checks only one type of rule is defined per rule
 func validateRuleType(r kyverno.Rule) error {
 	ruleTypes := []bool{r.HasMutate(), r.HasValidate(), r.HasGenerate()}
@@ -679,11 +1047,257 @@ func validateMatchedResourceDescription(rd kyverno.ResourceDescription) (string,
 	return "", nil
 }
 
+// pluralKinds maps resource names commonly copied from kubectl to their kind
+var pluralKinds = map[string]string{
+	"configmaps":               "ConfigMap",
+	"cronjobs":                 "CronJob",
+	"daemonsets":               "DaemonSet",
+	"deployments":              "Deployment",
+	"ingresses":                "Ingress",
+	"jobs":                     "Job",
+	"namespaces":               "Namespace",
+	"networkpolicies":          "NetworkPolicy",
+	"persistentvolumeclaims":   "PersistentVolumeClaim",
+	"persistentvolumes":        "PersistentVolume",
+	"pods":                     "Pod",
+	"replicasets":              "ReplicaSet",
+	"rolebindings":             "RoleBinding",
+	"roles":                    "Role",
+	"secrets":                  "Secret",
+	"serviceaccounts":          "ServiceAccount",
+	"services":                 "Service",
+	"statefulsets":             "StatefulSet",
+	"clusterrolebindings":      "ClusterRoleBinding",
+	"clusterroles":             "ClusterRole",
+	"horizontalpodautoscalers": "HorizontalPodAutoscaler",
+}
+
+// systemNamespaces are the namespaces reserved for Kubernetes system components
+var systemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// validateSystemNamespaceWildcards returns an error if a namespace wildcard of the match
+// block selects system namespaces that are not excluded, unless they are allowed
+func validateSystemNamespaceWildcards(rule kyverno.Rule, allowSystemNamespaces bool) error {
+	if allowSystemNamespaces {
+		return nil
+	}
+
+	namespaces := append([]string{}, rule.MatchResources.Namespaces...)
+	for _, rd := range rule.MatchResources.Any {
+		namespaces = append(namespaces, rd.Namespaces...)
+	}
+
+	for _, rd := range rule.MatchResources.All {
+		namespaces = append(namespaces, rd.Namespaces...)
+	}
+
+	for _, namespace := range namespaces {
+		if !HasWildcard(namespace) {
+			continue
+		}
+
+		for _, systemNamespace := range systemNamespaces {
+			if wildcard.Match(namespace, systemNamespace) && !utils.ContainsNamepace(rule.ExcludeResources.Namespaces, systemNamespace) {
+				return fmt.Errorf("namespace %s matches the system namespace %s, exclude it or set spec.allowSystemNamespaces to confirm", namespace, systemNamespace)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateGenerateSystemNamespace returns the path of the first generate block creating
+// resources in a system namespace, unless system namespaces are allowed
+func validateGenerateSystemNamespace(rule kyverno.Rule, allowSystemNamespaces bool) (string, error) {
+	if allowSystemNamespaces {
+		return "", nil
+	}
+
+	for i, generation := range rule.GenerateTargets() {
+		namespace := generation.Namespace
+		if utils.ContainsString(systemNamespaces, namespace) {
+			return generateTargetPath(rule, i) + ".namespace", fmt.Errorf("resources are generated in the system namespace %s, set spec.allowSystemNamespaces to confirm", namespace)
+		}
+	}
+
+	return "", nil
+}
+
+// validateGenerateSelfTarget returns the path of the first generate block creating a resource
+// of a matched kind named after the triggering resource, which can trigger the rule again.
+// Names derived from the trigger name, such as the name prefixed with the namespace or the
+// kind, trigger the rule again with a new name each time.
+func validateGenerateSelfTarget(rule kyverno.Rule) (string, error) {
+	kinds := matchedKinds(rule.MatchResources)
+	for i, generation := range rule.GenerateTargets() {
+		if !referencesTriggerName(generation.Name) {
+			continue
+		}
+
+		for _, kind := range kinds {
+			if kind == generation.Kind {
+				return generateTargetPath(rule, i), fmt.Errorf("generated %s is named after the triggering %s, which may generate resources infinitely", kind, kind)
+			}
+		}
+	}
+
+	return "", nil
+}
+
+var regexTriggerName = regexp.MustCompile(`request\.object\.metadata\.name\b`)
+
+// referencesTriggerName checks if a variable of the value references the name of the
+// triggering resource, whitespace in variables is ignored
+func referencesTriggerName(value string) bool {
+	for _, variable := range regexVariable.FindAllString(value, -1) {
+		if regexTriggerName.MatchString(strings.Join(strings.Fields(variable), "")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateSkipBackgroundRequests returns an error if a generate rule skips background
+// requests, as generate rules are applied on admission requests only
+func validateSkipBackgroundRequests(rule kyverno.Rule) error {
+	if !rule.SkipBackgroundRequests || len(rule.GenerateTargets()) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("generate rules are not applied in background scans, skipBackgroundRequests has no effect")
+}
+
+// validateExcludeSubjectsScope returns an error if the exclude block lists subjects without
+// any resource description, which excludes the subjects from all the resources of the rule
+func validateExcludeSubjectsScope(exclude kyverno.ExcludeResources) error {
+	if len(exclude.Subjects) == 0 || !reflect.DeepEqual(exclude.ResourceDescription, kyverno.ResourceDescription{}) ||
+		len(exclude.Any) > 0 || len(exclude.All) > 0 {
+		return nil
+	}
+
+	return fmt.Errorf("subjects are excluded from all resources matched by the rule, add exclude.resources to narrow the exclusion")
+}
+
+// validateKindNames returns an error if a kind looks like a lowercase plural resource
+// name instead of a kind. Kinds are CamelCase, so plural kinds of custom resources
+// are not reported.
+func validateKindNames(kinds []string) error {
+	for _, kind := range kinds {
+		if kind == "" || kind == "*" || strings.ToLower(kind) != kind || !strings.HasSuffix(kind, "s") {
+			continue
+		}
+
+		return fmt.Errorf("kind %s looks like a resource name, kinds are singular and CamelCase. Did you mean %s?", kind, singularKind(kind))
+	}
+
+	return nil
+}
+
+// singularKind guesses the kind of a lowercase plural resource name
+func singularKind(resource string) string {
+	if kind, ok := pluralKinds[resource]; ok {
+		return kind
+	}
+
+	singular := strings.TrimSuffix(resource, "s")
+	if strings.HasSuffix(resource, "ies") {
+		singular = strings.TrimSuffix(resource, "ies") + "y"
+	} else if strings.HasSuffix(resource, "sses") || strings.HasSuffix(resource, "xes") {
+		singular = strings.TrimSuffix(resource, "es")
+	}
+
+	return strings.ToUpper(singular[:1]) + singular[1:]
+}
+
+// validateAnyAllResourceDescriptions checks each description of the any and all blocks,
+// which cannot be combined with a single resource description
+func validateAnyAllResourceDescriptions(rd kyverno.ResourceDescription, anyDescriptions, allDescriptions []kyverno.ResourceDescription) (string, error) {
+	if !reflect.DeepEqual(rd, kyverno.ResourceDescription{}) {
+		return "resources", fmt.Errorf("a resource description cannot be combined with any or all resource descriptions")
+	}
+
+	for i, desc := range anyDescriptions {
+		if err := validateSubResourceDescription(desc); err != nil {
+			return fmt.Sprintf("any[%d]", i), err
+		}
+	}
+
+	for i, desc := range allDescriptions {
+		if err := validateSubResourceDescription(desc); err != nil {
+			return fmt.Sprintf("all[%d]", i), err
+		}
+	}
+
+	return "", nil
+}
+
+func validateSubResourceDescription(rd kyverno.ResourceDescription) error {
+	if reflect.DeepEqual(rd, kyverno.ResourceDescription{}) {
+		return fmt.Errorf("resource description cannot be empty")
+	}
+
+	return validateResourceDescription(rd)
+}
+
+// validateOperations checks the operations of the match block are admission operations
+// and that mutate rules do not apply to deletions
+func validateOperations(rule kyverno.Rule) (string, error) {
+	for i, operation := range rule.MatchResources.Operations {
+		if !utils.ContainsString([]string{"CREATE", "UPDATE", "DELETE", "CONNECT"}, operation) {
+			return fmt.Sprintf("operations[%d]", i), fmt.Errorf("unknown operation %s, expected one of [CREATE, UPDATE, DELETE, CONNECT]", operation)
+		}
+
+		if operation == "DELETE" && rule.HasMutate() {
+			return fmt.Sprintf("operations[%d]", i), fmt.Errorf("mutate rules cannot apply to DELETE, the deleted resource is not persisted")
+		}
+	}
+
+	return "", nil
+}
+
+// validateAnyAllStructure returns an error if only one of the match and exclude blocks uses
+// any or all resource descriptions, as mixing both structures is hard to follow
+func validateAnyAllStructure(rule kyverno.Rule) error {
+	matchAnyAll := len(rule.MatchResources.Any) > 0 || len(rule.MatchResources.All) > 0
+	excludeAnyAll := len(rule.ExcludeResources.Any) > 0 || len(rule.ExcludeResources.All) > 0
+	excludeResources := !reflect.DeepEqual(rule.ExcludeResources.ResourceDescription, kyverno.ResourceDescription{})
+
+	if matchAnyAll && excludeResources {
+		return fmt.Errorf("match uses any or all while exclude uses resources, use any or all in both blocks")
+	}
+
+	if !matchAnyAll && excludeAnyAll {
+		return fmt.Errorf("exclude uses any or all while match uses resources, use any or all in both blocks")
+	}
+
+	return nil
+}
+
+// matchedKinds returns the kinds selected by the match block, including
+// the kinds of the any and all resource descriptions
+func matchedKinds(match kyverno.MatchResources) []string {
+	kinds := append([]string{}, match.Kinds...)
+	for _, rd := range match.Any {
+		kinds = append(kinds, rd.Kinds...)
+	}
+
+	for _, rd := range match.All {
+		kinds = append(kinds, rd.Kinds...)
+	}
+
+	return kinds
+}
+
 func validateUserInfo(rule kyverno.Rule) (string, error) {
 	if err := validateRoles(rule.MatchResources.Roles); err != nil {
 		return "match.roles", err
 	}
 
+	if err := validateClusterRoles(rule.MatchResources.ClusterRoles); err != nil {
+		return "match.clusterRoles", err
+	}
+
 	if err := validateSubjects(rule.MatchResources.Subjects); err != nil {
 		return "match.subjects", err
 	}
@@ -692,6 +1306,10 @@ func validateUserInfo(rule kyverno.Rule) (string, error) {
 		return "exclude.roles", err
 	}
 
+	if err := validateClusterRoles(rule.ExcludeResources.ClusterRoles); err != nil {
+		return "exclude.clusterRoles", err
+	}
+
 	if err := validateSubjects(rule.ExcludeResources.Subjects); err != nil {
 		return "exclude.subjects", err
 	}
@@ -707,13 +1325,24 @@ func validateRoles(roles []string) error {
 
 	for _, r := range roles {
 		role := strings.Split(r, ":")
-		if len(role) != 2 {
+		if len(role) != 2 || strings.TrimSpace(role[0]) == "" || strings.TrimSpace(role[1]) == "" {
 			return fmt.Errorf("invalid role %s, expect namespace:name", r)
 		}
 	}
 	return nil
 }
 
+// a cluster role must have a name
+func validateClusterRoles(clusterRoles []string) error {
+	for i, clusterRole := range clusterRoles {
+		if strings.TrimSpace(clusterRole) == "" {
+			return fmt.Errorf("cluster role %d has an empty name", i)
+		}
+	}
+
+	return nil
+}
+
 // a namespace should be set in kind ServiceAccount of a subject
 func validateSubjects(subjects []rbacv1.Subject) error {
 	if len(subjects) == 0 {
@@ -754,6 +1383,37 @@ func validateResourceDescription(rd kyverno.ResourceDescription) error {
 			return errors.New("the requirements are not specified in selector")
 		}
 	}
+
+	if rd.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(rd.NamespaceSelector)
+		if err != nil {
+			return fmt.Errorf("invalid namespaceSelector: %v", err)
+		}
+		requirements, _ := selector.Requirements()
+		if len(requirements) == 0 {
+			return errors.New("the requirements are not specified in namespaceSelector")
+		}
+	}
+
+	if rd.MatchGeneration != "" {
+		if err := validateMatchGeneration(rd.MatchGeneration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateMatchGeneration checks the expression compares a generation field with an integer
+func validateMatchGeneration(expression string) error {
+	comparison, err := engine.ParseMatchGeneration(expression)
+	if err != nil {
+		return err
+	}
+
+	if comparison.Field != "metadata.generation" && comparison.Field != "status.observedGeneration" {
+		return fmt.Errorf("invalid matchGeneration %q: unsupported field %s, expected metadata.generation or status.observedGeneration", expression, comparison.Field)
+	}
+
 	return nil
 }
 
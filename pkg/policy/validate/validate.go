@@ -2,22 +2,33 @@ package validate
 
 import (
 	"fmt"
+	"strings"
 
 	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
 	commonAnchors "github.com/kyverno/kyverno/pkg/engine/anchor/common"
 	"github.com/kyverno/kyverno/pkg/policy/common"
+	"github.com/kyverno/kyverno/pkg/utils"
 )
 
 // Validate provides implementation to validate 'validate' rule
 type Validate struct {
 	// rule to hold 'validate' rule specifications
 	rule kyverno.Validation
+	// maxDepth is the limit of nested maps and arrays in patterns
+	maxDepth int
 }
 
 //NewValidateFactory returns a new instance of Mutate validation checker
 func NewValidateFactory(rule kyverno.Validation) *Validate {
+	return NewValidateFactoryWithLimit(rule, common.DefaultMaxDepth)
+}
+
+//NewValidateFactoryWithLimit returns a new instance of Validate validation checker
+//that allows patterns nested at most maxDepth levels
+func NewValidateFactoryWithLimit(rule kyverno.Validation, maxDepth int) *Validate {
 	m := Validate{
-		rule: rule,
+		rule:     rule,
+		maxDepth: maxDepth,
 	}
 	return &m
 }
@@ -31,18 +42,53 @@ func (v *Validate) Validate() (string, error) {
 	}
 
 	if rule.Pattern != nil {
-		if path, err := common.ValidatePattern(rule.Pattern, "/", []commonAnchors.IsAnchor{commonAnchors.IsConditionAnchor, commonAnchors.IsExistenceAnchor, commonAnchors.IsEqualityAnchor, commonAnchors.IsNegationAnchor}); err != nil {
+		if path, err := common.ValidateValidationPattern(rule.Pattern, "/", []commonAnchors.IsAnchor{commonAnchors.IsConditionAnchor, commonAnchors.IsExistenceAnchor, commonAnchors.IsEqualityAnchor, commonAnchors.IsNegationAnchor}, v.maxDepth); err != nil {
 			return fmt.Sprintf("pattern.%s", path), err
 		}
 	}
 
+	if path, err := v.validatePodSecurity(); err != nil {
+		return path, err
+	}
+
+	if rule.PodSecurity != nil && !v.evaluatedByEngine() {
+		return "podSecurity", fmt.Errorf("podSecurity is not evaluated by the engine, the rule must also declare deny conditions")
+	}
+
+	if path, err := validateForEach(rule.ForEach, v.maxDepth); err != nil {
+		return path, err
+	}
+
+	if path, err := validateManifests(rule.Manifests); err != nil {
+		return fmt.Sprintf("manifests.%s", path), err
+	}
+
+	if rule.Manifests != nil && !v.evaluatedByEngine() {
+		return "manifests", fmt.Errorf("manifests are not verified by the engine, the rule must also declare deny conditions")
+	}
+
+	if path, err := validateCEL(rule.CEL); err != nil {
+		return fmt.Sprintf("cel.%s", path), err
+	}
+
+	if rule.CEL != nil && !v.evaluatedByEngine() {
+		return "cel", fmt.Errorf("cel expressions are not evaluated by the engine, the rule must also declare deny conditions")
+	}
+
 	if rule.AnyPattern != nil {
-		anyPattern, err := rule.DeserializeAnyPattern()
+		entries, err := rule.DeserializeAnyPatternEntries()
 		if err != nil {
 			return "anyPattern", fmt.Errorf("failed to deserialize anyPattern, expect array: %v", err)
 		}
-		for i, pattern := range anyPattern {
-			if path, err := common.ValidatePattern(pattern, "/", []commonAnchors.IsAnchor{commonAnchors.IsConditionAnchor, commonAnchors.IsExistenceAnchor, commonAnchors.IsEqualityAnchor, commonAnchors.IsNegationAnchor}); err != nil {
+		for i, entry := range entries {
+			if len(entry.ForEach) > 0 {
+				if path, err := v.validateAnyPatternForEach(entry); err != nil {
+					return fmt.Sprintf("anyPattern[%d].%s", i, path), err
+				}
+				continue
+			}
+
+			if path, err := common.ValidateValidationPattern(entry.Pattern, "/", []commonAnchors.IsAnchor{commonAnchors.IsConditionAnchor, commonAnchors.IsExistenceAnchor, commonAnchors.IsEqualityAnchor, commonAnchors.IsNegationAnchor}, v.maxDepth); err != nil {
 				return fmt.Sprintf("anyPattern[%d].%s", i, path), err
 			}
 		}
@@ -50,16 +96,146 @@ func (v *Validate) Validate() (string, error) {
 	return "", nil
 }
 
+// validateAnyPatternForEach checks an anyPattern entry declaring foreach neither has a
+// pattern nor is combined with foreach at the validation level, as it would be ambiguous
+// which declaration applies
+func (v *Validate) validateAnyPatternForEach(entry kyverno.AnyPatternEntry) (string, error) {
+	if len(v.rule.ForEach) > 0 {
+		return "foreach", fmt.Errorf("foreach cannot be declared both in anyPattern entries and in validate.foreach")
+	}
+
+	if entry.Pattern != nil {
+		return "pattern", fmt.Errorf("an anyPattern entry cannot combine pattern and foreach")
+	}
+
+	if path, err := validateForEach(entry.ForEach, v.maxDepth); err != nil {
+		return path, err
+	}
+
+	return "", nil
+}
+
+// validateForEach checks each foreach declaration selects a list and has a valid pattern
+func validateForEach(forEach []kyverno.ForEachValidation, maxDepth int) (string, error) {
+	for i, fe := range forEach {
+		if fe.List == "" {
+			return fmt.Sprintf("foreach[%d].list", i), fmt.Errorf("list cannot be empty")
+		}
+
+		if fe.Pattern == nil {
+			return fmt.Sprintf("foreach[%d].pattern", i), fmt.Errorf("pattern must be specified")
+		}
+
+		if path, err := common.ValidateValidationPattern(fe.Pattern, "/", []commonAnchors.IsAnchor{commonAnchors.IsConditionAnchor, commonAnchors.IsExistenceAnchor, commonAnchors.IsEqualityAnchor, commonAnchors.IsNegationAnchor}, maxDepth); err != nil {
+			return fmt.Sprintf("foreach[%d].pattern.%s", i, path), err
+		}
+	}
+
+	return "", nil
+}
+
+// validateCEL checks the CEL expression is set and passes the syntax pre-check. The
+// expression is not compiled, so unknown variables or type errors are reported when the
+// rule is evaluated.
+func validateCEL(cel *kyverno.CEL) (string, error) {
+	if cel == nil {
+		return "", nil
+	}
+
+	if strings.TrimSpace(cel.Expression) == "" {
+		return "expression", fmt.Errorf("expression cannot be empty")
+	}
+
+	if err := common.PrecheckExpressionSyntax(cel.Expression); err != nil {
+		return "expression", fmt.Errorf("expression syntax pre-check failed: %v", err)
+	}
+
+	return "", nil
+}
+
+// validateManifests checks the manifests verification declares at least one attestor, each
+// identified by a key or a subject, and the ignored fields are valid JSON pointers
+func validateManifests(manifests *kyverno.Manifests) (string, error) {
+	if manifests == nil {
+		return "", nil
+	}
+
+	if len(manifests.Attestors) == 0 {
+		return "attestors", fmt.Errorf("at least one attestor must be specified")
+	}
+
+	for i, attestor := range manifests.Attestors {
+		if attestor.Keys == "" && attestor.Subject == "" {
+			return fmt.Sprintf("attestors[%d]", i), fmt.Errorf("keys or subject must be specified")
+		}
+
+		if attestor.Keys != "" && (attestor.Subject != "" || attestor.Issuer != "") {
+			return fmt.Sprintf("attestors[%d]", i), fmt.Errorf("keys cannot be combined with subject or issuer")
+		}
+	}
+
+	for i, field := range manifests.IgnoreFields {
+		if err := common.ValidateJSONPointer(field); err != nil {
+			return fmt.Sprintf("ignoreFields[%d]", i), err
+		}
+	}
+
+	return "", nil
+}
+
 // validateOverlayPattern checks one of pattern/anyPattern must exist
 func (v *Validate) validateOverlayPattern() error {
 	rule := v.rule
-	if rule.Pattern == nil && rule.AnyPattern == nil && rule.Deny == nil {
-		return fmt.Errorf("pattern, anyPattern or deny must be specified")
+	if rule.Pattern == nil && rule.AnyPattern == nil && rule.Deny == nil && rule.PodSecurity == nil && len(rule.ForEach) == 0 && rule.Manifests == nil && rule.CEL == nil {
+		return fmt.Errorf("pattern, anyPattern, deny, podSecurity, foreach, manifests or cel must be specified")
 	}
 
 	if rule.Pattern != nil && rule.AnyPattern != nil {
 		return fmt.Errorf("only one operation allowed per validation rule(pattern or anyPattern)")
 	}
 
+	if rule.PodSecurity != nil && (rule.Pattern != nil || rule.AnyPattern != nil) {
+		return fmt.Errorf("podSecurity cannot be combined with pattern or anyPattern")
+	}
+
+	if rule.Manifests != nil && (rule.Pattern != nil || rule.AnyPattern != nil) {
+		return fmt.Errorf("manifests cannot be combined with pattern or anyPattern")
+	}
+
+	if rule.CEL != nil && (rule.Pattern != nil || rule.AnyPattern != nil) {
+		return fmt.Errorf("cel cannot be combined with pattern or anyPattern")
+	}
+
 	return nil
 }
+
+// evaluatedByEngine checks the rule declares a pattern, anyPattern or deny conditions, which
+// are evaluated by the engine. A rule only declaring blocks the engine does not evaluate is
+// never applied.
+func (v *Validate) evaluatedByEngine() bool {
+	return v.rule.Pattern != nil || v.rule.AnyPattern != nil || v.rule.Deny != nil
+}
+
+// podSecurityLevels are the supported Pod Security Standards levels
+var podSecurityLevels = []string{"baseline", "restricted"}
+
+// podSecurityVersions are the known Pod Security Standards versions
+var podSecurityVersions = []string{"latest", "v1.19", "v1.20", "v1.21", "v1.22", "v1.23", "v1.24", "v1.25"}
+
+// validatePodSecurity checks the Pod Security Standards level and version are known
+func (v *Validate) validatePodSecurity() (string, error) {
+	podSecurity := v.rule.PodSecurity
+	if podSecurity == nil {
+		return "", nil
+	}
+
+	if !utils.ContainsString(podSecurityLevels, podSecurity.Level) {
+		return "podSecurity.level", fmt.Errorf("unsupported level %q, expected one of %v", podSecurity.Level, podSecurityLevels)
+	}
+
+	if podSecurity.Version != "" && !utils.ContainsString(podSecurityVersions, podSecurity.Version) {
+		return "podSecurity.version", fmt.Errorf("unknown version %q, expected one of %v", podSecurity.Version, podSecurityVersions)
+	}
+
+	return "", nil
+}
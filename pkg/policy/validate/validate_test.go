@@ -379,3 +379,209 @@ func Test_Validate_Validate_Unsupported(t *testing.T) {
 	}
 
 }
+
+func Test_Validate_PodSecurity(t *testing.T) {
+	testcases := []struct {
+		description  string
+		validation   []byte
+		expectedPath string
+		expectError  bool
+	}{
+		{
+			description: "baseline",
+			validation:  []byte(`{"podSecurity": {"level": "baseline"}, "deny": {}}`),
+		},
+		{
+			description: "restricted with version",
+			validation:  []byte(`{"podSecurity": {"level": "restricted", "version": "v1.22"}, "deny": {}}`),
+		},
+		{
+			description:  "podSecurity only",
+			validation:   []byte(`{"podSecurity": {"level": "baseline"}}`),
+			expectedPath: "podSecurity",
+			expectError:  true,
+		},
+		{
+			description:  "invalid level",
+			validation:   []byte(`{"podSecurity": {"level": "privileged"}}`),
+			expectedPath: "podSecurity.level",
+			expectError:  true,
+		},
+		{
+			description:  "unknown version",
+			validation:   []byte(`{"podSecurity": {"level": "baseline", "version": "1.22"}}`),
+			expectedPath: "podSecurity.version",
+			expectError:  true,
+		},
+		{
+			description: "mixed with pattern",
+			validation:  []byte(`{"podSecurity": {"level": "baseline"}, "pattern": {"spec": {"hostNetwork": false}}}`),
+			expectError: true,
+		},
+		{
+			description: "mixed with anyPattern",
+			validation:  []byte(`{"podSecurity": {"level": "baseline"}, "anyPattern": [{"spec": {"hostNetwork": false}}]}`),
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var validation kyverno.Validation
+		err := json.Unmarshal(testcase.validation, &validation)
+		assert.NilError(t, err, testcase.description)
+
+		checker := NewValidateFactory(validation)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_ForEach(t *testing.T) {
+	testcases := []struct {
+		description  string
+		validation   []byte
+		expectedPath string
+		expectError  bool
+	}{
+		{
+			description: "foreach in the validation",
+			validation:  []byte(`{"foreach": [{"list": "request.object.spec.containers", "pattern": {"image": "!*:latest"}}]}`),
+		},
+		{
+			description: "foreach in anyPattern entries",
+			validation:  []byte(`{"anyPattern": [{"foreach": [{"list": "request.object.spec.containers", "pattern": {"image": "!*:latest"}}]}, {"spec": {"hostNetwork": false}}]}`),
+		},
+		{
+			description:  "foreach in the validation and in an anyPattern entry",
+			validation:   []byte(`{"foreach": [{"list": "request.object.spec.containers", "pattern": {"image": "!*:latest"}}], "anyPattern": [{"spec": {"hostNetwork": false}}, {"foreach": [{"list": "request.object.spec.initContainers", "pattern": {"image": "!*:latest"}}]}]}`),
+			expectedPath: "anyPattern[1].foreach",
+			expectError:  true,
+		},
+		{
+			description:  "anyPattern entry with pattern and foreach",
+			validation:   []byte(`{"anyPattern": [{"pattern": {"spec": {"hostNetwork": false}}, "foreach": [{"list": "request.object.spec.containers", "pattern": {"image": "!*:latest"}}]}]}`),
+			expectedPath: "anyPattern[0].pattern",
+			expectError:  true,
+		},
+		{
+			description:  "foreach without list",
+			validation:   []byte(`{"foreach": [{"pattern": {"image": "!*:latest"}}]}`),
+			expectedPath: "foreach[0].list",
+			expectError:  true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var validation kyverno.Validation
+		err := json.Unmarshal(testcase.validation, &validation)
+		assert.NilError(t, err, testcase.description)
+
+		checker := NewValidateFactory(validation)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_Manifests(t *testing.T) {
+	testcases := []struct {
+		description  string
+		validation   []byte
+		expectedPath string
+		expectError  bool
+	}{
+		{
+			description: "complete manifests",
+			validation:  []byte(`{"manifests": {"attestors": [{"keys": "-----BEGIN PUBLIC KEY-----"}, {"subject": "ci@example.com", "issuer": "https://accounts.example.com"}], "ignoreFields": ["/metadata/annotations/deployment.kubernetes.io~1revision", "/spec/replicas"]}, "deny": {}}`),
+		},
+		{
+			description:  "manifests only",
+			validation:   []byte(`{"manifests": {"attestors": [{"keys": "-----BEGIN PUBLIC KEY-----"}]}}`),
+			expectedPath: "manifests",
+			expectError:  true,
+		},
+		{
+			description:  "missing attestors",
+			validation:   []byte(`{"manifests": {"ignoreFields": ["/spec/replicas"]}}`),
+			expectedPath: "manifests.attestors",
+			expectError:  true,
+		},
+		{
+			description:  "attestor without identity",
+			validation:   []byte(`{"manifests": {"attestors": [{"issuer": "https://accounts.example.com"}]}}`),
+			expectedPath: "manifests.attestors[0]",
+			expectError:  true,
+		},
+		{
+			description:  "invalid ignored field",
+			validation:   []byte(`{"manifests": {"attestors": [{"keys": "-----BEGIN PUBLIC KEY-----"}], "ignoreFields": ["spec.replicas"]}}`),
+			expectedPath: "manifests.ignoreFields[0]",
+			expectError:  true,
+		},
+		{
+			description: "manifests with pattern",
+			validation:  []byte(`{"pattern": {"spec": {"replicas": ">1"}}, "manifests": {"attestors": [{"keys": "-----BEGIN PUBLIC KEY-----"}]}}`),
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var validation kyverno.Validation
+		err := json.Unmarshal(testcase.validation, &validation)
+		assert.NilError(t, err, testcase.description)
+
+		checker := NewValidateFactory(validation)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_CEL(t *testing.T) {
+	testcases := []struct {
+		description  string
+		validation   []byte
+		expectedPath string
+		expectError  bool
+	}{
+		{
+			description: "compiling expression",
+			validation:  []byte(`{"cel": {"expression": "object.spec.replicas <= 5 && object.metadata.name.startsWith('web')", "message": "too many replicas"}, "deny": {}}`),
+		},
+		{
+			description:  "cel only",
+			validation:   []byte(`{"cel": {"expression": "object.spec.replicas <= 5"}}`),
+			expectedPath: "cel",
+			expectError:  true,
+		},
+		{
+			description:  "syntax error",
+			validation:   []byte(`{"cel": {"expression": "object.spec.containers.all(c, has(c.resources)"}}`),
+			expectedPath: "cel.expression",
+			expectError:  true,
+		},
+		{
+			description:  "empty expression",
+			validation:   []byte(`{"cel": {"message": "invalid"}}`),
+			expectedPath: "cel.expression",
+			expectError:  true,
+		},
+		{
+			description: "cel with pattern",
+			validation:  []byte(`{"pattern": {"spec": {"replicas": "<=5"}}, "cel": {"expression": "object.spec.replicas <= 5"}}`),
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var validation kyverno.Validation
+		err := json.Unmarshal(testcase.validation, &validation)
+		assert.NilError(t, err, testcase.description)
+
+		checker := NewValidateFactory(validation)
+		path, err := checker.Validate()
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
@@ -2,6 +2,8 @@ package policy
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	kyverno "github.com/kyverno/kyverno/pkg/api/kyverno/v1"
@@ -760,6 +762,52 @@ func Test_Validate_Roles(t *testing.T) {
 	assert.Assert(t, path == "match.roles")
 }
 
+func Test_Validate_ExcludeRoles(t *testing.T) {
+	testcases := []struct {
+		description  string
+		exclude      string
+		expectedPath string
+	}{
+		{
+			description: "roles only",
+			exclude:     `{"roles":["kube-system:admin"],"clusterRoles":["cluster-admin"]}`,
+		},
+		{
+			description:  "role without namespace",
+			exclude:      `{"roles":[":admin"]}`,
+			expectedPath: "exclude.roles",
+		},
+		{
+			description:  "role without name",
+			exclude:      `{"roles":["kube-system:"]}`,
+			expectedPath: "exclude.roles",
+		},
+		{
+			description:  "empty cluster role",
+			exclude:      `{"clusterRoles":["cluster-admin",""]}`,
+			expectedPath: "exclude.clusterRoles",
+		},
+	}
+
+	openAPIController, _ := openapi.NewOpenAPIController()
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal([]byte(`{"name":"test","match":{"resources":{"kinds":["Pod"]}},"exclude":`+testcase.exclude+`,"validate":{"pattern":{"metadata":{"name":"*"}}}}`), &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateUserInfo(rule)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+
+		if testcase.expectedPath == "" {
+			background := false
+			policy := &kyverno.ClusterPolicy{Spec: kyverno.Spec{Rules: []kyverno.Rule{rule}, Background: &background}}
+			policy.Name = "exclude-roles"
+			assert.NilError(t, Validate(policy, nil, true, openAPIController), testcase.description)
+		}
+	}
+}
+
 func Test_Validate_ServiceAccount(t *testing.T) {
 	rawRule := []byte(`
 	{
@@ -1217,3 +1265,655 @@ func Test_doesMatchExcludeConflict(t *testing.T) {
 		}
 	}
 }
+
+func Test_Validate_WebhookTimeout(t *testing.T) {
+	timeout := func(seconds int32) *int32 { return &seconds }
+	testcases := []struct {
+		timeout     *int32
+		expectError bool
+	}{
+		{timeout: nil, expectError: false},
+		{timeout: timeout(0), expectError: true},
+		{timeout: timeout(1), expectError: false},
+		{timeout: timeout(10), expectError: false},
+		{timeout: timeout(30), expectError: false},
+		{timeout: timeout(31), expectError: true},
+		{timeout: timeout(-5), expectError: true},
+	}
+
+	for i, testcase := range testcases {
+		err := validateWebhookTimeout(testcase.timeout)
+		assert.Equal(t, err != nil, testcase.expectError, "testcase %d", i)
+	}
+}
+
+func Test_Validate_MatchAnyAll(t *testing.T) {
+	testcases := []struct {
+		description  string
+		rule         []byte
+		expectedPath string
+	}{
+		{
+			description:  "any only",
+			rule:         []byte(`{"name":"any-only","match":{"any":[{"kinds":["Pod"]},{"kinds":["Deployment"],"namespaces":["prod"]}]}}`),
+			expectedPath: "",
+		},
+		{
+			description:  "all only",
+			rule:         []byte(`{"name":"all-only","match":{"all":[{"kinds":["Pod"]},{"selector":{"matchLabels":{"app":"nginx"}}}]}}`),
+			expectedPath: "",
+		},
+		{
+			description:  "resources mixed with any",
+			rule:         []byte(`{"name":"mixed","match":{"resources":{"kinds":["Pod"]},"any":[{"kinds":["Deployment"]}]}}`),
+			expectedPath: "match.resources",
+		},
+		{
+			description:  "invalid selector in any",
+			rule:         []byte(`{"name":"invalid-any","match":{"any":[{"kinds":["Pod"]},{"kinds":["Pod"]},{"kinds":["Pod"],"selector":{"matchLabels":{"app":"nginx"},"matchExpressions":[{"key":"tier","operator":"Unknown","values":["db"]}]}}]}}`),
+			expectedPath: "match.any[2]",
+		},
+		{
+			description:  "empty description in all",
+			rule:         []byte(`{"name":"empty-all","match":{"all":[{}]}}`),
+			expectedPath: "match.all[0]",
+		},
+		{
+			description:  "exclude any and all",
+			rule:         []byte(`{"name":"exclude-any-all","match":{"any":[{"kinds":["Pod"]}]},"exclude":{"any":[{"namespaces":["kube-system"]}],"all":[{"kinds":["Pod"]},{"selector":{"matchLabels":{"app":"nginx"}}}]}}`),
+			expectedPath: "",
+		},
+		{
+			description:  "exclude resources mixed with all",
+			rule:         []byte(`{"name":"exclude-mixed","match":{"any":[{"kinds":["Pod"]}]},"exclude":{"resources":{"namespaces":["kube-system"]},"all":[{"kinds":["Pod"]}]}}`),
+			expectedPath: "exclude.resources",
+		},
+		{
+			description:  "invalid selector in exclude all",
+			rule:         []byte(`{"name":"exclude-invalid-all","match":{"any":[{"kinds":["Pod"]}]},"exclude":{"all":[{"kinds":["Pod"]},{"selector":{"matchExpressions":[{"key":"tier","operator":"Unknown","values":["db"]}]}}]}}`),
+			expectedPath: "exclude.all[1]",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err)
+
+		path, err := validateResources(rule)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+	}
+}
+
+func Test_Validate_Operations(t *testing.T) {
+	testcases := []struct {
+		description  string
+		rule         []byte
+		expectedPath string
+	}{
+		{
+			description: "valid operations",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"]},"operations":["CREATE","DELETE"]},"validate":{"pattern":{"spec":{"hostNetwork":false}}}}`),
+		},
+		{
+			description:  "unknown operation",
+			rule:         []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"]},"operations":["CREATE","PATCH"]},"validate":{"pattern":{"spec":{"hostNetwork":false}}}}`),
+			expectedPath: "match.operations[1]",
+		},
+		{
+			description:  "DELETE on a mutate rule",
+			rule:         []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"]},"operations":["DELETE"]},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"app":"nginx"}}}}}`),
+			expectedPath: "match.operations[0]",
+		},
+		{
+			description: "UPDATE on a mutate rule",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"]},"operations":["UPDATE"]},"mutate":{"patchStrategicMerge":{"metadata":{"labels":{"app":"nginx"}}}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateResources(rule)
+		assert.Equal(t, path, testcase.expectedPath, testcase.description)
+		assert.Equal(t, err != nil, testcase.expectedPath != "", testcase.description)
+	}
+}
+
+func Test_Validate_AnyAllStructure(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		expectError bool
+	}{
+		{
+			description: "any in both blocks",
+			rule:        []byte(`{"name":"r","match":{"any":[{"kinds":["Pod"]}]},"exclude":{"any":[{"namespaces":["kube-system"]}]}}`),
+		},
+		{
+			description: "resources in both blocks",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"]}},"exclude":{"resources":{"namespaces":["kube-system"]}}}`),
+		},
+		{
+			description: "all in match and resources in exclude",
+			rule:        []byte(`{"name":"r","match":{"all":[{"kinds":["Pod"]}]},"exclude":{"resources":{"namespaces":["kube-system"]}}}`),
+			expectError: true,
+		},
+		{
+			description: "resources in match and any in exclude",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Pod"]}},"exclude":{"any":[{"namespaces":["kube-system"]}]}}`),
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		err = validateAnyAllStructure(rule)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_Validate_KindNames(t *testing.T) {
+	testcases := []struct {
+		kinds       []string
+		expectError bool
+	}{
+		{kinds: []string{"pods"}, expectError: true},
+		{kinds: []string{"Pod"}, expectError: false},
+		{kinds: []string{"Deployment", "networkpolicies"}, expectError: true},
+		{kinds: []string{"Endpoints"}, expectError: false},
+		{kinds: []string{"PodMetrics"}, expectError: false},
+		{kinds: []string{"*"}, expectError: false},
+	}
+
+	for _, testcase := range testcases {
+		err := validateKindNames(testcase.kinds)
+		assert.Equal(t, err != nil, testcase.expectError, "kinds %v", testcase.kinds)
+	}
+
+	assert.Equal(t, singularKind("pods"), "Pod")
+	assert.Equal(t, singularKind("networkpolicies"), "NetworkPolicy")
+	assert.Equal(t, singularKind("widgets"), "Widget")
+}
+
+func Test_ValidateWithOptions_MaxPatchOps(t *testing.T) {
+	rawPolicy := []byte(`
+	{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {
+		  "name": "add-labels"
+		},
+		"spec": {
+		  "rules": [
+			{
+			  "name": "add-labels",
+			  "match": {
+				"resources": {
+				  "kinds": ["ConfigMap"]
+				}
+			  },
+			  "mutate": {
+				"patches": [
+				  {"path": "/metadata/labels/a", "op": "add", "value": "a"},
+				  {"path": "/metadata/labels/b", "op": "add", "value": "b"},
+				  {"path": "/metadata/labels/c", "op": "add", "value": "c"}
+				]
+			  }
+			}
+		  ]
+		}
+	}`)
+
+	openAPIController, _ := openapi.NewOpenAPIController()
+	var policy *kyverno.ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	err = Validate(policy, nil, true, openAPIController)
+	assert.NilError(t, err)
+
+	err = ValidateWithOptions(policy, nil, true, openAPIController, ValidateOptions{MaxPatchOps: 3})
+	assert.NilError(t, err)
+
+	err = ValidateWithOptions(policy, nil, true, openAPIController, ValidateOptions{MaxPatchOps: 2})
+	assert.Error(t, err, "path: spec.rules[0].mutate.patches.: mutation has 3 JSON patch operations, the limit is 2")
+}
+
+func Test_validateUniqueRulePriority(t *testing.T) {
+	testcases := []struct {
+		description string
+		spec        []byte
+		path        string
+	}{
+		{
+			description: "no priorities",
+			spec:        []byte(`{"rules":[{"name":"a"},{"name":"b"}]}`),
+		},
+		{
+			description: "unique priorities",
+			spec:        []byte(`{"rules":[{"name":"a","priority":1},{"name":"b"},{"name":"c","priority":2}]}`),
+		},
+		{
+			description: "duplicate priorities",
+			spec:        []byte(`{"rules":[{"name":"a","priority":1},{"name":"b"},{"name":"c","priority":1}]}`),
+			path:        "rules[2].priority",
+		},
+		{
+			description: "negative priority",
+			spec:        []byte(`{"rules":[{"name":"a","priority":-1}]}`),
+			path:        "rules[0].priority",
+		},
+	}
+
+	for _, testcase := range testcases {
+		var policy kyverno.ClusterPolicy
+		err := json.Unmarshal(testcase.spec, &policy.Spec)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateUniqueRulePriority(policy)
+		assert.Equal(t, path, testcase.path, testcase.description)
+		assert.Equal(t, err != nil, testcase.path != "", testcase.description)
+	}
+}
+
+func Test_ValidateWithOptions_MaxDepth(t *testing.T) {
+	rawPolicy := []byte(`{"metadata":{"name":"depth"},"spec":{"rules":[{"name":"nested","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"containers":[{"securityContext":{"runAsNonRoot":true}}]}}}}]}}`)
+
+	openAPIController, _ := openapi.NewOpenAPIController()
+	var policy *kyverno.ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	err = ValidateWithOptions(policy, nil, true, openAPIController, ValidateOptions{MaxDepth: 5})
+	assert.NilError(t, err)
+
+	err = ValidateWithOptions(policy, nil, true, openAPIController, ValidateOptions{MaxDepth: 4})
+	assert.Error(t, err, "path: spec.rules[0].validate.pattern.//spec/containers0//securityContext.: pattern exceeds the maximum depth of 4")
+}
+
+func Test_Validate_MatchGeneration(t *testing.T) {
+	testcases := []struct {
+		description string
+		match       []byte
+		expectError bool
+	}{
+		{
+			description: "generation comparison",
+			match:       []byte(`{"resources":{"kinds":["Deployment"],"matchGeneration":"metadata.generation > 1"}}`),
+		},
+		{
+			description: "observed generation comparison",
+			match:       []byte(`{"resources":{"kinds":["Deployment"],"matchGeneration":"status.observedGeneration>=2"}}`),
+		},
+		{
+			description: "unsupported field",
+			match:       []byte(`{"resources":{"kinds":["Deployment"],"matchGeneration":"metadata.name > 1"}}`),
+			expectError: true,
+		},
+		{
+			description: "non integer value",
+			match:       []byte(`{"resources":{"kinds":["Deployment"],"matchGeneration":"metadata.generation > one"}}`),
+			expectError: true,
+		},
+		{
+			description: "missing operator",
+			match:       []byte(`{"resources":{"kinds":["Deployment"],"matchGeneration":"metadata.generation"}}`),
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.match, &rule.MatchResources)
+		assert.NilError(t, err, testcase.description)
+
+		_, err = validateResources(rule)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_Validate_ExcludeSubjectsScope(t *testing.T) {
+	testcases := []struct {
+		description string
+		exclude     []byte
+		expectError bool
+	}{
+		{
+			description: "subjects only",
+			exclude:     []byte(`{"subjects":[{"kind":"Group","name":"system:masters"}]}`),
+			expectError: true,
+		},
+		{
+			description: "subjects and resources",
+			exclude:     []byte(`{"subjects":[{"kind":"Group","name":"system:masters"}],"resources":{"namespaces":["kube-system"]}}`),
+			expectError: false,
+		},
+		{
+			description: "resources only",
+			exclude:     []byte(`{"resources":{"namespaces":["kube-system"]}}`),
+			expectError: false,
+		},
+		{
+			description: "cluster roles only",
+			exclude:     []byte(`{"clusterRoles":["cluster-admin"]}`),
+			expectError: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var exclude kyverno.ExcludeResources
+		err := json.Unmarshal(testcase.exclude, &exclude)
+		assert.NilError(t, err, testcase.description)
+
+		err = validateExcludeSubjectsScope(exclude)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_Validate_GenerateSelfTarget(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		path        string
+		expectError bool
+	}{
+		{
+			description: "self-targeting",
+			rule:        []byte(`{"name":"copy-pod","match":{"resources":{"kinds":["Pod"]}},"generate":{"kind":"Pod","name":"{{ request.object.metadata.name }}","namespace":"default","data":{"spec":{"containers":[{"name":"nginx","image":"nginx"}]}}}}`),
+			path:        "generate",
+			expectError: true,
+		},
+		{
+			description: "self-targeting in generations",
+			rule:        []byte(`{"name":"copy-pod","match":{"resources":{"kinds":["Pod"]}},"generations":[{"kind":"ConfigMap","name":"{{request.object.metadata.name}}","namespace":"default","data":{}},{"kind":"Pod","name":"{{request.object.metadata.name}}","namespace":"default","data":{"spec":{"containers":[{"name":"nginx","image":"nginx"}]}}}]}`),
+			path:        "generations[1]",
+			expectError: true,
+		},
+		{
+			description: "self-targeting with tabs and newlines",
+			rule:        []byte(`{"name":"copy-pod","match":{"resources":{"kinds":["Pod"]}},"generate":{"kind":"Pod","name":"{{\trequest.object.metadata.name\n}}","namespace":"default","data":{}}}`),
+			path:        "generate",
+			expectError: true,
+		},
+		{
+			description: "name combined with the namespace",
+			rule:        []byte(`{"name":"copy-cm","match":{"resources":{"kinds":["ConfigMap"]}},"generate":{"kind":"ConfigMap","name":"{{request.object.metadata.namespace}}-{{request.object.metadata.name}}","namespace":"default","data":{}}}`),
+			path:        "generate",
+			expectError: true,
+		},
+		{
+			description: "name combined with the kind",
+			rule:        []byte(`{"name":"copy-cm","match":{"resources":{"kinds":["ConfigMap"]}},"generate":{"kind":"ConfigMap","name":"{{ to_lower(request.object.kind) }}-{{ request.object.metadata.name }}","namespace":"default","data":{}}}`),
+			path:        "generate",
+			expectError: true,
+		},
+		{
+			description: "name of the namespace",
+			rule:        []byte(`{"name":"copy-cm","match":{"resources":{"kinds":["ConfigMap"]}},"generate":{"kind":"ConfigMap","name":"{{request.object.metadata.namespace}}-defaults","namespace":"default","data":{}}}`),
+			expectError: false,
+		},
+		{
+			description: "different kind",
+			rule:        []byte(`{"name":"add-netpol","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"NetworkPolicy","name":"{{request.object.metadata.name}}","namespace":"{{request.object.metadata.name}}","data":{"spec":{"podSelector":{}}}}}`),
+			expectError: false,
+		},
+		{
+			description: "same kind with a fixed name",
+			rule:        []byte(`{"name":"default-cm","match":{"resources":{"kinds":["ConfigMap"]}},"generate":{"kind":"ConfigMap","name":"defaults","namespace":"default","data":{"data":{"key":"value"}}}}`),
+			expectError: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateGenerateSelfTarget(rule)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+		assert.Equal(t, path, testcase.path, testcase.description)
+	}
+}
+
+func Test_Validate_SystemNamespaceWildcards(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		allow       bool
+		expectError bool
+	}{
+		{
+			description: "wildcard without flag",
+			rule:        []byte(`{"name":"check","match":{"resources":{"kinds":["Pod"],"namespaces":["*"]}},"validate":{"pattern":{"spec":{"hostNetwork":false}}}}`),
+			expectError: true,
+		},
+		{
+			description: "wildcard with flag",
+			rule:        []byte(`{"name":"check","match":{"resources":{"kinds":["Pod"],"namespaces":["*"]}},"validate":{"pattern":{"spec":{"hostNetwork":false}}}}`),
+			allow:       true,
+			expectError: false,
+		},
+		{
+			description: "kube prefix",
+			rule:        []byte(`{"name":"check","match":{"any":[{"kinds":["Pod"],"namespaces":["kube-*"]}]},"validate":{"pattern":{"spec":{"hostNetwork":false}}}}`),
+			expectError: true,
+		},
+		{
+			description: "system namespaces excluded",
+			rule:        []byte(`{"name":"check","match":{"resources":{"kinds":["Pod"],"namespaces":["*"]}},"exclude":{"resources":{"namespaces":["kube-*"]}},"validate":{"pattern":{"spec":{"hostNetwork":false}}}}`),
+			expectError: false,
+		},
+		{
+			description: "narrow wildcard",
+			rule:        []byte(`{"name":"check","match":{"resources":{"kinds":["Pod"],"namespaces":["team-*"]}},"validate":{"pattern":{"spec":{"hostNetwork":false}}}}`),
+			expectError: false,
+		},
+		{
+			description: "explicit system namespace",
+			rule:        []byte(`{"name":"check","match":{"resources":{"kinds":["Pod"],"namespaces":["kube-system"]}},"validate":{"pattern":{"spec":{"hostNetwork":false}}}}`),
+			expectError: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		err = validateSystemNamespaceWildcards(rule, testcase.allow)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_validateGenerateSystemNamespace(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		allow       bool
+		expectError bool
+	}{
+		{
+			description: "generate in kube-system",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"cm","namespace":"kube-system","data":{}}}`),
+			expectError: true,
+		},
+		{
+			description: "generate in kube-system allowed",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"cm","namespace":"kube-system","data":{}}}`),
+			allow:       true,
+		},
+		{
+			description: "generate in a namespace",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"cm","namespace":"default","data":{}}}`),
+		},
+		{
+			description: "generate in the trigger namespace",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.name}}","data":{}}}`),
+		},
+		{
+			description: "generations in kube-public",
+			rule:        []byte(`{"name":"gen","match":{"resources":{"kinds":["Namespace"]}},"generations":[{"kind":"ConfigMap","name":"cm","namespace":"default","data":{}},{"kind":"ConfigMap","name":"cm","namespace":"kube-public","data":{}}]}`),
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		_, err = validateGenerateSystemNamespace(rule, testcase.allow)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_Validate_SkipBackgroundRequests(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		expectError bool
+	}{
+		{
+			description: "validate rule skipping background requests",
+			rule:        []byte(`{"name":"r","skipBackgroundRequests":true,"match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"spec":{"hostNetwork":false}}}}`),
+		},
+		{
+			description: "generate rule skipping background requests",
+			rule:        []byte(`{"name":"r","skipBackgroundRequests":true,"match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.name}}","data":{}}}`),
+			expectError: true,
+		},
+		{
+			description: "generate rule",
+			rule:        []byte(`{"name":"r","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"cm","namespace":"{{request.object.metadata.name}}","data":{}}}`),
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err, testcase.description)
+
+		err = validateSkipBackgroundRequests(rule)
+		assert.Equal(t, err != nil, testcase.expectError, testcase.description)
+	}
+}
+
+func Test_ValidateWithOptions_MaxRules(t *testing.T) {
+	rawPolicy := []byte(`{"metadata":{"name":"rules"},"spec":{"rules":[{"name":"first","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"name":"*"}}}},{"name":"second","match":{"resources":{"kinds":["Pod"]}},"validate":{"pattern":{"metadata":{"namespace":"*"}}}}]}}`)
+
+	openAPIController, _ := openapi.NewOpenAPIController()
+	var policy *kyverno.ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	err = ValidateWithOptions(policy, nil, true, openAPIController, ValidateOptions{})
+	assert.NilError(t, err)
+
+	err = ValidateWithOptions(policy, nil, true, openAPIController, ValidateOptions{MaxRules: 2})
+	assert.NilError(t, err)
+
+	err = ValidateWithOptions(policy, nil, true, openAPIController, ValidateOptions{MaxRules: 1})
+	assert.Error(t, err, "path: spec.rules: policy has 2 rules, which exceeds the maximum of 1")
+}
+
+func Test_validateMessageLength(t *testing.T) {
+	message := "image {{request.object.spec.containers[0].image}} must come from the registry"
+
+	assert.NilError(t, validateMessageLength(message, 0))
+	assert.NilError(t, validateMessageLength(message, 40))
+	assert.Error(t, validateMessageLength(message, 30), "message has 34 characters, which exceeds the maximum of 30")
+	assert.Error(t, validateMessageLength(strings.Repeat("x", 300), 256), "message has 300 characters, which exceeds the maximum of 256")
+}
+
+func Test_Validate_Generations(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        string
+		err         string
+	}{
+		{
+			description: "multiple targets",
+			rule:        `{"name":"isolate","match":{"resources":{"kinds":["Namespace"]}},"generations":[{"kind":"NetworkPolicy","name":"deny-all","namespace":"{{request.object.metadata.name}}","data":{"spec":{"podSelector":{},"policyTypes":["Ingress"]}}},{"kind":"ConfigMap","name":"settings","namespace":"{{request.object.metadata.name}}","data":{"data":{"isolated":"true"}}}]}`,
+		},
+		{
+			description: "duplicate target",
+			rule:        `{"name":"isolate","match":{"resources":{"kinds":["Namespace"]}},"generations":[{"kind":"ConfigMap","name":"settings","namespace":"{{request.object.metadata.name}}","data":{"data":{"isolated":"true"}}},{"kind":"ConfigMap","name":"settings","namespace":"{{request.object.metadata.name}}","data":{"data":{"isolated":"false"}}}]}`,
+			err:         "path: spec.rules[0].generations[1]: duplicate target ConfigMap {{request.object.metadata.name}}/settings, already generated by generations[0]",
+		},
+		{
+			description: "invalid entry",
+			rule:        `{"name":"isolate","match":{"resources":{"kinds":["Namespace"]}},"generations":[{"kind":"ConfigMap","name":"settings","namespace":"{{request.object.metadata.name}}","data":{"data":{"isolated":"true"}}},{"kind":"NetworkPolicy","namespace":"{{request.object.metadata.name}}","data":{"spec":{"podSelector":{}}}}]}`,
+			err:         "path: spec.rules[0].generations[1].name.: name cannot be empty",
+		},
+		{
+			description: "combined with generate",
+			rule:        `{"name":"isolate","match":{"resources":{"kinds":["Namespace"]}},"generate":{"kind":"ConfigMap","name":"settings","namespace":"{{request.object.metadata.name}}","data":{"data":{"isolated":"true"}}},"generations":[{"kind":"NetworkPolicy","name":"deny-all","namespace":"{{request.object.metadata.name}}","data":{"spec":{"podSelector":{}}}}]}`,
+			err:         "path: spec.rules[0].generations: generate and generations are mutually exclusive",
+		},
+	}
+
+	openAPIController, _ := openapi.NewOpenAPIController()
+	for _, testcase := range testcases {
+		var policy *kyverno.ClusterPolicy
+		err := json.Unmarshal([]byte(`{"metadata":{"name":"isolate"},"spec":{"rules":[`+testcase.rule+`]}}`), &policy)
+		assert.NilError(t, err, testcase.description)
+
+		err = Validate(policy, nil, true, openAPIController)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, err, testcase.err, testcase.description)
+		}
+	}
+}
+
+func Test_Validate_NamespaceSelector(t *testing.T) {
+	testcases := []struct {
+		description string
+		match       string
+		err         string
+	}{
+		{
+			description: "valid namespaceSelector",
+			match:       `{"resources":{"kinds":["Pod"],"namespaceSelector":{"matchLabels":{"env":"prod"}}}}`,
+		},
+		{
+			description: "namespaceSelector only",
+			match:       `{"resources":{"namespaceSelector":{"matchExpressions":[{"key":"env","operator":"In","values":["prod","staging"]}]}}}`,
+		},
+		{
+			description: "invalid operator",
+			match:       `{"resources":{"kinds":["Pod"],"namespaceSelector":{"matchExpressions":[{"key":"env","operator":"Equals","values":["prod"]}]}}}`,
+			err:         `path: spec.rules[0].match.resources.match: invalid namespaceSelector: "Equals" is not a valid pod selector operator`,
+		},
+		{
+			description: "empty namespaceSelector",
+			match:       `{"resources":{"kinds":["Pod"],"namespaceSelector":{}}}`,
+			err:         "path: spec.rules[0].match.resources.match: the requirements are not specified in namespaceSelector",
+		},
+	}
+
+	for _, testcase := range testcases {
+		rule := kyverno.Rule{}
+		err := json.Unmarshal([]byte(`{"name":"prod","match":`+testcase.match+`,"validate":{"pattern":{"metadata":{"labels":{"app":"?*"}}}}}`), &rule)
+		assert.NilError(t, err, testcase.description)
+
+		path, err := validateResources(rule)
+		if testcase.err == "" {
+			assert.NilError(t, err, testcase.description)
+		} else {
+			assert.Error(t, fmt.Errorf("path: spec.rules[0].%s: %v", path, err), testcase.err, testcase.description)
+		}
+
+		copied := rule.DeepCopy()
+		assert.DeepEqual(t, copied.MatchResources.NamespaceSelector, rule.MatchResources.NamespaceSelector)
+		assert.Assert(t, copied.MatchResources.NamespaceSelector != rule.MatchResources.NamespaceSelector, testcase.description)
+	}
+}
@@ -0,0 +1,35 @@
+package policy
+
+// Severity is the severity of a policy validation diagnostic
+type Severity string
+
+const (
+	// SeverityError is used for diagnostics that make the policy invalid
+	SeverityError Severity = "error"
+	// SeverityWarning is used for diagnostics that do not block the policy
+	SeverityWarning Severity = "warning"
+)
+
+// PolicyValidationResult is a diagnostic reported when validating a policy
+type PolicyValidationResult struct {
+	// Policy is the name of the policy
+	Policy string
+
+	// Rule is the name of the rule, empty for policy level diagnostics
+	Rule string
+
+	// Severity of the diagnostic
+	Severity Severity
+
+	// Message describes the diagnostic
+	Message string
+
+	// Path is the path of the offending field in the policy, e.g. spec.rules[0].validate.pattern
+	Path string
+
+	// File is the file the policy was loaded from, if any
+	File string
+
+	// Line is the line of the offending field in File, if known
+	Line int
+}
@@ -78,19 +78,19 @@ func generateCronJobRule(rule kyverno.Rule, controllers string, log logr.Logger)
 
 	if (jobRule.Validation != nil) && (jobRule.Validation.AnyPattern != nil) {
 		var patterns []interface{}
-		anyPatterns, err := jobRule.Validation.DeserializeAnyPattern()
+		anyPatterns, err := jobRule.Validation.DeserializeAnyPatternEntries()
 		if err != nil {
 			logger.Error(err, "failed to deserialize anyPattern, expect type array")
 		}
 
-		for _, pattern := range anyPatterns {
+		for _, entry := range anyPatterns {
 			newPattern := map[string]interface{}{
 				"spec": map[string]interface{}{
-					"jobTemplate": pattern,
+					"jobTemplate": entry.Pattern,
 				},
 			}
 
-			patterns = append(patterns, newPattern)
+			patterns = append(patterns, anyPatternEntry(newPattern, entry.Message))
 		}
 
 		cronJobRule.Validation = &kyverno.Validation{
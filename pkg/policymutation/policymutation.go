@@ -484,19 +484,19 @@ func generateRuleForControllers(rule kyverno.Rule, controllers string, log logr.
 
 	if rule.Validation.AnyPattern != nil {
 		var patterns []interface{}
-		anyPatterns, err := rule.Validation.DeserializeAnyPattern()
+		anyPatterns, err := rule.Validation.DeserializeAnyPatternEntries()
 		if err != nil {
 			logger.Error(err, "failed to deserialize anyPattern, expect type array")
 		}
 
-		for _, pattern := range anyPatterns {
+		for _, entry := range anyPatterns {
 			newPattern := map[string]interface{}{
 				"spec": map[string]interface{}{
-					"template": pattern,
+					"template": entry.Pattern,
 				},
 			}
 
-			patterns = append(patterns, newPattern)
+			patterns = append(patterns, anyPatternEntry(newPattern, entry.Message))
 		}
 
 		controllerRule.Validation = &kyverno.Validation{
@@ -548,3 +548,15 @@ func defaultPodControllerAnnotation(ann map[string]string) ([]byte, error) {
 	}
 	return patchByte, nil
 }
+
+// anyPatternEntry returns the pattern as an anyPattern entry, keeping its message if any
+func anyPatternEntry(pattern interface{}, message string) interface{} {
+	if message == "" {
+		return pattern
+	}
+
+	return map[string]interface{}{
+		"pattern": pattern,
+		"message": message,
+	}
+}
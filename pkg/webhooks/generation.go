@@ -47,6 +47,7 @@ func (ws *WebhookServer) HandleGenerate(request *v1beta1.AdmissionRequest, polic
 			NewResource:         new,
 			OldResource:         old,
 			AdmissionInfo:       userRequestInfo,
+			Operation:           string(request.Operation),
 			ExcludeGroupRole:    dynamicConfig.GetExcludeGroupRole(),
 			ExcludeResourceFunc: ws.configHandler.ToFilter,
 			ResourceCache:       ws.resCache,
@@ -146,28 +147,30 @@ func (ws *WebhookServer) handleUpdateTargetResource(request *v1beta1.AdmissionRe
 	for _, policy := range policies {
 		if policy.GetName() == policyName {
 			for _, rule := range policy.Spec.Rules {
-				if rule.Generation.Kind == targetSourceKind && rule.Generation.Name == targetSourceName {
-					data := rule.Generation.DeepCopy().Data
-					if data != nil {
-						if _, err := validate.ValidateResourceWithPattern(logger, newRes.Object, data); err != nil {
-							enqueueBool = true
-							break
+				for _, generation := range rule.GenerateTargets() {
+					if generation.Kind == targetSourceKind && generation.Name == targetSourceName {
+						data := generation.DeepCopy().Data
+						if data != nil {
+							if _, err := validate.ValidateResourceWithPattern(logger, newRes.Object, data); err != nil {
+								enqueueBool = true
+								break
+							}
 						}
-					}
 
-					cloneName := rule.Generation.Clone.Name
-					if cloneName != "" {
-						obj, err := ws.client.GetResource("", rule.Generation.Kind, rule.Generation.Clone.Namespace, rule.Generation.Clone.Name)
-						if err != nil {
-							logger.Error(err, fmt.Sprintf("source resource %s/%s/%s not found.", rule.Generation.Kind, rule.Generation.Clone.Namespace, rule.Generation.Clone.Name))
-							continue
-						}
+						cloneName := generation.Clone.Name
+						if cloneName != "" {
+							obj, err := ws.client.GetResource("", generation.Kind, generation.Clone.Namespace, generation.Clone.Name)
+							if err != nil {
+								logger.Error(err, fmt.Sprintf("source resource %s/%s/%s not found.", generation.Kind, generation.Clone.Namespace, generation.Clone.Name))
+								continue
+							}
 
-						sourceObj, newResObj := stripNonPolicyFields(obj.Object, newRes.Object, logger)
+							sourceObj, newResObj := stripNonPolicyFields(obj.Object, newRes.Object, logger)
 
-						if _, err := validate.ValidateResourceWithPattern(logger, newResObj, sourceObj); err != nil {
-							enqueueBool = true
-							break
+							if _, err := validate.ValidateResourceWithPattern(logger, newResObj, sourceObj); err != nil {
+								enqueueBool = true
+								break
+							}
 						}
 					}
 				}
@@ -76,6 +76,7 @@ func HandleValidation(
 		NewResource:         newR,
 		OldResource:         oldR,
 		AdmissionInfo:       userRequestInfo,
+		Operation:           string(request.Operation),
 		ExcludeGroupRole:    dynamicConfig.GetExcludeGroupRole(),
 		ExcludeResourceFunc: dynamicConfig.ToFilter,
 		ResourceCache:       resCache,